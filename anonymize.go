@@ -0,0 +1,82 @@
+package shuntingyard
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Anonymize replaces every numeric literal and variable name in an infix
+// expression with a numbered placeholder while preserving its structure, so
+// expressions containing sensitive figures can be logged and aggregated safely
+// (e.g., "(1200.50 + 300) * customer.salary" becomes "(n1 + n2) * v1").
+//
+// Function names and constants such as pi are kept, as they are part of the
+// structure. Numbers are numbered by position rather than by value, so two
+// expressions with the same shape always anonymize to the same string and
+// repeated values are not revealed. Variables are numbered by first
+// appearance, so a name used twice gets the same placeholder both times. The
+// output is normalized to single spaces between tokens.
+//
+// opts are passed to Scan, so custom literals (see WithLiteralParser) are
+// anonymized like any other number.
+//
+// Returns the anonymized expression or the Scan error for invalid input. Errors
+// are always redacted (see WithRedactedErrors) so they are as safe to log as the result.
+func Anonymize(expression string, opts ...Option) (string, error) {
+	tokens, err := Scan(expression, append(opts[:len(opts):len(opts)], WithRedactedErrors())...)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := newConfig(opts)
+	numbers := 0
+	variables := make(map[string]string)
+	anonymized := make([]string, len(tokens))
+	for i, token := range tokens {
+		call := i+1 < len(tokens) && tokens[i+1] == "("
+		_, constant := cfg.constant(token)
+		switch {
+		case cfg.isGrammarToken(token) || (call && isIdentifier(token)) || constant:
+			anonymized[i] = token
+		case isVariableName(token) && !isFloatKeyword(token):
+			placeholder, ok := variables[token]
+			if !ok {
+				placeholder = "v" + strconv.Itoa(len(variables)+1)
+				variables[token] = placeholder
+			}
+			anonymized[i] = placeholder
+		default:
+			numbers++
+			anonymized[i] = "n" + strconv.Itoa(numbers)
+		}
+	}
+
+	return joinTokens(anonymized), nil
+}
+
+// joinTokens renders infix tokens as a readable expression with single spaces
+// between tokens and none inside parentheses, before a comma, after a prefix
+// operator, or between a function name and its arguments.
+func joinTokens(tokens []string) string {
+	var b strings.Builder
+	for i, token := range tokens {
+		call := token == "(" && isIdentifier(tokens[max(i-1, 0)])
+		if i > 0 && token != ")" && token != "," && tokens[i-1] != "(" && !isPrefix(tokens, i-1) && !call {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// isPrefix reports whether tokens[i] is a prefix operator: '!', '~', or a '+'
+// or '-' that starts the expression or follows any grammar token but ')'.
+func isPrefix(tokens []string, i int) bool {
+	if tokens[i] == "!" || tokens[i] == "~" {
+		return true
+	}
+	if tokens[i] != "+" && tokens[i] != "-" {
+		return false
+	}
+	return i == 0 || (isGrammarToken(tokens[i-1]) && tokens[i-1] != ")")
+}
@@ -0,0 +1,74 @@
+package shuntingyard
+
+import "testing"
+
+// TestAnonymize tests replacing literals with placeholders
+func TestAnonymize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "single literal", input: "42", expected: "n1"},
+		{name: "simple addition", input: "2 + 3", expected: "n1 + n2"},
+		{name: "no spaces", input: "1200.50*12", expected: "n1 * n2"},
+		{name: "repeated values", input: "2 + 2", expected: "n1 + n2"},
+		{name: "parentheses", input: "(1200.50 + 300) * 12", expected: "(n1 + n2) * n3"},
+		{name: "nested parentheses", input: "((2+3)*4)-5", expected: "((n1 + n2) * n3) - n4"},
+		{name: "signs", input: "-2 * (-3 + +4)", expected: "-n1 * (-n2 + +n3)"},
+		{name: "sign after operator", input: "2 - -3", expected: "n1 - -n2"},
+		{name: "logical operators", input: "!(1 > 2) && !-3", expected: "!(n1 > n2) && !-n3"},
+		{name: "conditional", input: "1 > 0 ? -1 : 2", expected: "n1 > n2 ? -n3 : n4"},
+		{name: "function calls", input: "sqrt (2)*abs(-3)", expected: "sqrt(n1) * abs(-n2)"},
+		{name: "several arguments", input: "max(1,2 , 3)", expected: "max(n1, n2, n3)"},
+		{name: "bitwise operators", input: "~8 & 3 xor 1", expected: "~n1 & n2 xor n3"},
+		{name: "variables", input: "2 + 3 * rate", expected: "n1 + n2 * v1"},
+		{name: "dotted paths", input: "customer.salary * 12 - customer.tax", expected: "v1 * n1 - v2"},
+		{name: "repeated variable", input: "x * x + y / x", expected: "v1 * v1 + v2 / v1"},
+		{name: "constants kept", input: "2 * pi * r", expected: "n1 * pi * v1"},
+		{name: "function of a variable", input: "sqrt(area) + max(a, 1)", expected: "sqrt(v1) + max(v2, n1)"},
+		{name: "float keyword", input: "x < inf", expected: "v1 < n1"},
+
+		// Error cases
+		{name: "invalid character", input: "2 + $", wantErr: true},
+		{name: "empty expression", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Anonymize(tt.input)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Anonymize() expected error, got %q", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Anonymize() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("Anonymize(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestAnonymizeSameShape tests that expressions with equal structure anonymize identically
+func TestAnonymizeSameShape(t *testing.T) {
+	a, err := Anonymize("100 / 2 - 3 * 4")
+	if err != nil {
+		t.Fatalf("Anonymize() unexpected error: %v", err)
+	}
+	b, err := Anonymize("7.5/1-9*0.25")
+	if err != nil {
+		t.Fatalf("Anonymize() unexpected error: %v", err)
+	}
+	if a != b {
+		t.Errorf("Anonymize() = %q and %q, expected identical output", a, b)
+	}
+}
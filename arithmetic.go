@@ -0,0 +1,372 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidNumber is the error an Arithmetic's ParseLiteral returns for a
+// token that isn't a number at all. The evaluation then fails with "invalid
+// number", redacted like any other by WithRedactedErrors.
+var ErrInvalidNumber = errors.New("invalid number")
+
+// Arithmetic implements a number type for EvaluateWith, so that float64,
+// exact rationals, fixed-point decimals, and custom types such as big.Float or
+// a unit-carrying quantity are all evaluated by the same engine. Operations
+// return an error for results the type can't represent, such as a division by
+// zero or an overflow.
+//
+// Beyond the four basic operators, the engine uses the optional interfaces
+// OperatorArithmetic, FunctionArithmetic, VariableArithmetic, and
+// RoundingArithmetic when the Arithmetic implements them.
+type Arithmetic[T any] interface {
+	// ParseLiteral reads a number token, such as "19.99".
+	ParseLiteral(token string) (T, error)
+	Add(a, b T) (T, error)
+	Sub(a, b T) (T, error)
+	Mul(a, b T) (T, error)
+	Div(a, b T) (T, error)
+}
+
+// OperatorArithmetic is an Arithmetic that supports the operators beyond +, -,
+// * and /. Without it, unary minus is computed as 0 - a and the other
+// operators fail the evaluation.
+type OperatorArithmetic[T any] interface {
+	Arithmetic[T]
+	// Binary applies "%", "^", a comparison, "&&", "||", a bitwise operator
+	// or a shift.
+	Binary(operator string, a, b T) (T, error)
+	// Unary applies "neg", "!" or "~".
+	Unary(operator string, a T) (T, error)
+	// Truth reports whether a counts as true, choosing the branch of a
+	// conditional "c ? a : b".
+	Truth(a T) bool
+}
+
+// FunctionArithmetic is an Arithmetic that computes function calls such as
+// "max(a, b)". The engine checks the argument count against the function's
+// definition before calling Call. Without it, function calls fail the
+// evaluation.
+type FunctionArithmetic[T any] interface {
+	Arithmetic[T]
+	Call(name string, args []T) (T, error)
+}
+
+// VariableArithmetic is an Arithmetic that converts the float64 values of
+// variables and constants itself. Without it, a value is handed to
+// ParseLiteral in its shortest decimal form.
+type VariableArithmetic[T any] interface {
+	Arithmetic[T]
+	Variable(name string, value float64) (T, error)
+}
+
+// RoundingArithmetic is an Arithmetic that rounds its values to a number of
+// decimal places, which WithRounding requires.
+type RoundingArithmetic[T any] interface {
+	Arithmetic[T]
+	Round(a T, scale int, mode RoundingMode) (T, error)
+}
+
+// invoker is implemented by the package's own arithmetics, which call the
+// function the engine resolved from the configuration rather than look it up
+// again.
+type invoker[T any] interface {
+	invoke(fn function, token string, args []T) (T, error)
+}
+
+// EvaluateWith computes the result of a postfix (RPN) expression in the number
+// type implemented by arith, with the operators, functions, and variables of
+// Evaluate:
+//
+//	result, err := EvaluateWith(postfix, myBigFloatArithmetic, opts...)
+//
+// Tokens that are names are variables, resolved as for Evaluate and converted
+// by arith; every other operand token is handed to arith.ParseLiteral. Returns
+// the result or an error for invalid expressions and for operations arith
+// rejects. Failures caused by a specific token are reported as a *TokenError
+// holding its index.
+func EvaluateWith[T any](postfixTokens []string, arith Arithmetic[T], opts ...Option) (T, error) {
+	return evaluateWith(postfixTokens, arith, newConfig(opts))
+}
+
+// evaluateWith implements EvaluateWith and the evaluators built on it.
+func evaluateWith[T any](postfixTokens []string, arith Arithmetic[T], cfg config) (T, error) {
+	var zero T
+
+	if len(postfixTokens) == 0 {
+		return zero, fmt.Errorf("empty expression")
+	}
+
+	operators, _ := arith.(OperatorArithmetic[T])
+	functions, _ := arith.(FunctionArithmetic[T])
+	variables, _ := arith.(VariableArithmetic[T])
+	builtin, _ := arith.(invoker[T])
+	rounder, _ := arith.(RoundingArithmetic[T])
+	finite, _ := arith.(finiteArithmetic[T])
+	if cfg.rounding && rounder == nil {
+		return zero, fmt.Errorf("rounding is not supported by %T", arith)
+	}
+
+	// A valid expression never holds more than half its tokens (plus one) on
+	// the stack, so this single allocation covers the whole evaluation
+	stack := make([]T, 0, len(postfixTokens)/2+1)
+
+	// Operands that decide a conditional, "&&" or "||" skip the tokens of
+	// the operand they make unnecessary, which are never evaluated
+	var branches []branch
+	if operators != nil {
+		branches = cfg.branches(postfixTokens)
+	}
+
+	steps := 0
+	for i := 0; i < len(postfixTokens); i++ {
+		token := postfixTokens[i]
+		if err := cfg.step(steps, i); err != nil {
+			return zero, err
+		}
+		steps++
+
+		switch {
+		case cfg.isBinaryOperator(token):
+			// Need at least 2 operands
+			if len(stack) < 2 {
+				return zero, &TokenError{Index: i, Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)}
+			}
+
+			// Pop two operands (note: order matters for - and /)
+			operands := stack[len(stack)-2:]
+			a, b := operands[0], operands[1]
+			stack = stack[:len(stack)-2]
+
+			var result T
+			var err error
+			custom, isCustom := cfg.operators[token]
+			switch {
+			case isCustom && builtin != nil:
+				result, err = builtin.invoke(custom.fn, callToken(token, 2), operands)
+			case token == "+":
+				result, err = arith.Add(a, b)
+			case token == "-":
+				result, err = arith.Sub(a, b)
+			case token == "*":
+				result, err = arith.Mul(a, b)
+			case token == "/":
+				result, err = arith.Div(a, b)
+			case operators != nil:
+				result, err = operators.Binary(token, a, b)
+			default:
+				err = fmt.Errorf("operator '%s' is not supported by %T", token, arith)
+			}
+			if err == nil {
+				result, err = checkFinite(finite, result, cfg)
+			}
+			if err == nil && cfg.roundIntermediates {
+				result, err = roundResult(rounder, result, cfg)
+			}
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: err}
+			}
+
+			stack = append(stack, result)
+
+		case isUnaryOperator(token):
+			if len(stack) < 1 {
+				return zero, &TokenError{Index: i, Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)}
+			}
+			top := &stack[len(stack)-1]
+
+			var result T
+			var err error
+			switch {
+			case operators != nil:
+				result, err = operators.Unary(token, *top)
+			case token == negate:
+				result, err = arith.ParseLiteral("0")
+				if err == nil {
+					result, err = arith.Sub(result, *top)
+				}
+			default:
+				err = fmt.Errorf("operator '%s' is not supported by %T", token, arith)
+			}
+			if err == nil {
+				result, err = checkFinite(finite, result, cfg)
+			}
+			if err == nil && cfg.roundIntermediates {
+				result, err = roundResult(rounder, result, cfg)
+			}
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: err}
+			}
+			*top = result
+
+		case cfg.isCall(token):
+			fn, argc, err := cfg.checkCall(token, len(stack))
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: err}
+			}
+			args := stack[len(stack)-argc:]
+
+			var result T
+			switch {
+			case builtin != nil:
+				result, err = builtin.invoke(fn, token, args)
+			case functions != nil:
+				name, _, _ := splitCall(token)
+				result, err = functions.Call(name, args)
+				if err != nil {
+					err = fmt.Errorf("function '%s': %w", name, err)
+				}
+			default:
+				name, _, _ := splitCall(token)
+				err = fmt.Errorf("function '%s' is not supported by %T", name, arith)
+			}
+			if err == nil {
+				result, err = checkFinite(finite, result, cfg)
+			}
+			if err == nil && cfg.roundIntermediates {
+				result, err = roundResult(rounder, result, cfg)
+			}
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: err}
+			}
+			stack = append(stack[:len(stack)-argc], result)
+
+		case token == ternary && branches != nil:
+			// Only the branch taken was evaluated, above the condition
+			stack[len(stack)-2] = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+		case token == ternary:
+			if len(stack) < 3 {
+				return zero, &TokenError{Index: i, Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)}
+			}
+			if operators == nil {
+				return zero, &TokenError{Index: i, Err: fmt.Errorf("operator '%s' is not supported by %T", token, arith)}
+			}
+			chosen := stack[len(stack)-1]
+			if operators.Truth(stack[len(stack)-3]) {
+				chosen = stack[len(stack)-2]
+			}
+			stack[len(stack)-3] = chosen
+			stack = stack[:len(stack)-2]
+
+		case isVariableName(token) && !isFloatKeyword(token):
+			value, err := cfg.variable(token, i)
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: err}
+			}
+			var result T
+			if variables != nil {
+				result, err = variables.Variable(token, value)
+			} else {
+				result, err = arith.ParseLiteral(strconv.FormatFloat(value, 'g', -1, 64))
+			}
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: cfg.redactLiteral(err, i)}
+			}
+			stack = append(stack, result)
+
+		default:
+			result, err := arith.ParseLiteral(token)
+			if errors.Is(err, ErrInvalidNumber) {
+				err = cfg.invalidNumber(token, i)
+			}
+			if err != nil {
+				return zero, &TokenError{Index: i, Err: cfg.redactLiteral(err, i)}
+			}
+			stack = append(stack, result)
+		}
+
+		// A "&&" or "||" its left operand decides is skipped, its result
+		// taking the place of that operand, and whatever branch the operator
+		// itself starts is taken in turn
+		for branches != nil {
+			b := branches[i]
+			switch b.kind {
+			case branchCondition:
+				if !operators.Truth(stack[len(stack)-1]) {
+					i = b.target - 1
+				}
+			case branchThen:
+				i = b.target - 1
+			case branchAnd, branchOr:
+				// A false left operand of "&&" or a true one of "||" is
+				// combined with itself for the result of the operator
+				top := &stack[len(stack)-1]
+				if operators.Truth(*top) != (b.kind == branchOr) {
+					break
+				}
+				result, err := operators.Binary(postfixTokens[b.target], *top, *top)
+				if err == nil && cfg.roundIntermediates {
+					result, err = roundResult(rounder, result, cfg)
+				}
+				if err != nil {
+					return zero, &TokenError{Index: b.target, Err: err}
+				}
+				*top = result
+				i = b.target
+				continue
+			}
+			break
+		}
+	}
+
+	// Should have exactly one value left
+	if len(stack) != 1 {
+		return zero, fmt.Errorf("invalid expression: too many operands")
+	}
+
+	return roundResult(rounder, stack[0], cfg)
+}
+
+// roundResult applies WithRounding, if given, to a result.
+func roundResult[T any](rounder RoundingArithmetic[T], result T, cfg config) (T, error) {
+	if !cfg.rounding {
+		return result, nil
+	}
+	return rounder.Round(result, cfg.roundScale, cfg.roundMode)
+}
+
+// isFloatKeyword reports whether a name is one strconv.ParseFloat reads as a
+// number, such as "inf" or "NaN", which is a literal rather than a variable.
+func isFloatKeyword(token string) bool {
+	return strings.EqualFold(token, "inf") || strings.EqualFold(token, "infinity") || strings.EqualFold(token, "nan")
+}
+
+// floatArithmetic implements Evaluate's float64 arithmetic.
+type floatArithmetic struct{}
+
+func (floatArithmetic) ParseLiteral(token string) (float64, error) {
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return value, nil
+}
+
+func (floatArithmetic) Add(a, b float64) (float64, error) { return a + b, nil }
+func (floatArithmetic) Sub(a, b float64) (float64, error) { return a - b, nil }
+func (floatArithmetic) Mul(a, b float64) (float64, error) { return a * b, nil }
+func (floatArithmetic) Div(a, b float64) (float64, error) { return applyBinary("/", a, b) }
+
+func (floatArithmetic) Binary(operator string, a, b float64) (float64, error) {
+	return applyBinary(operator, a, b)
+}
+
+func (floatArithmetic) Unary(operator string, a float64) (float64, error) {
+	return applyUnary(operator, a)
+}
+
+func (floatArithmetic) Truth(a float64) bool { return a != 0 }
+
+func (floatArithmetic) Variable(_ string, value float64) (float64, error) { return value, nil }
+
+func (floatArithmetic) Round(a float64, scale int, mode RoundingMode) (float64, error) {
+	return roundFloat(a, scale, mode, 64), nil
+}
+
+func (floatArithmetic) invoke(fn function, token string, args []float64) (float64, error) {
+	return fn.invoke(token, args)
+}
@@ -0,0 +1,298 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+// bigFloatArithmetic is a custom arithmetic on big.Float implementing the
+// optional function interface
+type bigFloatArithmetic struct {
+	prec uint
+}
+
+func (f bigFloatArithmetic) ParseLiteral(token string) (*big.Float, error) {
+	value, _, err := big.ParseFloat(token, 10, f.prec, big.ToNearestEven)
+	if err != nil {
+		return nil, ErrInvalidNumber
+	}
+	return value, nil
+}
+
+func (f bigFloatArithmetic) Add(a, b *big.Float) (*big.Float, error) {
+	return new(big.Float).SetPrec(f.prec).Add(a, b), nil
+}
+
+func (f bigFloatArithmetic) Sub(a, b *big.Float) (*big.Float, error) {
+	return new(big.Float).SetPrec(f.prec).Sub(a, b), nil
+}
+
+func (f bigFloatArithmetic) Mul(a, b *big.Float) (*big.Float, error) {
+	return new(big.Float).SetPrec(f.prec).Mul(a, b), nil
+}
+
+func (f bigFloatArithmetic) Div(a, b *big.Float) (*big.Float, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return new(big.Float).SetPrec(f.prec).Quo(a, b), nil
+}
+
+func (f bigFloatArithmetic) Call(name string, args []*big.Float) (*big.Float, error) {
+	if name != "sqrt" {
+		return nil, errors.New("not supported")
+	}
+	if args[0].Sign() < 0 {
+		return nil, errors.New("negative argument")
+	}
+	return new(big.Float).SetPrec(f.prec).Sqrt(args[0]), nil
+}
+
+// modularArithmetic is a custom arithmetic on integers modulo 7 implementing
+// only the required methods
+type modularArithmetic struct{}
+
+func (modularArithmetic) ParseLiteral(token string) (int, error) {
+	n, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not an integer", token)
+	}
+	return (n%7 + 7) % 7, nil
+}
+
+func (modularArithmetic) Add(a, b int) (int, error) { return (a + b) % 7, nil }
+func (modularArithmetic) Sub(a, b int) (int, error) { return (a - b + 7) % 7, nil }
+func (modularArithmetic) Mul(a, b int) (int, error) { return a * b % 7, nil }
+
+func (modularArithmetic) Div(a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	// Multiply by the inverse, b^5 modulo 7
+	inverse := 1
+	for range 5 {
+		inverse = inverse * b % 7
+	}
+	return a * inverse % 7, nil
+}
+
+// TestEvaluateWith tests evaluation through custom arithmetics
+func TestEvaluateWith(t *testing.T) {
+	bigFloat := bigFloatArithmetic{prec: 200}
+
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		eval       func(postfix []string, opts ...Option) (string, error)
+		expected   string
+		wantErr    string
+	}{
+		{
+			name:       "big float beyond float64 precision",
+			expression: "1 / 3",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, bigFloat, opts...)
+				return result.Text('f', 40), err
+			},
+			expected: "0.3333333333333333333333333333333333333333",
+		},
+		{
+			name:       "comparison without operator support",
+			expression: "sqrt(2) * sqrt(2) < 2",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, bigFloat, opts...)
+				return fmt.Sprint(result), err
+			},
+			wantErr: "operator '<' is not supported by shuntingyard.bigFloatArithmetic",
+		},
+		{
+			name:       "big float function",
+			expression: "sqrt(2) * sqrt(2)",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, bigFloat, opts...)
+				return result.Text('f', 30), err
+			},
+			expected: "2.000000000000000000000000000000",
+		},
+		{
+			name:       "big float function error",
+			expression: "sqrt(-1)",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, bigFloat, opts...)
+				return fmt.Sprint(result), err
+			},
+			wantErr: "function 'sqrt': negative argument",
+		},
+		{
+			name:       "big float variable",
+			expression: "x * 2",
+			opts:       []Option{WithConstant("x", 0.25)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, bigFloat, opts...)
+				return result.Text('g', 10), err
+			},
+			expected: "0.5",
+		},
+		{
+			name:       "modular arithmetic",
+			expression: "3 * 4 + 6 / 2",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			expected: "1",
+		},
+		{
+			name:       "negation without operator support",
+			expression: "-3",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			expected: "4",
+		},
+		{
+			name:       "conditional without operator support",
+			expression: "1 ? 2 : 3",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			wantErr: "operator '?:' is not supported by shuntingyard.modularArithmetic",
+		},
+		{
+			name:       "function without function support",
+			expression: "abs(3)",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			wantErr: "function 'abs' is not supported by shuntingyard.modularArithmetic",
+		},
+		{
+			name:       "literal rejected by arithmetic",
+			expression: "1.5 + 1",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			wantErr: "'1.5' is not an integer",
+		},
+		{
+			name:       "undefined variable",
+			expression: "y + 1",
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			wantErr: "undefined variable 'y'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			result, err := tt.eval(postfix, tt.opts...)
+
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Errorf("EvaluateWith() expected error, got %s", result)
+				} else if err.Error() != tt.wantErr {
+					t.Errorf("EvaluateWith() error = %q, expected %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateWith() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("EvaluateWith(%q) = %s, expected %s", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluateWithInvalidNumber tests that ErrInvalidNumber is reported like Evaluate reports it
+func TestEvaluateWithInvalidNumber(t *testing.T) {
+	postfix := []string{"1.2.3", "1", "+"}
+
+	_, err := EvaluateWith(postfix, bigFloatArithmetic{prec: 64})
+	if err == nil || err.Error() != "invalid number: 1.2.3" {
+		t.Errorf("EvaluateWith() error = %v, expected invalid number: 1.2.3", err)
+	}
+
+	_, err = EvaluateWith(postfix, bigFloatArithmetic{prec: 64}, WithRedactedErrors())
+	if err == nil || err.Error() != "invalid number at token 0" {
+		t.Errorf("EvaluateWith() error = %v, expected invalid number at token 0", err)
+	}
+
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Index != 0 {
+		t.Errorf("EvaluateWith() error = %v, expected a *TokenError at index 0", err)
+	}
+}
+
+func TestRedactedLiteralErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		eval     func(postfix []string, opts ...Option) error
+		input    []string
+		plain    string
+		redacted string
+	}{
+		{
+			name:     "decimal places",
+			eval:     func(postfix []string, opts ...Option) error { _, err := EvaluateDecimal(postfix, opts...); return err },
+			input:    []string{"1", "84123.5512345678901234567", "+"},
+			plain:    "'84123.5512345678901234567' has more than 18 decimal places",
+			redacted: "number at token 1 has more than 18 decimal places",
+		},
+		{
+			name:     "no rational value",
+			eval:     func(postfix []string, opts ...Option) error { _, err := EvaluateRat(postfix, opts...); return err },
+			input:    []string{"inf", "1", "+"},
+			plain:    "'inf' has no exact rational value",
+			redacted: "number at token 0 has no exact rational value",
+		},
+		{
+			name:     "integer overflow",
+			eval:     func(postfix []string, opts ...Option) error { _, err := EvaluateAs[int8](postfix, opts...); return err },
+			input:    []string{"300"},
+			plain:    "'300' overflows int8",
+			redacted: "number at token 0 overflows int8",
+		},
+		{
+			name:     "integer fraction",
+			eval:     func(postfix []string, opts ...Option) error { _, err := EvaluateInt(postfix, opts...); return err },
+			input:    []string{"2", "84123.55", "*"},
+			plain:    "'84123.55' is not an integer",
+			redacted: "number at token 1 is not an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.eval(tt.input); err == nil || err.Error() != tt.plain {
+				t.Errorf("error = %v, expected %q", err, tt.plain)
+			}
+			if err := tt.eval(tt.input, WithRedactedErrors()); err == nil || err.Error() != tt.redacted {
+				t.Errorf("redacted error = %v, expected %q", err, tt.redacted)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package shuntingyard
+
+import "fmt"
+
+// Node is a node of the expression tree ParseAST builds: a *NumberNode,
+// *VariableNode, *UnaryNode, *BinaryNode, *ConditionalNode or *CallNode.
+// Consumers such as pretty printers and code generators switch on its type.
+type Node interface {
+	node()
+}
+
+// NumberNode is a numeric literal. Value is its token, such as "2.5" or
+// "9.81±0.02", which each evaluator reads in its own number type.
+type NumberNode struct {
+	Value string
+}
+
+// VariableNode is a name that isn't a function: a variable or a constant such
+// as pi.
+type VariableNode struct {
+	Name string
+}
+
+// UnaryNode is a prefix operator applied to Operand. Operator is its infix
+// symbol: "-", "!" or "~".
+type UnaryNode struct {
+	Operator string
+	Operand  Node
+}
+
+// BinaryNode is a binary operator, such as "+" or "<=", applied to Left and
+// Right.
+type BinaryNode struct {
+	Operator    string
+	Left, Right Node
+}
+
+// ConditionalNode is the conditional "Condition ? Then : Else".
+type ConditionalNode struct {
+	Condition, Then, Else Node
+}
+
+// CallNode is a call of the function Name with Args.
+type CallNode struct {
+	Name string
+	Args []Node
+}
+
+func (*NumberNode) node()      {}
+func (*VariableNode) node()    {}
+func (*UnaryNode) node()       {}
+func (*BinaryNode) node()      {}
+func (*ConditionalNode) node() {}
+func (*CallNode) node()        {}
+
+// ParseAST converts infix tokens, as returned by Scan, to an expression tree,
+// for consumers that need the structure of an expression rather than the flat
+// postfix slice Parse returns. The tree follows the same precedence and
+// associativity as Parse, and parentheses leave no node of their own:
+// "2 * (3 + x)" is a BinaryNode "*" whose Right is the BinaryNode "+".
+//
+// Returns the root of the tree or the Parse error.
+func ParseAST(tokens []string, opts ...Option) (Node, error) {
+	cfg := newConfig(opts)
+	postfix, _, err := parse(tokens, nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return buildAST(postfix, cfg)
+}
+
+// buildAST builds the expression tree of a postfix expression, each operator
+// taking the nodes of its operands off a stack as Evaluate takes their values.
+func buildAST(postfix []string, cfg config) (Node, error) {
+	stack := make([]Node, 0, len(postfix)/2+1)
+
+	for i, token := range postfix {
+		// Operators and calls need their operands on the stack
+		argc := 0
+		switch {
+		case cfg.isBinaryOperator(token):
+			argc = 2
+		case isUnaryOperator(token):
+			argc = 1
+		case token == ternary:
+			argc = 3
+		case cfg.isCall(token):
+			_, argc, _ = splitCall(token)
+		}
+		if len(stack) < argc {
+			return nil, &TokenError{Index: i, Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)}
+		}
+		operands := stack[len(stack)-argc:]
+
+		var node Node
+		switch {
+		case cfg.isBinaryOperator(token):
+			node = &BinaryNode{Operator: token, Left: operands[0], Right: operands[1]}
+		case token == negate:
+			node = &UnaryNode{Operator: "-", Operand: operands[0]}
+		case isUnaryOperator(token):
+			node = &UnaryNode{Operator: token, Operand: operands[0]}
+		case token == ternary:
+			node = &ConditionalNode{Condition: operands[0], Then: operands[1], Else: operands[2]}
+		case cfg.isCall(token):
+			name, _, _ := splitCall(token)
+			node = &CallNode{Name: name, Args: append([]Node(nil), operands...)}
+		case isVariableName(token) && !isFloatKeyword(token):
+			node = &VariableNode{Name: token}
+		default:
+			node = &NumberNode{Value: token}
+		}
+		stack = append(stack[:len(stack)-argc], node)
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("invalid expression: too many operands")
+	}
+	return stack[0], nil
+}
+
+// ToPostfix converts an expression tree, as built by ParseAST or decoded by
+// UnmarshalAST, back to the postfix tokens Parse returns for it, for Evaluate
+// or any other evaluator.
+//
+// Returns the postfix tokens, or an error for a tree with a nil node or a
+// UnaryNode operator other than "-", "!" and "~".
+func ToPostfix(root Node) ([]string, error) {
+	var postfix []string
+	var walk func(n Node) error
+	walk = func(n Node) error {
+		if isNilNode(n) {
+			return fmt.Errorf("invalid expression tree: nil node")
+		}
+
+		var token string
+		var operands []Node
+		switch n := n.(type) {
+		case *NumberNode:
+			token = n.Value
+		case *VariableNode:
+			token = n.Name
+		case *UnaryNode:
+			switch n.Operator {
+			case "-":
+				token = negate
+			case "!", "~":
+				token = n.Operator
+			default:
+				return fmt.Errorf("invalid expression tree: unknown unary operator '%s'", n.Operator)
+			}
+			operands = []Node{n.Operand}
+		case *BinaryNode:
+			token, operands = n.Operator, []Node{n.Left, n.Right}
+		case *ConditionalNode:
+			token, operands = ternary, []Node{n.Condition, n.Then, n.Else}
+		case *CallNode:
+			token, operands = callToken(n.Name, len(n.Args)), n.Args
+		}
+
+		for _, operand := range operands {
+			if err := walk(operand); err != nil {
+				return err
+			}
+		}
+		postfix = append(postfix, token)
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return postfix, nil
+}
+
+// isNilNode reports whether n is nil or a nil pointer to one of the node
+// types.
+func isNilNode(n Node) bool {
+	switch n := n.(type) {
+	case *NumberNode:
+		return n == nil
+	case *VariableNode:
+		return n == nil
+	case *UnaryNode:
+		return n == nil
+	case *BinaryNode:
+		return n == nil
+	case *ConditionalNode:
+		return n == nil
+	case *CallNode:
+		return n == nil
+	}
+	return n == nil
+}
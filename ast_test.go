@@ -0,0 +1,155 @@
+package shuntingyard
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAST(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   Node
+		wantErr    bool
+	}{
+		{name: "number", expression: "2.5", expected: &NumberNode{Value: "2.5"}},
+		{
+			name:       "precedence",
+			expression: "1 + 2 * x",
+			expected: &BinaryNode{
+				Operator: "+",
+				Left:     &NumberNode{Value: "1"},
+				Right:    &BinaryNode{Operator: "*", Left: &NumberNode{Value: "2"}, Right: &VariableNode{Name: "x"}},
+			},
+		},
+		{
+			name:       "parentheses and left associativity",
+			expression: "(8 - 4) - 2",
+			expected: &BinaryNode{
+				Operator: "-",
+				Left:     &BinaryNode{Operator: "-", Left: &NumberNode{Value: "8"}, Right: &NumberNode{Value: "4"}},
+				Right:    &NumberNode{Value: "2"},
+			},
+		},
+		{
+			name:       "right associative power",
+			expression: "2 ^ 3 ^ 2",
+			expected: &BinaryNode{
+				Operator: "^",
+				Left:     &NumberNode{Value: "2"},
+				Right:    &BinaryNode{Operator: "^", Left: &NumberNode{Value: "3"}, Right: &NumberNode{Value: "2"}},
+			},
+		},
+		{
+			name:       "unary operators",
+			expression: "-x + !~1",
+			expected: &BinaryNode{
+				Operator: "+",
+				Left:     &UnaryNode{Operator: "-", Operand: &VariableNode{Name: "x"}},
+				Right:    &UnaryNode{Operator: "!", Operand: &UnaryNode{Operator: "~", Operand: &NumberNode{Value: "1"}}},
+			},
+		},
+		{
+			name:       "conditional",
+			expression: "a > 0 ? a : inf",
+			expected: &ConditionalNode{
+				Condition: &BinaryNode{Operator: ">", Left: &VariableNode{Name: "a"}, Right: &NumberNode{Value: "0"}},
+				Then:      &VariableNode{Name: "a"},
+				Else:      &NumberNode{Value: "inf"},
+			},
+		},
+		{
+			name:       "calls",
+			expression: "max(1, sqrt(y), pi)",
+			expected: &CallNode{
+				Name: "max",
+				Args: []Node{
+					&NumberNode{Value: "1"},
+					&CallNode{Name: "sqrt", Args: []Node{&VariableNode{Name: "y"}}},
+					&VariableNode{Name: "pi"},
+				},
+			},
+		},
+		{
+			name:       "registered function without arguments",
+			expression: "now()",
+			opts:       []Option{WithFunction("now", 0, func(...float64) (float64, error) { return 0, nil })},
+			expected:   &CallNode{Name: "now"},
+		},
+
+		// Error cases
+		{name: "unmatched parenthesis", expression: "(1 + 2", wantErr: true},
+		{name: "missing operand", expression: "1 +", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			root, err := ParseAST(tokens, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAST() expected error, got %#v", root)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAST() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(root, tt.expected) {
+				t.Errorf("ParseAST() = %#v, expected %#v", root, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToPostfix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Node
+		expected []string
+		wantErr  bool
+	}{
+		{name: "number", input: &NumberNode{Value: "2.5"}, expected: []string{"2.5"}},
+		{
+			name: "operators",
+			input: &BinaryNode{
+				Operator: "*",
+				Left:     &UnaryNode{Operator: "-", Operand: &VariableNode{Name: "x"}},
+				Right:    &UnaryNode{Operator: "!", Operand: &NumberNode{Value: "0"}},
+			},
+			expected: []string{"x", "neg", "0", "!", "*"},
+		},
+		{
+			name:     "conditional and call",
+			input:    &ConditionalNode{Condition: &VariableNode{Name: "c"}, Then: &CallNode{Name: "max", Args: []Node{&NumberNode{Value: "1"}, &NumberNode{Value: "2"}}}, Else: &NumberNode{Value: "3"}},
+			expected: []string{"c", "1", "2", "max/2", "3", "?:"},
+		},
+		{name: "nil root", input: nil, wantErr: true},
+		{name: "nil operand", input: &UnaryNode{Operator: "-"}, wantErr: true},
+		{name: "nil node pointer", input: &BinaryNode{Operator: "+", Left: (*NumberNode)(nil), Right: &NumberNode{Value: "1"}}, wantErr: true},
+		{name: "unknown unary operator", input: &UnaryNode{Operator: "+", Operand: &NumberNode{Value: "1"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToPostfix(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ToPostfix() = %v, expected error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToPostfix() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ToPostfix() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package shuntingyard
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonNode is the JSON form of a Node, an object whose "type" is "number",
+// "variable", "unary", "binary", "conditional" or "call", with the fields of
+// the node type in lower case:
+//
+//	{"type": "binary", "operator": "+",
+//	 "left": {"type": "number", "value": "2"},
+//	 "right": {"type": "call", "name": "max", "args": [
+//	   {"type": "variable", "name": "x"},
+//	   {"type": "unary", "operator": "-", "operand": {"type": "number", "value": "1"}}]}}
+//
+// Number values are strings, keeping each literal exactly as written for the
+// evaluator that reads it.
+type jsonNode struct {
+	Type      string      `json:"type"`
+	Value     string      `json:"value,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Operator  string      `json:"operator,omitempty"`
+	Operand   *jsonNode   `json:"operand,omitempty"`
+	Left      *jsonNode   `json:"left,omitempty"`
+	Right     *jsonNode   `json:"right,omitempty"`
+	Condition *jsonNode   `json:"condition,omitempty"`
+	Then      *jsonNode   `json:"then,omitempty"`
+	Else      *jsonNode   `json:"else,omitempty"`
+	Args      []*jsonNode `json:"args,omitempty"`
+}
+
+// MarshalJSON encodes the node and its operands in the JSON schema
+// UnmarshalAST decodes, so that json.Marshal stores an expression tree,
+// alone or in a larger document, for other services to read.
+func (n *NumberNode) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+
+// MarshalJSON encodes the node as NumberNode.MarshalJSON describes.
+func (n *VariableNode) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+
+// MarshalJSON encodes the node as NumberNode.MarshalJSON describes.
+func (n *UnaryNode) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+
+// MarshalJSON encodes the node as NumberNode.MarshalJSON describes.
+func (n *BinaryNode) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+
+// MarshalJSON encodes the node as NumberNode.MarshalJSON describes.
+func (n *ConditionalNode) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+
+// MarshalJSON encodes the node as NumberNode.MarshalJSON describes.
+func (n *CallNode) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+
+func marshalNode(n Node) ([]byte, error) {
+	j, err := toJSONNode(n)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// toJSONNode converts an expression tree to its JSON form.
+func toJSONNode(n Node) (*jsonNode, error) {
+	if isNilNode(n) {
+		return nil, fmt.Errorf("invalid expression tree: nil node")
+	}
+
+	var j *jsonNode
+	var operands []**jsonNode
+	var nodes []Node
+	switch n := n.(type) {
+	case *NumberNode:
+		return &jsonNode{Type: "number", Value: n.Value}, nil
+	case *VariableNode:
+		return &jsonNode{Type: "variable", Name: n.Name}, nil
+	case *UnaryNode:
+		j = &jsonNode{Type: "unary", Operator: n.Operator}
+		operands, nodes = []**jsonNode{&j.Operand}, []Node{n.Operand}
+	case *BinaryNode:
+		j = &jsonNode{Type: "binary", Operator: n.Operator}
+		operands, nodes = []**jsonNode{&j.Left, &j.Right}, []Node{n.Left, n.Right}
+	case *ConditionalNode:
+		j = &jsonNode{Type: "conditional"}
+		operands, nodes = []**jsonNode{&j.Condition, &j.Then, &j.Else}, []Node{n.Condition, n.Then, n.Else}
+	case *CallNode:
+		j = &jsonNode{Type: "call", Name: n.Name, Args: make([]*jsonNode, len(n.Args))}
+		for i := range j.Args {
+			operands = append(operands, &j.Args[i])
+		}
+		nodes = n.Args
+	}
+
+	for i, operand := range operands {
+		child, err := toJSONNode(nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		*operand = child
+	}
+	return j, nil
+}
+
+// UnmarshalAST decodes an expression tree from the JSON that json.Marshal
+// encodes it in, returning the tree ToPostfix turns into tokens to evaluate:
+//
+//	root, err := UnmarshalAST(data)
+//	...
+//	postfix, err := ToPostfix(root)
+//	...
+//	result, err := Evaluate(postfix)
+//
+// Returns the root of the tree, or an error for invalid JSON or a node of an
+// unknown type or without the fields its type requires.
+func UnmarshalAST(data []byte) (Node, error) {
+	var j *jsonNode
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j.node("")
+}
+
+// node converts the JSON form of a node to the node, path locating it in
+// errors: "left.args[1]" is the second argument of the call on the left of
+// the root.
+func (j *jsonNode) node(path string) (Node, error) {
+	at := func(field string) string {
+		if path == "" {
+			return field
+		}
+		return path + "." + field
+	}
+	invalid := func(format string, args ...any) error {
+		where := "root"
+		if path != "" {
+			where = path
+		}
+		return fmt.Errorf("invalid expression tree at %s: "+format, append([]any{where}, args...)...)
+	}
+	if j == nil {
+		return nil, invalid("missing node")
+	}
+
+	switch j.Type {
+	case "number":
+		if j.Value == "" {
+			return nil, invalid("number without value")
+		}
+		return &NumberNode{Value: j.Value}, nil
+
+	case "variable":
+		if j.Name == "" {
+			return nil, invalid("variable without name")
+		}
+		return &VariableNode{Name: j.Name}, nil
+
+	case "unary":
+		switch j.Operator {
+		case "-", "!", "~":
+		default:
+			return nil, invalid("unknown unary operator '%s'", j.Operator)
+		}
+		x, err := j.Operand.node(at("operand"))
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryNode{Operator: j.Operator, Operand: x}, nil
+
+	case "binary":
+		if j.Operator == "" {
+			return nil, invalid("binary node without operator")
+		}
+		left, err := j.Left.node(at("left"))
+		if err != nil {
+			return nil, err
+		}
+		right, err := j.Right.node(at("right"))
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryNode{Operator: j.Operator, Left: left, Right: right}, nil
+
+	case "conditional":
+		cond, err := j.Condition.node(at("condition"))
+		if err != nil {
+			return nil, err
+		}
+		then, err := j.Then.node(at("then"))
+		if err != nil {
+			return nil, err
+		}
+		otherwise, err := j.Else.node(at("else"))
+		if err != nil {
+			return nil, err
+		}
+		return &ConditionalNode{Condition: cond, Then: then, Else: otherwise}, nil
+
+	case "call":
+		if j.Name == "" {
+			return nil, invalid("call without name")
+		}
+		call := &CallNode{Name: j.Name, Args: make([]Node, len(j.Args))}
+		for i, arg := range j.Args {
+			x, err := arg.node(at(fmt.Sprintf("args[%d]", i)))
+			if err != nil {
+				return nil, err
+			}
+			call.Args[i] = x
+		}
+		return call, nil
+	}
+	return nil, invalid("unknown node type %q", j.Type)
+}
@@ -0,0 +1,143 @@
+package shuntingyard
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestASTJSONSchema(t *testing.T) {
+	root := mustParseAST(t, "2 + max(x, -1) * (c ? 1 : 0)")
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	expected := `{"type":"binary","operator":"+",` +
+		`"left":{"type":"number","value":"2"},` +
+		`"right":{"type":"binary","operator":"*",` +
+		`"left":{"type":"call","name":"max","args":[` +
+		`{"type":"variable","name":"x"},` +
+		`{"type":"unary","operator":"-","operand":{"type":"number","value":"1"}}]},` +
+		`"right":{"type":"conditional",` +
+		`"condition":{"type":"variable","name":"c"},` +
+		`"then":{"type":"number","value":"1"},` +
+		`"else":{"type":"number","value":"0"}}}}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal() = %s, expected %s", data, expected)
+	}
+}
+
+func TestASTJSONRoundTrip(t *testing.T) {
+	expressions := []string{
+		"42",
+		"9.81±0.02 * t",
+		"-(a - b) ^ 2 / !c",
+		"x > 0 && y <= 1 ? sqrt(x) : ~y",
+		"max(1, 2, 3) + min(a, b)",
+	}
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			root := mustParseAST(t, expression)
+			data, err := json.Marshal(root)
+			if err != nil {
+				t.Fatalf("json.Marshal() unexpected error: %v", err)
+			}
+			decoded, err := UnmarshalAST(data)
+			if err != nil {
+				t.Fatalf("UnmarshalAST() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, root) {
+				t.Errorf("UnmarshalAST() = %#v, expected %#v", decoded, root)
+			}
+
+			postfix, err := ToPostfix(decoded)
+			if err != nil {
+				t.Fatalf("ToPostfix() unexpected error: %v", err)
+			}
+			if expected := mustParse(t, expression, nil); !slices.Equal(postfix, expected) {
+				t.Errorf("ToPostfix() = %v, expected %v", postfix, expected)
+			}
+		})
+	}
+}
+
+func TestASTJSONEmbedded(t *testing.T) {
+	type document struct {
+		Name    string `json:"name"`
+		Formula Node   `json:"formula"`
+	}
+	data, err := json.Marshal(document{Name: "area", Formula: mustParseAST(t, "pi * r ^ 2")})
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+
+	var stored struct {
+		Formula json.RawMessage `json:"formula"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+	}
+	root, err := UnmarshalAST(stored.Formula)
+	if err != nil {
+		t.Fatalf("UnmarshalAST() unexpected error: %v", err)
+	}
+	postfix, err := ToPostfix(root)
+	if err != nil {
+		t.Fatalf("ToPostfix() unexpected error: %v", err)
+	}
+	result, err := EvaluateWithVars(postfix, map[string]float64{"r": 2})
+	if err != nil || result != 3.141592653589793*4 {
+		t.Errorf("EvaluateWithVars() = %v, %v, expected %v", result, err, 3.141592653589793*4)
+	}
+}
+
+func TestUnmarshalASTErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "null", input: `null`, expected: "invalid expression tree at root: missing node"},
+		{name: "unknown type", input: `{"type":"matrix"}`, expected: `invalid expression tree at root: unknown node type "matrix"`},
+		{name: "number without value", input: `{"type":"number"}`, expected: "invalid expression tree at root: number without value"},
+		{name: "unknown unary operator", input: `{"type":"unary","operator":"neg","operand":{"type":"number","value":"1"}}`, expected: "invalid expression tree at root: unknown unary operator 'neg'"},
+		{name: "missing operand", input: `{"type":"binary","operator":"+","left":{"type":"number","value":"1"}}`, expected: "invalid expression tree at right: missing node"},
+		{
+			name:     "nested argument",
+			input:    `{"type":"binary","operator":"+","left":{"type":"call","name":"max","args":[{"type":"number","value":"1"},{"type":"variable"}]},"right":{"type":"number","value":"1"}}`,
+			expected: "invalid expression tree at left.args[1]: variable without name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalAST([]byte(tt.input))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("UnmarshalAST() error = %v, expected %q", err, tt.expected)
+			}
+		})
+	}
+
+	if _, err := UnmarshalAST([]byte(`{"type":`)); err == nil {
+		t.Error("UnmarshalAST() expected error for invalid JSON, got nil")
+	}
+}
+
+func TestMarshalASTNilOperand(t *testing.T) {
+	if _, err := json.Marshal(&BinaryNode{Operator: "+", Left: &NumberNode{Value: "1"}}); err == nil {
+		t.Error("json.Marshal() expected error for a nil operand, got nil")
+	}
+}
+
+func mustParseAST(t *testing.T, expression string) Node {
+	t.Helper()
+	tokens, err := Scan(expression)
+	if err != nil {
+		t.Fatalf("Scan(%q) unexpected error: %v", expression, err)
+	}
+	root, err := ParseAST(tokens)
+	if err != nil {
+		t.Fatalf("ParseAST(%q) unexpected error: %v", expression, err)
+	}
+	return root
+}
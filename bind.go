@@ -0,0 +1,107 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BindStruct returns a VariableResolver that reads variables from the exported
+// fields of the struct v, or of the struct v points to, so that values don't
+// have to be copied into a map for every evaluation:
+//
+//	type Order struct {
+//		Total    float64
+//		Quantity int `expr:"qty"`
+//		Customer struct{ Age int }
+//	}
+//	expr, err := Compile("Total / qty", WithVariableResolver(BindStruct(&order)))
+//	...
+//	result, err := expr.Eval(nil)
+//
+// A field is named by its expr tag if it has one, and by its Go name
+// otherwise; a tag of "-" hides it. Fields of nested structs, and of struct
+// pointers, are reached by dotted paths such as "Customer.Age", and fields of
+// embedded structs are promoted as in encoding/json. A path through a nil
+// pointer is undefined. Numeric fields resolve to
+// their value and bool fields to 1 or 0, read when the variable is evaluated,
+// so a resolver bound to a pointer sees later changes.
+//
+// BindStruct panics if v is not a struct or a non-nil pointer to one.
+func BindStruct(v any) VariableResolver {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("shuntingyard: BindStruct of %T, not a struct", v))
+	}
+
+	return VariableResolverFunc(func(path string) (float64, error) {
+		current := value
+		for {
+			name, rest, nested := strings.Cut(path, ".")
+			field, ok := structField(current, name)
+			if !ok {
+				return 0, ErrUndefinedVariable
+			}
+			if !nested {
+				return fieldNumber(name, field)
+			}
+			if field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct {
+				if field.IsNil() {
+					return 0, ErrUndefinedVariable
+				}
+				field = field.Elem()
+			}
+			if field.Kind() != reflect.Struct {
+				return 0, fmt.Errorf("field '%s' of type %s is not a struct", name, field.Type())
+			}
+			current, path = field, rest
+		}
+	})
+}
+
+// structField finds the exported field of s named name, by tag or Go name,
+// searching embedded structs after the fields of s itself.
+func structField(s reflect.Value, name string) (reflect.Value, bool) {
+	var embedded []reflect.Value
+	for i := range s.NumField() {
+		field := s.Type().Field(i)
+		tag := field.Tag.Get("expr")
+		switch {
+		case field.Anonymous && tag == "":
+			inner := s.Field(i)
+			if inner.Kind() == reflect.Pointer && !inner.IsNil() {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct {
+				embedded = append(embedded, inner)
+			}
+		case !field.IsExported() || tag == "-":
+		case tag == name || (tag == "" && field.Name == name):
+			return s.Field(i), true
+		}
+	}
+	for _, inner := range embedded {
+		if field, ok := structField(inner, name); ok {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// fieldNumber converts the value of the named field to a number.
+func fieldNumber(name string, field reflect.Value) (float64, error) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(field.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), nil
+	case reflect.Bool:
+		return fromBool(field.Bool()), nil
+	}
+	return 0, fmt.Errorf("field '%s' of type %s is not a number", name, field.Type())
+}
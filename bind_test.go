@@ -0,0 +1,139 @@
+package shuntingyard
+
+import (
+	"errors"
+	"testing"
+)
+
+type bindAudit struct {
+	Revision int
+}
+
+type bindCustomer struct {
+	Age     int
+	Premium bool
+}
+
+type bindOrder struct {
+	*bindAudit
+
+	Total    float64
+	Quantity int     `expr:"qty"`
+	Discount float32 `expr:"-"`
+	Cents    bindCents
+	Note     string
+	Customer bindCustomer
+	Billing  *bindCustomer
+
+	internal int
+}
+
+type bindCents int64
+
+// TestBindStruct tests resolving variables from struct fields through the full pipeline
+func TestBindStruct(t *testing.T) {
+	order := &bindOrder{
+		bindAudit: &bindAudit{Revision: 4},
+		Total:     120,
+		Quantity:  3,
+		Discount:  0.5,
+		Cents:     1999,
+		Note:      "gift",
+		Customer:  bindCustomer{Age: 42, Premium: true},
+		internal:  1,
+	}
+	resolver := BindStruct(order)
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    error
+	}{
+		{name: "field", expression: "Total * 2", expected: 240},
+		{name: "tagged field", expression: "Total / qty", expected: 40},
+		{name: "named numeric type", expression: "Cents / 100", expected: 19.99},
+		{name: "nested struct", expression: "Customer.Age >= 18", expected: 1},
+		{name: "bool field", expression: "Customer.Premium ? 0.9 : 1", expected: 0.9},
+		{name: "embedded field", expression: "Revision", expected: 4},
+		{name: "constant not shadowed", expression: "round(pi)", expected: 3},
+
+		// Error cases
+		{name: "tagged field by Go name", expression: "Quantity", wantErr: ErrUndefinedVariable},
+		{name: "hidden field", expression: "Discount", wantErr: ErrUndefinedVariable},
+		{name: "unexported field", expression: "internal", wantErr: ErrUndefinedVariable},
+		{name: "missing nested field", expression: "Customer.Name", wantErr: ErrUndefinedVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithVariableResolver(resolver))
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("eval() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+
+	// Values are read at evaluation time
+	order.Total = 60
+	order.Billing = &bindCustomer{Age: 30}
+	result, err := eval("Total + Billing.Age", WithVariableResolver(resolver))
+	if err != nil {
+		t.Fatalf("eval() unexpected error: %v", err)
+	}
+	if result != 90 {
+		t.Errorf("eval() after update = %v, expected 90", result)
+	}
+}
+
+// TestBindStructErrorMessages tests the errors for fields that aren't numbers
+func TestBindStructErrorMessages(t *testing.T) {
+	resolver := BindStruct(bindOrder{})
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: "Note", expected: "variable 'Note': field 'Note' of type string is not a number"},
+		{expression: "Total.Value", expected: "variable 'Total.Value': field 'Total' of type float64 is not a struct"},
+		{expression: "Billing.Age", expected: "undefined variable 'Billing.Age'"},
+	}
+
+	for _, tt := range tests {
+		_, err := eval(tt.expression, WithVariableResolver(resolver))
+		if err == nil {
+			t.Errorf("eval(%q) expected error, got nil", tt.expression)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("eval(%q) error = %q, expected %q", tt.expression, err.Error(), tt.expected)
+		}
+	}
+}
+
+// TestBindStructInvalid tests that BindStruct rejects values other than structs
+func TestBindStructInvalid(t *testing.T) {
+	for _, v := range []any{nil, 42, map[string]float64{}, (*bindOrder)(nil)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("BindStruct(%#v) expected panic", v)
+				}
+			}()
+			BindStruct(v)
+		}()
+	}
+}
@@ -0,0 +1,243 @@
+package shuntingyard
+
+// closure evaluates a compiled subexpression against variables.
+type closure func(vars map[string]float64) (float64, error)
+
+// CompileFunc compiles an infix expression to a Go function, for hot formulas
+// where even the stack machine behind Compile is too slow:
+//
+//	area, err := CompileFunc("pi * r ^ 2")
+//	...
+//	a, err := area(map[string]float64{"r": 2})
+//
+// Every operator, call and operand becomes a closure calling the closures of
+// its operands, so evaluation is a walk of Go function calls with nothing
+// left to decode. The function evaluates like (*Expression).Eval, errors
+// included, and may be called concurrently.
+//
+// Returns the function or the Compile error, or with WithMaxSteps the
+// *TokenError of an expression that could take too many steps.
+func CompileFunc(expression string, opts ...Option) (func(vars map[string]float64) (float64, error), error) {
+	expr, err := Compile(expression, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if limit := expr.cfg.maxSteps; limit > 0 && len(expr.program.code) > limit {
+		return nil, &TokenError{Index: expr.program.code[limit].index, Err: &LimitError{Limit: "evaluation steps", Max: limit}}
+	}
+	fn := expr.program.closure(expr.cfg)
+	return func(vars map[string]float64) (float64, error) {
+		if err := checkVarNames(vars); err != nil {
+			return 0, err
+		}
+		return fn(vars)
+	}, nil
+}
+
+// closure builds the tree of closures of the program, each instruction taking
+// the closures of its operands off a stack as run takes their values.
+func (p *program) closure(cfg config) closure {
+	stack := make([]closure, 0, p.depth)
+	// shared holds the closure of each repeated subexpression, which a
+	// closure computes anew wherever it occurs
+	shared := make([]closure, len(p.shared))
+
+	for _, in := range p.code {
+		index := in.index
+		var fn closure
+		switch in.op {
+		case opBranch, opJump, opAnd, opOr, opMemo:
+			// Closures short-circuit by calling only the operands they need
+			continue
+
+		case opStore:
+			shared[in.slot] = stack[len(stack)-1]
+			continue
+
+		case opLoad:
+			stack = append(stack, shared[in.slot])
+			continue
+
+		case opConst:
+			value := p.consts[in.arg]
+			fn = func(map[string]float64) (float64, error) { return value, nil }
+
+		case opVar:
+			name := p.names[in.arg]
+			fn = func(vars map[string]float64) (float64, error) {
+				if value, ok := vars[name]; ok {
+					return value, nil
+				}
+				// The resolver, constants and fallback, as cfg has no vars
+				value, err := cfg.variable(name, index)
+				if err != nil {
+					return 0, &TokenError{Index: index, Err: err}
+				}
+				return value, nil
+			}
+
+		case opAdd, opSub, opMul, opDiv, opBinary:
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			fn = binaryClosure(p, in, left, right)
+
+		case opNeg, opUnary:
+			operand := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			operator := negate
+			if in.op == opUnary {
+				operator = p.names[in.arg]
+			}
+			fn = func(vars map[string]float64) (float64, error) {
+				a, err := operand(vars)
+				if err != nil {
+					return 0, err
+				}
+				result, err := applyUnary(operator, a)
+				if err != nil {
+					return 0, &TokenError{Index: index, Err: err}
+				}
+				return result, nil
+			}
+
+		case opCall:
+			c := p.calls[in.arg]
+			args := append([]closure(nil), stack[len(stack)-c.argc:]...)
+			stack = stack[:len(stack)-c.argc]
+			fn = func(vars map[string]float64) (float64, error) {
+				values := make([]float64, len(args))
+				for i, arg := range args {
+					value, err := arg(vars)
+					if err != nil {
+						return 0, err
+					}
+					values[i] = value
+				}
+				result, err := c.fn.invoke(c.token, values)
+				if err != nil {
+					return 0, &TokenError{Index: index, Err: err}
+				}
+				return result, nil
+			}
+
+		case opCond:
+			cond, ifTrue, ifFalse := stack[len(stack)-3], stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-3]
+			fn = func(vars map[string]float64) (float64, error) {
+				c, err := cond(vars)
+				if err != nil {
+					return 0, err
+				}
+				if c != 0 {
+					return ifTrue(vars)
+				}
+				return ifFalse(vars)
+			}
+		}
+
+		// Choosing a branch computes nothing to check or round
+		if cfg.nonFinite != nonFinitePropagate && in.op != opConst && in.op != opVar && in.op != opCond {
+			fn = checked(fn, index, cfg)
+		}
+		if cfg.roundIntermediates && in.op != opConst && in.op != opVar && in.op != opCond {
+			fn = rounded(fn, cfg)
+		}
+		stack = append(stack, fn)
+	}
+
+	if cfg.rounding {
+		return rounded(stack[0], cfg)
+	}
+	return stack[0]
+}
+
+// binaryClosure is the closure of a binary instruction, inlining the
+// arithmetic of the four basic operators.
+func binaryClosure(p *program, in instruction, left, right closure) closure {
+	operands := func(vars map[string]float64) (a, b float64, err error) {
+		if a, err = left(vars); err != nil {
+			return 0, 0, err
+		}
+		b, err = right(vars)
+		return a, b, err
+	}
+
+	switch in.op {
+	case opAdd:
+		return func(vars map[string]float64) (float64, error) {
+			a, b, err := operands(vars)
+			return a + b, err
+		}
+	case opSub:
+		return func(vars map[string]float64) (float64, error) {
+			a, b, err := operands(vars)
+			return a - b, err
+		}
+	case opMul:
+		return func(vars map[string]float64) (float64, error) {
+			a, b, err := operands(vars)
+			return a * b, err
+		}
+	}
+
+	operator := "/"
+	if in.op == opBinary {
+		operator = p.names[in.arg]
+	}
+	if operator == "&&" || operator == "||" {
+		// The left operand decides a false "&&" or a true "||" alone
+		or := operator == "||"
+		return func(vars map[string]float64) (float64, error) {
+			a, err := left(vars)
+			if err != nil {
+				return 0, err
+			}
+			if (a != 0) == or {
+				return fromBool(or), nil
+			}
+			b, err := right(vars)
+			if err != nil {
+				return 0, err
+			}
+			return fromBool(b != 0), nil
+		}
+	}
+	return func(vars map[string]float64) (float64, error) {
+		a, b, err := operands(vars)
+		if err != nil {
+			return 0, err
+		}
+		result, err := applyBinary(operator, a, b)
+		if err != nil {
+			return 0, &TokenError{Index: in.index, Err: err}
+		}
+		return result, nil
+	}
+}
+
+// rounded rounds the results of fn as WithRounding asks.
+func rounded(fn closure, cfg config) closure {
+	return func(vars map[string]float64) (float64, error) {
+		result, err := fn(vars)
+		if err != nil {
+			return 0, err
+		}
+		return roundFloat(result, cfg.roundScale, cfg.roundMode, 64), nil
+	}
+}
+
+// checked applies the NaN and infinity policy of cfg to the results of fn, the
+// closure of the postfix token index.
+func checked(fn closure, index int, cfg config) closure {
+	return func(vars map[string]float64) (float64, error) {
+		result, err := fn(vars)
+		if err != nil {
+			return 0, err
+		}
+		result, err = checkFinite[float64](floatArithmetic{}, result, cfg)
+		if err != nil {
+			return 0, &TokenError{Index: index, Err: err}
+		}
+		return result, nil
+	}
+}
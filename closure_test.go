@@ -0,0 +1,117 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestCompileFunc(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		vars       map[string]float64
+	}{
+		{name: "arithmetic", expression: "1 + 2 * 3 - 4 / 8 % 3"},
+		{name: "variables", expression: "price * qty * (1 - discount)", vars: map[string]float64{"price": 20, "qty": 3, "discount": 0.25}},
+		{name: "logic and bitwise", expression: "x > 1 && !(y == 2) || ~x < 0", vars: map[string]float64{"x": 3, "y": 2}},
+		{name: "conditional", expression: "x < 0 ? -x : x ^ 2", vars: map[string]float64{"x": -4}},
+		{name: "functions and constants", expression: "max(abs(x), sqrt(16), 1) + round(pi)", vars: map[string]float64{"x": -7}},
+		{
+			name:       "registered function and operator",
+			expression: "vat(net) <> 100",
+			opts: []Option{
+				WithFunction("vat", 1, func(args ...float64) (float64, error) { return args[0] * 1.25, nil }),
+				WithOperator("<>", 4, LeftAssociative, func(a, b float64) (float64, error) { return math.Abs(a - b), nil }),
+			},
+			vars: map[string]float64{"net": 100},
+		},
+		{name: "resolved variable", expression: "x + y", opts: []Option{WithMissingVariableDefault(10)}, vars: map[string]float64{"x": 1}},
+		{name: "intermediate rounding", expression: "x / 3 + x / 3", opts: []Option{WithRounding(2, RoundHalfEven), WithIntermediateRounding()}, vars: map[string]float64{"x": 1}},
+
+		// Error cases
+		{name: "division by zero", expression: "1 + 2 / (x - 1)", vars: map[string]float64{"x": 1}},
+		{name: "undefined variable", expression: "x + y", vars: map[string]float64{"x": 1}},
+		{name: "error in the branch not taken", expression: "1 ? 2 : 1 / 0"},
+		{name: "function error", expression: "fact(x)", vars: map[string]float64{"x": 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			expected, expectedErr := expr.Eval(tt.vars)
+
+			fn, err := CompileFunc(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("CompileFunc() unexpected error: %v", err)
+			}
+			result, err := fn(tt.vars)
+			if expectedErr != nil {
+				var tokenErr, expectedTokenErr *TokenError
+				if err == nil || err.Error() != expectedErr.Error() {
+					t.Fatalf("fn() error = %v, expected %v", err, expectedErr)
+				}
+				if errors.As(expectedErr, &expectedTokenErr) && (!errors.As(err, &tokenErr) || tokenErr.Index != expectedTokenErr.Index) {
+					t.Errorf("fn() error = %#v, expected token %d", err, expectedTokenErr.Index)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fn() unexpected error: %v", err)
+			}
+			if result != expected {
+				t.Errorf("fn() = %v, Eval() = %v", result, expected)
+			}
+		})
+	}
+}
+
+func TestCompileFuncErrors(t *testing.T) {
+	fn, err := CompileFunc("(1 + 2")
+	if fn != nil || err == nil || err.Error() != "mismatched parentheses: unmatched '(' at token 0" {
+		t.Errorf("CompileFunc() = %p, %v, expected the Parse error", fn, err)
+	}
+}
+
+func TestCompileFuncConcurrent(t *testing.T) {
+	fn, err := CompileFunc("x * x + 1")
+	if err != nil {
+		t.Fatalf("CompileFunc() unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				x := float64(i*100 + j)
+				result, err := fn(map[string]float64{"x": x})
+				if err != nil || result != x*x+1 {
+					t.Errorf("fn(x=%v) = %v, %v, expected %v", x, result, err, x*x+1)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkCompileFunc(b *testing.B) {
+	fn, err := CompileFunc("price * qty * (1 - discount) + max(shipping, 5)")
+	if err != nil {
+		b.Fatalf("CompileFunc() unexpected error: %v", err)
+	}
+	vars := map[string]float64{"price": 19.99, "qty": 3, "discount": 0.1, "shipping": 4}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = fn(vars)
+	}
+}
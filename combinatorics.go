@@ -0,0 +1,169 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// Errors returned by the combinatorics and number-theory functions, wrapped
+// with the name of the function.
+var (
+	errIntegerArgs  = errors.New("requires integer arguments")
+	errCountingArgs = errors.New("requires non-negative integer arguments")
+	errOverflow     = errors.New("result overflows a 64-bit integer")
+)
+
+// integerArgs converts function arguments to integers, rejecting fractions and
+// values outside the int64 range.
+func integerArgs(args []float64) ([]int64, error) {
+	ints := make([]int64, len(args))
+	for i, arg := range args {
+		if arg != math.Trunc(arg) || arg < math.MinInt64 || arg >= math.MaxInt64 {
+			return nil, errIntegerArgs
+		}
+		ints[i] = int64(arg)
+	}
+	return ints, nil
+}
+
+// countingArgs converts function arguments to non-negative integers.
+func countingArgs(args []float64) ([]uint64, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, errCountingArgs
+	}
+	counts := make([]uint64, len(ints))
+	for i, n := range ints {
+		if n < 0 {
+			return nil, errCountingArgs
+		}
+		counts[i] = uint64(n)
+	}
+	return counts, nil
+}
+
+// ncr counts the ways to choose k of n items, C(n, k); it is zero for k > n.
+func ncr(args ...float64) (float64, error) {
+	counts, err := countingArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	n, k := counts[0], counts[1]
+	if k > n {
+		return 0, nil
+	}
+	k = min(k, n-k)
+
+	// C(n, i+1) = C(n, i) * (n-i) / (i+1) is exact at every step; the product
+	// is taken in 128 bits so that it can't overflow before the division
+	result := uint64(1)
+	for i := range k {
+		hi, lo := bits.Mul64(result, n-i)
+		if hi >= i+1 {
+			return 0, errOverflow
+		}
+		result, _ = bits.Div64(hi, lo, i+1)
+		if result > math.MaxInt64 {
+			return 0, errOverflow
+		}
+	}
+	return float64(result), nil
+}
+
+// npr counts the ordered arrangements of k of n items, n! / (n-k)!; it is zero
+// for k > n.
+func npr(args ...float64) (float64, error) {
+	counts, err := countingArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	n, k := counts[0], counts[1]
+	if k > n {
+		return 0, nil
+	}
+	result, ok := fallingFactorial(n, k)
+	if !ok {
+		return 0, errOverflow
+	}
+	return float64(result), nil
+}
+
+// fact computes n!.
+func fact(args ...float64) (float64, error) {
+	counts, err := countingArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	result, ok := fallingFactorial(counts[0], counts[0])
+	if !ok {
+		return 0, errOverflow
+	}
+	return float64(result), nil
+}
+
+// fallingFactorial computes n * (n-1) * ... * (n-k+1), reporting ok == false if
+// the product overflows int64.
+func fallingFactorial(n, k uint64) (result uint64, ok bool) {
+	result = 1
+	for i := range k {
+		hi, lo := bits.Mul64(result, n-i)
+		if hi != 0 || lo > math.MaxInt64 {
+			return 0, false
+		}
+		result = lo
+	}
+	return result, true
+}
+
+// gcd computes the greatest common divisor of its arguments, which is never
+// negative; the divisor of zeros alone is zero.
+func gcd(args ...float64) (float64, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	result := uint64(0)
+	for _, n := range ints {
+		result = euclid(result, absInt(n))
+	}
+	return float64(result), nil
+}
+
+// lcm computes the least common multiple of its arguments, which is never
+// negative; it is zero if any argument is zero.
+func lcm(args ...float64) (float64, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	result := uint64(1)
+	for _, n := range ints {
+		m := absInt(n)
+		if m == 0 {
+			return 0, nil
+		}
+		hi, lo := bits.Mul64(result/euclid(result, m), m)
+		if hi != 0 || lo > math.MaxInt64 {
+			return 0, errOverflow
+		}
+		result = lo
+	}
+	return float64(result), nil
+}
+
+// euclid computes the greatest common divisor of a and b.
+func euclid(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// absInt returns the magnitude of n, which for math.MinInt64 exceeds int64.
+func absInt(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+	return uint64(n)
+}
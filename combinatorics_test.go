@@ -0,0 +1,65 @@
+package shuntingyard
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCombinatorics tests the combinatorics and number-theory functions through the full pipeline
+func TestCombinatorics(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    error
+	}{
+		{name: "combinations", expression: "ncr(5, 2)", expected: 10},
+		{name: "combinations of none", expression: "ncr(7, 0)", expected: 1},
+		{name: "combinations of more than available", expression: "ncr(3, 5)", expected: 0},
+		{name: "large combinations", expression: "ncr(66, 33)", expected: 7219428434016265740},
+		{name: "permutations", expression: "npr(5, 2)", expected: 20},
+		{name: "permutations of more than available", expression: "npr(3, 4)", expected: 0},
+		{name: "factorial", expression: "fact(5)", expected: 120},
+		{name: "factorial of zero", expression: "fact(0)", expected: 1},
+		{name: "largest factorial", expression: "fact(20)", expected: 2432902008176640000},
+		{name: "greatest common divisor", expression: "gcd(12, 18)", expected: 6},
+		{name: "greatest common divisor of several", expression: "gcd(24, -36, 60)", expected: 12},
+		{name: "greatest common divisor with zero", expression: "gcd(0, 9)", expected: 9},
+		{name: "least common multiple", expression: "lcm(4, 6)", expected: 12},
+		{name: "least common multiple of several", expression: "lcm(2, -3, 4)", expected: 12},
+		{name: "least common multiple with zero", expression: "lcm(0, 5)", expected: 0},
+		{name: "probability formula", expression: "ncr(4, 2) * ncr(48, 3) / ncr(52, 5)", expected: 0.03992981808107859},
+
+		// Error cases
+		{name: "factorial overflow", expression: "fact(21)", wantErr: errOverflow},
+		{name: "combinations overflow", expression: "ncr(68, 34)", wantErr: errOverflow},
+		{name: "permutations overflow", expression: "npr(100, 20)", wantErr: errOverflow},
+		{name: "least common multiple overflow", expression: "lcm(4294967311, 4294967357, 3)", wantErr: errOverflow},
+		{name: "fractional factorial", expression: "fact(2.5)", wantErr: errCountingArgs},
+		{name: "negative factorial", expression: "fact(-1)", wantErr: errCountingArgs},
+		{name: "negative combinations", expression: "ncr(5, -1)", wantErr: errCountingArgs},
+		{name: "fractional divisor", expression: "gcd(4, 0.5)", wantErr: errIntegerArgs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("eval() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
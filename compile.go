@@ -0,0 +1,71 @@
+package shuntingyard
+
+// Expression is an expression compiled by Compile: scanned and parsed once,
+// ready to be evaluated any number of times against different variables. An
+// Expression is immutable, so one can be shared by concurrent evaluations.
+type Expression struct {
+	source  string
+	postfix []string
+	program *program
+	cfg     config
+}
+
+// Compile scans and parses an infix expression once, for formulas that are
+// evaluated many times, as by a rule engine:
+//
+//	expr, err := Compile("price * qty * (1 - discount)")
+//	...
+//	for _, order := range orders {
+//		total, err := expr.Eval(map[string]float64{"price": order.Price, ...})
+//	}
+//
+// opts apply to every evaluation of the Expression, and functions and
+// operators must be registered here to be parsed. The postfix expression is
+// compiled in turn to a program for a small stack machine, with its numbers
+// parsed and its functions resolved, so that Eval does no more than the
+// arithmetic. A subexpression that occurs more than once, such as the
+// "sqrt(x*x + y*y)" of a formula using a distance three times, is computed
+// once per Eval, its value reused for the other occurrences, and one of
+// numbers alone, such as the "60 * 60 * 24" of "x * (60 * 60 * 24)", is
+// computed here, unless WithoutConstantFolding is given. Functions and
+// operators registered through opts should therefore be pure.
+//
+// Returns the compiled expression or the Scan or Parse error, or the
+// *TokenError of a number or call that no evaluation could accept.
+func Compile(expression string, opts ...Option) (*Expression, error) {
+	cfg := newConfig(opts)
+	tokens, _, err := scan(expression, cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	postfix, err := parseSource(expression, tokens, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var origin []int
+	if !cfg.noFolding {
+		postfix, origin = foldConstants(postfix, cfg)
+	}
+	program, err := compileProgram(postfix, origin, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: expression, postfix: postfix, program: program, cfg: cfg}, nil
+}
+
+// Eval evaluates the expression like EvaluateWithVars, resolving names in it
+// against vars. vars is only read, so one map can serve concurrent
+// evaluations.
+func (e *Expression) Eval(vars map[string]float64) (float64, error) {
+	if err := checkVarNames(vars); err != nil {
+		return 0, err
+	}
+	cfg := e.cfg
+	cfg.vars = vars
+	return e.program.run(cfg)
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
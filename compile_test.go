@@ -0,0 +1,134 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		vars       map[string]float64
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "constant expression", expression: "2 + 3 * 4", expected: 14},
+		{name: "variables", expression: "price * qty * (1 - discount)", vars: map[string]float64{"price": 20, "qty": 3, "discount": 0.25}, expected: 45},
+		{name: "functions and constants", expression: "max(x, 0) + round(pi)", vars: map[string]float64{"x": -2}, expected: 3},
+		{
+			name:       "registered function",
+			expression: "vat(net)",
+			opts:       []Option{WithFunction("vat", 1, func(args ...float64) (float64, error) { return args[0] * 1.25, nil })},
+			vars:       map[string]float64{"net": 100},
+			expected:   125,
+		},
+		{name: "options apply to every evaluation", expression: "sin(90)", opts: []Option{WithDegrees()}, expected: 1},
+
+		// Error cases
+		{name: "undefined variable", expression: "x + y", vars: map[string]float64{"x": 1}, wantErr: true},
+		{name: "runtime error", expression: "1 / x", vars: map[string]float64{"x": 0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			if expr.String() != tt.expression {
+				t.Errorf("String() = %q, expected %q", expr.String(), tt.expression)
+			}
+
+			result, err := expr.Eval(tt.vars)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Eval() expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+			if math.Abs(result-tt.expected) > 1e-9 {
+				t.Errorf("Eval() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{name: "scan error", expression: "1 + $", expected: "invalid character '$' at position 4"},
+		{name: "parse error", expression: "(1 + 2", expected: "mismatched parentheses: unmatched '(' at token 0"},
+		{name: "multi-line parse error", expression: "1 +\n  (2", expected: "mismatched parentheses: unmatched '(' at 2:3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expression)
+			if expr != nil {
+				t.Errorf("Compile() = %v, expected nil", expr)
+			}
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Compile() error = %v, expected %q", err, tt.expected)
+			}
+		})
+	}
+
+	t.Run("undefined variable", func(t *testing.T) {
+		expr, err := Compile("a * b")
+		if err != nil {
+			t.Fatalf("Compile() unexpected error: %v", err)
+		}
+		_, err = expr.Eval(map[string]float64{"a": 1})
+		if !errors.Is(err, ErrUndefinedVariable) {
+			t.Errorf("Eval() error = %v, expected ErrUndefinedVariable", err)
+		}
+	})
+}
+
+func TestExpressionConcurrentEval(t *testing.T) {
+	expr, err := Compile("x * x + 1")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				x := float64(i*100 + j)
+				result, err := expr.Eval(map[string]float64{"x": x})
+				if err != nil || result != x*x+1 {
+					t.Errorf("Eval(x=%v) = %v, %v, expected %v", x, result, err, x*x+1)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkExpressionEval(b *testing.B) {
+	expr, err := Compile("price * qty * (1 - discount) + max(shipping, 5)")
+	if err != nil {
+		b.Fatalf("Compile() unexpected error: %v", err)
+	}
+	vars := map[string]float64{"price": 19.99, "qty": 3, "discount": 0.1, "shipping": 4}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = expr.Eval(vars)
+	}
+}
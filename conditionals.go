@@ -0,0 +1,33 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+)
+
+// ifThenElse chooses between its second and third arguments like the
+// conditional operator: the second if the first is non-zero, else the third.
+// Being a function, it evaluates both branches.
+func ifThenElse(args ...float64) (float64, error) {
+	return applyTernary(args[0], args[1], args[2]), nil
+}
+
+// coalesce returns its first argument that is not NaN, the value that stands
+// in for a missing one, or NaN if all of them are.
+func coalesce(args ...float64) (float64, error) {
+	for _, arg := range args {
+		if !math.IsNaN(arg) {
+			return arg, nil
+		}
+	}
+	return math.NaN(), nil
+}
+
+// clamp limits x to the range [lo, hi].
+func clamp(args ...float64) (float64, error) {
+	x, lo, hi := args[0], args[1], args[2]
+	if lo > hi {
+		return 0, errors.New("lower bound exceeds upper bound")
+	}
+	return max(lo, min(x, hi)), nil
+}
@@ -0,0 +1,67 @@
+package shuntingyard
+
+import (
+	"math"
+	"testing"
+)
+
+// TestConditionalFunctions tests if, coalesce and clamp through the full pipeline
+func TestConditionalFunctions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "if true", expression: "if(1, 10, 20)", expected: 10},
+		{name: "if false", expression: "if(0, 10, 20)", expected: 20},
+		{name: "if with comparison", expression: "if(3 > 2, 3 * 2, 0)", expected: 6},
+		{name: "nested if", expression: "if(0, 1, if(1, 2, 3))", expected: 2},
+		{name: "if agrees with conditional", expression: "if(5 - 5, 1, 2) == (5 - 5 ? 1 : 2)", expected: 1},
+		{name: "coalesce first", expression: "coalesce(1, 2)", expected: 1},
+		{name: "coalesce skips NaN", expression: "coalesce(nan, nan, 3)", expected: 3},
+		{name: "coalesce keeps zero", expression: "coalesce(0, 5)", expected: 0},
+		{name: "clamp inside", expression: "clamp(5, 0, 10)", expected: 5},
+		{name: "clamp below", expression: "clamp(-3, 0, 10)", expected: 0},
+		{name: "clamp above", expression: "clamp(42, 0, 10)", expected: 10},
+		{name: "clamp to a point", expression: "clamp(7, 3, 3)", expected: 3},
+
+		// Error cases
+		{name: "clamp with crossed bounds", expression: "clamp(5, 10, 0)", wantErr: true},
+		{name: "if without else", expression: "if(1, 2)", wantErr: true},
+		{name: "coalesce of nothing", expression: "coalesce()", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCoalesceAllNaN tests that coalesce of missing values alone is missing
+func TestCoalesceAllNaN(t *testing.T) {
+	result, err := eval("coalesce(nan, nan)")
+	if err != nil {
+		t.Fatalf("eval() unexpected error: %v", err)
+	}
+	if !math.IsNaN(result) {
+		t.Errorf("eval() = %v, expected NaN", result)
+	}
+}
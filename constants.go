@@ -0,0 +1,57 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"maps"
+	"math"
+)
+
+// constants are the built-in named constants available in expressions.
+// Constants registered with WithConstant are consulted first and may shadow
+// these.
+var constants = map[string]float64{
+	"pi":  math.Pi,
+	"e":   math.E,
+	"tau": 2 * math.Pi,
+}
+
+// WithConstant registers a named constant that expressions can use like a
+// number, such as g = 9.81 in "0.5 * g * t ^ 2". Pass it in
+// EvaluatorConfig.Options to give every expression an Evaluator runs the same
+// constants. A registered constant shadows a built-in one of the same name,
+// and a value given to EvaluateWithVars shadows both.
+//
+// WithConstant panics if name is not a valid name (ASCII letters, digits, and
+// underscores, not starting with a digit), is a keyword operator such as
+// "xor", or is a float keyword: "inf", "infinity" or "nan" in any case.
+func WithConstant(name string, value float64) Option {
+	if !isIdentifier(name) || isBinaryOperator(name) || isFloatKeyword(name) {
+		panic(fmt.Sprintf("shuntingyard: invalid constant name %q", name))
+	}
+
+	return func(c *config) {
+		// Copy so that options sharing a config don't share the map
+		registered := make(map[string]float64, len(c.constants)+1)
+		maps.Copy(registered, c.constants)
+		registered[name] = value
+		c.constants = registered
+	}
+}
+
+// constant returns the value of the named constant, registered or built-in.
+func (c config) constant(name string) (float64, bool) {
+	if value, ok := c.constants[name]; ok {
+		return value, true
+	}
+	value, ok := constants[name]
+	return value, ok
+}
+
+// builtinConstant reports whether name resolves to a built-in constant rather
+// than a variable or registered constant.
+func (c config) builtinConstant(name string) bool {
+	_, variable := c.vars[name]
+	_, registered := c.constants[name]
+	_, builtin := constants[name]
+	return builtin && !variable && !registered
+}
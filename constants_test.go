@@ -0,0 +1,95 @@
+package shuntingyard
+
+import (
+	"math"
+	"testing"
+)
+
+// TestConstants tests built-in and registered constants through the full pipeline
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "pi", expression: "pi", expected: math.Pi},
+		{name: "e", expression: "e ^ 2", expected: math.E * math.E},
+		{name: "tau", expression: "tau / 2 == pi", expected: 1},
+		{name: "constant in function", expression: "cos(pi)", expected: -1},
+		{name: "registered constant", expression: "0.5 * g * 2 ^ 2", opts: []Option{WithConstant("g", 9.81)}, expected: 19.62},
+		{name: "several registered constants", expression: "a + b", opts: []Option{WithConstant("a", 1), WithConstant("b", 2)}, expected: 3},
+		{name: "registered constant shadows built-in", expression: "pi", opts: []Option{WithConstant("pi", 3)}, expected: 3},
+
+		// Error cases
+		{name: "unknown name", expression: "pie", wantErr: true},
+		{name: "constant called as function", expression: "pi(2)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestConstantsShadowedByVariables tests that values given to EvaluateWithVars take precedence
+func TestConstantsShadowedByVariables(t *testing.T) {
+	result, err := EvaluateWithVars([]string{"e", "g", "+"}, map[string]float64{"e": 1, "g": 2}, WithConstant("g", 9.81))
+	if err != nil {
+		t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+	}
+	if result != 3 {
+		t.Errorf("EvaluateWithVars() = %v, expected 3", result)
+	}
+}
+
+// TestConstantsExactness tests how constants carry exactness in EvaluateResult
+func TestConstantsExactness(t *testing.T) {
+	result, err := EvaluateResult([]string{"pi", "2", "*"})
+	if err != nil {
+		t.Fatalf("EvaluateResult() unexpected error: %v", err)
+	}
+	if result.Exact != nil {
+		t.Errorf("EvaluateResult() exact = %s, expected nil for pi", result.Exact.RatString())
+	}
+
+	result, err = EvaluateResult([]string{"g", "3", "/"}, WithConstant("g", 9.81))
+	if err != nil {
+		t.Fatalf("EvaluateResult() unexpected error: %v", err)
+	}
+	if result.Exact == nil || result.Exact.RatString() != "327/100" {
+		t.Errorf("EvaluateResult() = %s, expected exact 327/100", result)
+	}
+}
+
+// TestWithConstantInvalid tests that invalid constant names are rejected
+func TestWithConstantInvalid(t *testing.T) {
+	for _, name := range []string{"", "2x", "a-b", "xor", "nan", "Inf", "infinity"} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithConstant(%q) expected panic", name)
+				}
+			}()
+			WithConstant(name, 1)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package shuntingyard
+
+import "context"
+
+// contextCheckInterval is how many tokens or instructions an evaluation under
+// a context gets through between checks of it.
+const contextCheckInterval = 64
+
+// EvaluateContext evaluates a postfix expression like Evaluate, giving up once
+// ctx is done, so that callers can put a deadline on, or cancel, expressions
+// from untrusted sources that would otherwise run for too long:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+//	defer cancel()
+//	result, err := EvaluateContext(ctx, postfix)
+//
+// ctx is checked before the first token and then every few dozen tokens, so a
+// single registered function that doesn't return is not interrupted.
+//
+// Returns the result, the Evaluate error, or ctx.Err() when ctx is done.
+func EvaluateContext(ctx context.Context, postfixTokens []string, opts ...Option) (float64, error) {
+	cfg := newConfig(opts)
+	cfg.ctx = ctx
+	return evaluate(postfixTokens, cfg)
+}
+
+// EvalContext evaluates the expression like Eval, giving up once ctx is done
+// as EvaluateContext does.
+func (e *Expression) EvalContext(ctx context.Context, vars map[string]float64) (float64, error) {
+	if err := checkVarNames(vars); err != nil {
+		return 0, err
+	}
+	cfg := e.cfg
+	cfg.vars = vars
+	cfg.ctx = ctx
+	return e.program.run(cfg)
+}
+
+// withContext makes evaluation give up once ctx is done, as EvaluateContext
+// does, for entry points that evaluate infix expressions.
+func withContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.ctx = ctx
+	}
+}
+
+// interrupted returns the error of the context of EvaluateContext, if it is
+// done, when step i of an evaluation is due to check it.
+func (c config) interrupted(i int) error {
+	if c.ctx == nil || i%contextCheckInterval != 0 {
+		return nil
+	}
+	return c.ctx.Err()
+}
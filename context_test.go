@@ -0,0 +1,89 @@
+package shuntingyard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateContext(t *testing.T) {
+	t.Run("evaluates like Evaluate", func(t *testing.T) {
+		result, err := EvaluateContext(context.Background(), []string{"2", "3", "4", "*", "+"})
+		if err != nil || result != 14 {
+			t.Errorf("EvaluateContext() = %v, %v, expected 14", result, err)
+		}
+	})
+
+	t.Run("cancelled before evaluation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := EvaluateContext(ctx, []string{"2", "3", "+"})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("EvaluateContext() error = %v, expected context.Canceled", err)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		_, err := EvaluateContext(ctx, []string{"2", "3", "+"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("EvaluateContext() error = %v, expected context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("cancelled during evaluation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stop := WithFunction("stop", 1, func(args ...float64) (float64, error) {
+			cancel()
+			return args[0], nil
+		})
+		postfix := cancellingPostfix(t, stop)
+
+		_, err := EvaluateContext(ctx, postfix, stop)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("EvaluateContext() error = %v, expected context.Canceled", err)
+		}
+	})
+}
+
+func TestExpressionEvalContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := WithFunction("stop", 1, func(args ...float64) (float64, error) {
+		cancel()
+		return args[0], nil
+	})
+	expr, err := Compile("stop(x)"+strings.Repeat(" + x", 200), stop)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	vars := map[string]float64{"x": 1}
+
+	result, err := expr.EvalContext(context.Background(), vars)
+	if err != nil || result != 201 {
+		t.Errorf("EvalContext() = %v, %v, expected 201", result, err)
+	}
+	_, err = expr.EvalContext(ctx, vars)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("EvalContext() error = %v, expected context.Canceled", err)
+	}
+}
+
+// cancellingPostfix parses an expression that calls stop and then goes on for
+// long enough for the evaluation to check its context again.
+func cancellingPostfix(t *testing.T, stop Option) []string {
+	t.Helper()
+	tokens, err := Scan("stop(1)" + strings.Repeat(" + 1", 200))
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	postfix, err := Parse(tokens, stop)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	return postfix
+}
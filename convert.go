@@ -0,0 +1,67 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"math"
+)
+
+// intTolerance is the relative distance from the nearest integer that EvalInt
+// still accepts, absorbing float artifacts such as 0.1 * 30 = 3.0000000000000004.
+const intTolerance = 1e-9
+
+// EvalInt scans, parses, and evaluates an infix expression and converts the
+// result to int64. Results within a small relative tolerance of an integer are
+// rounded to it; anything else is an error rather than being truncated.
+//
+// Returns the integer result, the pipeline error, or an error if the result is
+// not integral or does not fit in an int64.
+func EvalInt(expression string, opts ...Option) (int64, error) {
+	cfg := newConfig(opts)
+
+	result, err := eval(expression, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	rounded := math.Round(result)
+	if math.IsNaN(result) || math.Abs(result-rounded) > intTolerance*math.Max(1, math.Abs(result)) {
+		if cfg.redactErrors {
+			return 0, fmt.Errorf("result is not an integer")
+		}
+		return 0, fmt.Errorf("result %v is not an integer", result)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, so it must be excluded
+	if rounded < math.MinInt64 || rounded >= math.MaxInt64 {
+		if cfg.redactErrors {
+			return 0, fmt.Errorf("result is out of integer range")
+		}
+		return 0, fmt.Errorf("result %v is out of integer range", result)
+	}
+
+	return int64(rounded), nil
+}
+
+// EvalBool scans, parses, and evaluates an infix expression and converts the
+// result to a bool. The result must be exactly 1 (true) or 0 (false).
+//
+// Returns the boolean result, the pipeline error, or an error for any other value.
+func EvalBool(expression string, opts ...Option) (bool, error) {
+	cfg := newConfig(opts)
+
+	result, err := eval(expression, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	switch result {
+	case 1:
+		return true, nil
+	case 0:
+		return false, nil
+	}
+
+	if cfg.redactErrors {
+		return false, fmt.Errorf("result is not a boolean")
+	}
+	return false, fmt.Errorf("result %v is not a boolean", result)
+}
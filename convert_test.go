@@ -0,0 +1,89 @@
+package shuntingyard
+
+import "testing"
+
+// TestEvalInt tests evaluating expressions to validated integers
+func TestEvalInt(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   int64
+		wantErr    bool
+	}{
+		{name: "integer result", expression: "2 + 3 * 4", expected: 14},
+		{name: "exact division", expression: "10 / 2", expected: 5},
+		{name: "float artifact", expression: "0.1 * 30", expected: 3},
+		{name: "negative result", expression: "2 - 7", expected: -5},
+		{name: "large integer", expression: "1000000 * 1000000", expected: 1000000000000},
+
+		// Error cases
+		{name: "fractional result", expression: "10 / 4", wantErr: true},
+		{name: "repeating decimal", expression: "10 / 3", wantErr: true},
+		{name: "out of range", expression: "10000000000 * 10000000000", wantErr: true},
+		{name: "pipeline error", expression: "10 / 0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvalInt(tt.expression)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvalInt() expected error, got %d", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvalInt() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("EvalInt(%q) = %d, expected %d", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvalBool tests evaluating expressions to validated booleans
+func TestEvalBool(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   bool
+		wantErr    bool
+	}{
+		{name: "one is true", expression: "1", expected: true},
+		{name: "zero is false", expression: "0", expected: false},
+		{name: "computed true", expression: "3 - 2", expected: true},
+		{name: "computed false", expression: "2 * 0", expected: false},
+
+		// Error cases
+		{name: "other integer", expression: "2", wantErr: true},
+		{name: "fraction", expression: "1 / 2", wantErr: true},
+		{name: "pipeline error", expression: "(1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvalBool(tt.expression)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvalBool() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvalBool() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("EvalBool(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale is the most decimal places a Decimal holds; int64 has room
+// for 18 digits whatever their value.
+const maxDecimalScale = 18
+
+// divisionScale is the number of decimal places EvaluateDecimal gives a
+// quotient that doesn't terminate sooner, such as 1 / 3.
+const divisionScale = 16
+
+// Decimal is a fixed-point decimal number, Unscaled × 10^-Scale, so that 19.99
+// is {1999, 2}. The scale is part of the value as written: 1.50 has scale 2.
+type Decimal struct {
+	Unscaled int64
+	Scale    int
+}
+
+// String renders d with exactly Scale decimal places, e.g. "24.19" or "-0.50".
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.Unscaled, 10)
+	sign := ""
+	if d.Unscaled < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+}
+
+// Rat returns d as an exact rational.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.Unscaled), pow10(d.Scale))
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// EvaluateDecimal computes the result of a postfix (RPN) expression in
+// fixed-point decimal arithmetic, so that monetary formulas such as
+// "19.99 * 1.21" don't pick up binary floating-point artifacts: the result is
+// exactly 24.1879, which round(19.99 * 1.21, 2) makes 24.19.
+//
+// Literals keep the decimal places they are written with, and variables get
+// those of the shortest decimal form of their float64 values. Sums,
+// differences and remainders have the larger scale of their operands, and
+// products the sum of their scales. A quotient has the larger scale of its
+// operands, or up to 16 places when it doesn't terminate sooner. Results past
+// 18 decimal places are rounded half away from zero; results that don't fit
+// an int64 at their scale are an overflow error. Exponents must be integers.
+// The functions available are those of EvaluateRat.
+//
+// Returns the result or an error for invalid expressions, division or modulo
+// by zero, overflow, and operations without a decimal value, such as
+// "2 ^ 0.5". Failures caused by a specific token are reported as a
+// *TokenError holding its index.
+func EvaluateDecimal(postfixTokens []string, opts ...Option) (Decimal, error) {
+	cfg := newConfig(opts)
+	return evaluateWith(postfixTokens, Arithmetic[Decimal](decimalArithmetic{ratArithmetic{cfg: &cfg}}), cfg)
+}
+
+// decimalArithmetic implements EvaluateDecimal's fixed-point arithmetic,
+// computing each operation exactly as EvaluateRat does before giving the
+// result its scale.
+type decimalArithmetic struct {
+	exact ratArithmetic
+}
+
+// ParseLiteral keeps the places a literal is written with, as in "1.50";
+// other forms get as many as the value needs.
+func (d decimalArithmetic) ParseLiteral(token string) (Decimal, error) {
+	exact, err := d.exact.ParseLiteral(token)
+	if err != nil {
+		return Decimal{}, err
+	}
+	scale := 0
+	if _, fraction, ok := strings.Cut(token, "."); ok && strings.Trim(fraction, "0123456789") == "" {
+		scale = len(fraction)
+	}
+	for scale <= maxDecimalScale && !isDecimalAt(exact, scale) {
+		scale++
+	}
+	if scale > maxDecimalScale {
+		return Decimal{}, &literalError{token: token, problem: fmt.Sprintf("has more than %d decimal places", maxDecimalScale)}
+	}
+	return toDecimal(exact, scale)
+}
+
+func (d decimalArithmetic) Add(a, b Decimal) (Decimal, error) { return d.Binary("+", a, b) }
+func (d decimalArithmetic) Sub(a, b Decimal) (Decimal, error) { return d.Binary("-", a, b) }
+func (d decimalArithmetic) Mul(a, b Decimal) (Decimal, error) { return d.Binary("*", a, b) }
+func (d decimalArithmetic) Div(a, b Decimal) (Decimal, error) { return d.Binary("/", a, b) }
+
+func (decimalArithmetic) Binary(operator string, a, b Decimal) (Decimal, error) {
+	exact, err := ratBinary(operator, a.Rat(), b.Rat())
+	if err != nil {
+		return Decimal{}, err
+	}
+	return toDecimal(exact, binaryScale(operator, a, b, exact))
+}
+
+func (decimalArithmetic) Unary(operator string, a Decimal) (Decimal, error) {
+	exact, err := ratUnary(operator, a.Rat())
+	if err != nil {
+		return Decimal{}, err
+	}
+	scale := 0
+	if operator == negate {
+		scale = a.Scale
+	}
+	return toDecimal(exact, scale)
+}
+
+func (decimalArithmetic) Truth(a Decimal) bool { return a.Unscaled != 0 }
+
+// Variable gives a value the places of the shortest decimal form of its
+// float64.
+func (d decimalArithmetic) Variable(name string, value float64) (Decimal, error) {
+	if d.exact.cfg.builtinConstant(name) {
+		return Decimal{}, fmt.Errorf("constant '%s' has no exact decimal value", name)
+	}
+	return d.ParseLiteral(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Round gives a exactly scale places, or none for a negative scale.
+func (decimalArithmetic) Round(a Decimal, scale int, mode RoundingMode) (Decimal, error) {
+	return toDecimal(ratRoundMode(a.Rat(), int64(scale), mode), max(scale, 0))
+}
+
+func (d decimalArithmetic) invoke(fn function, token string, args []Decimal) (Decimal, error) {
+	exactArgs := make([]*big.Rat, len(args))
+	scale := 0
+	for j, arg := range args {
+		exactArgs[j] = arg.Rat()
+		scale = max(scale, arg.Scale)
+	}
+	exact, err := d.exact.cfg.exactCall(fn, token, exactArgs, "decimal")
+	if err != nil {
+		return Decimal{}, err
+	}
+	// Rounding to places sets the scale; the other functions pick a value
+	// among, or derived from, their arguments
+	switch name, _, _ := splitCall(token); {
+	case name == "round" && len(args) == 2:
+		scale = max(0, int(args[1].Rat().Num().Int64()))
+	case name == "round", name == "floor", name == "ceil", name == "trunc":
+		scale = 0
+	}
+	return toDecimal(exact, scale)
+}
+
+// binaryScale is the scale of the result of a binary operator on a and b,
+// whose exact value is exact.
+func binaryScale(operator string, a, b Decimal, exact *big.Rat) int {
+	switch operator {
+	case "+", "-", "%":
+		return max(a.Scale, b.Scale)
+	case "*":
+		return a.Scale + b.Scale
+	case "/":
+		return quotientScale(exact, max(a.Scale, b.Scale))
+	case "^":
+		n := b.Rat().Num().Int64()
+		if n < 0 {
+			return quotientScale(exact, a.Scale)
+		}
+		return a.Scale * int(min(n, maxDecimalScale))
+	}
+	// Comparisons, logical and bitwise operators yield integers
+	return 0
+}
+
+// quotientScale picks the scale for a quotient: at least scale, or as many
+// places as the exact value needs up to divisionScale.
+func quotientScale(exact *big.Rat, scale int) int {
+	for s := scale; s < divisionScale; s++ {
+		if isDecimalAt(exact, s) {
+			return s
+		}
+	}
+	return max(scale, divisionScale)
+}
+
+// isDecimalAt reports whether x has at most scale decimal places.
+func isDecimalAt(x *big.Rat, scale int) bool {
+	return new(big.Rat).Mul(x, new(big.Rat).SetInt(pow10(scale))).IsInt()
+}
+
+// toDecimal rounds x half away from zero to scale places, at most
+// maxDecimalScale, and checks that the result fits a Decimal.
+func toDecimal(x *big.Rat, scale int) (Decimal, error) {
+	scale = min(scale, maxDecimalScale)
+	rounded := ratRound(x, int64(scale))
+	unscaled := new(big.Int).Mul(rounded.Num(), pow10(scale))
+	unscaled.Quo(unscaled, rounded.Denom())
+	if !unscaled.IsInt64() {
+		return Decimal{}, fmt.Errorf("decimal overflow")
+	}
+	return Decimal{Unscaled: unscaled.Int64(), Scale: scale}, nil
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,105 @@
+package shuntingyard
+
+import "testing"
+
+// TestEvaluateDecimal tests fixed-point decimal evaluation through the full pipeline
+func TestEvaluateDecimal(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   string // Decimal.String
+		wantErr    bool
+	}{
+		{name: "price with VAT", expression: "19.99 * 1.21", expected: "24.1879"},
+		{name: "rounded to cents", expression: "round(19.99 * 1.21, 2)", expected: "24.19"},
+		{name: "decimal sum is exact", expression: "0.1 + 0.2", expected: "0.3"},
+		{name: "exact equality", expression: "0.1 + 0.2 == 0.3", expected: "1"},
+		{name: "sum keeps larger scale", expression: "1.50 + 2", expected: "3.50"},
+		{name: "product adds scales", expression: "0.5 * 0.5", expected: "0.25"},
+		{name: "exponent literal", expression: "2.5E-3 * 2", expected: "0.0050"},
+		{name: "terminating quotient", expression: "10 / 4", expected: "2.5"},
+		{name: "quotient keeps operand scale", expression: "10.00 / 4", expected: "2.50"},
+		{name: "repeating quotient", expression: "1 / 3", expected: "0.3333333333333333"},
+		{name: "quotient rounds half away from zero", expression: "-2 / 3", expected: "-0.6666666666666667"},
+		{name: "integer power", expression: "1.1 ^ 2", expected: "1.21"},
+		{name: "negative power", expression: "2 ^ -2", expected: "0.25"},
+		{name: "modulo", expression: "7.5 % 2", expected: "1.5"},
+		{name: "negation", expression: "-0.50", expected: "-0.50"},
+		{name: "conditional", expression: "1 > 0 ? 2.50 : 3", expected: "2.50"},
+		{name: "functions", expression: "max(1.25, 1.5) + abs(-0.05)", expected: "1.55"},
+		{name: "floor", expression: "floor(2.75)", expected: "2"},
+		{name: "products past 18 places round", expression: "0.0000000001 * 0.0000000015", expected: "0.000000000000000000"},
+		{name: "variable", expression: "price * 3", opts: []Option{WithConstant("price", 19.99)}, expected: "59.97"},
+
+		// Error cases
+		{name: "division by zero", expression: "1 / (0.1 + 0.2 - 0.3)", wantErr: true},
+		{name: "fractional exponent", expression: "2 ^ 0.5", wantErr: true},
+		{name: "overflow", expression: "9223372036854775807 + 1", wantErr: true},
+		{name: "overflow at scale", expression: "10000000000 * 1.000000000", wantErr: true},
+		{name: "too many places", expression: "0.0000000000000000001", wantErr: true},
+		{name: "infinite literal", expression: "inf + 1", wantErr: true},
+		{name: "irrational constant", expression: "pi * 2", wantErr: true},
+		{name: "inexact function", expression: "sqrt(4)", wantErr: true},
+		{name: "undefined variable", expression: "y + 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			result, err := EvaluateDecimal(postfix, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateDecimal() expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateDecimal() unexpected error: %v", err)
+				return
+			}
+
+			if result.String() != tt.expected {
+				t.Errorf("EvaluateDecimal(%q) = %s, expected %s", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDecimal tests the conversions of a Decimal
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		decimal  Decimal
+		expected string
+		float    float64
+	}{
+		{decimal: Decimal{Unscaled: 1999, Scale: 2}, expected: "19.99", float: 19.99},
+		{decimal: Decimal{Unscaled: -5, Scale: 3}, expected: "-0.005", float: -0.005},
+		{decimal: Decimal{Unscaled: 42}, expected: "42", float: 42},
+		{decimal: Decimal{Unscaled: 0, Scale: 2}, expected: "0.00", float: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.decimal.String(); got != tt.expected {
+				t.Errorf("String() = %s, expected %s", got, tt.expected)
+			}
+			if got := tt.decimal.Float64(); got != tt.float {
+				t.Errorf("Float64() = %v, expected %v", got, tt.float)
+			}
+			if got := tt.decimal.Rat().FloatString(tt.decimal.Scale); got != tt.expected {
+				t.Errorf("Rat() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
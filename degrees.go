@@ -0,0 +1,52 @@
+package shuntingyard
+
+import "math"
+
+// WithDegrees makes the built-in trigonometric functions work in degrees
+// instead of radians, as a calculator does: sin, cos and tan take their
+// argument in degrees, and asin, acos, atan and atan2 return degrees. Sine and
+// cosine are exactly zero at multiples of 90 degrees, so sin(180) is 0 rather
+// than the 1.2246467991473532e-16 that a conversion to radians gives. The
+// hyperbolic functions and functions registered with WithFunction are
+// unaffected.
+func WithDegrees() Option {
+	return func(c *config) {
+		c.degrees = true
+	}
+}
+
+// degreeFunctions replace the trigonometric built-ins under WithDegrees.
+var degreeFunctions = map[string]function{
+	"sin":   unary(sinDegrees),
+	"cos":   unary(cosDegrees),
+	"tan":   unary(func(x float64) float64 { return sinDegrees(x) / cosDegrees(x) }),
+	"asin":  unary(func(x float64) float64 { return toDegrees(math.Asin(x)) }),
+	"acos":  unary(func(x float64) float64 { return toDegrees(math.Acos(x)) }),
+	"atan":  unary(func(x float64) float64 { return toDegrees(math.Atan(x)) }),
+	"atan2": binary(func(y, x float64) float64 { return toDegrees(math.Atan2(y, x)) }),
+}
+
+// sinDegrees computes the sine of x degrees.
+func sinDegrees(x float64) float64 {
+	// Reduce first: math.Mod is exact, while x * π/180 loses precision for
+	// large angles
+	x = math.Mod(x, 360)
+	if math.Mod(x, 180) == 0 {
+		return 0
+	}
+	return math.Sin(x * math.Pi / 180)
+}
+
+// cosDegrees computes the cosine of x degrees.
+func cosDegrees(x float64) float64 {
+	x = math.Mod(x, 360)
+	if math.Abs(math.Mod(x, 180)) == 90 {
+		return 0
+	}
+	return math.Cos(x * math.Pi / 180)
+}
+
+// toDegrees converts an angle in radians to degrees.
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
@@ -0,0 +1,67 @@
+package shuntingyard
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDegrees tests trigonometric functions in degrees mode through the full pipeline
+func TestDegrees(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+	}{
+		{name: "sine", expression: "sin(30)", expected: 0.5},
+		{name: "sine of right angle", expression: "sin(90)", expected: 1},
+		{name: "sine of straight angle is zero", expression: "sin(180)", expected: 0},
+		{name: "sine of large angle", expression: "sin(360 * 1000000 + 90)", expected: 1},
+		{name: "negative sine", expression: "sin(-90)", expected: -1},
+		{name: "cosine", expression: "cos(60)", expected: 0.5},
+		{name: "cosine of right angle is zero", expression: "cos(90)", expected: 0},
+		{name: "cosine of negative right angle is zero", expression: "cos(-270)", expected: 0},
+		{name: "cosine of straight angle", expression: "cos(180)", expected: -1},
+		{name: "tangent", expression: "tan(45)", expected: 1},
+		{name: "tangent of straight angle is zero", expression: "tan(180)", expected: 0},
+		{name: "inverse sine", expression: "asin(1)", expected: 90},
+		{name: "inverse cosine", expression: "acos(-1)", expected: 180},
+		{name: "inverse tangent", expression: "atan(1)", expected: 45},
+		{name: "two-argument inverse tangent", expression: "atan2(1, -1)", expected: 135},
+		{name: "round trip", expression: "asin(sin(30))", expected: 30},
+		{name: "hyperbolic functions unaffected", expression: "sinh(1)", expected: math.Sinh(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithDegrees())
+			if err != nil {
+				t.Fatalf("eval() unexpected error: %v", err)
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDegreesOptional tests that radians remain the default and registered functions take precedence
+func TestDegreesOptional(t *testing.T) {
+	result, err := eval("sin(90)")
+	if err != nil {
+		t.Fatalf("eval() unexpected error: %v", err)
+	}
+	if result != math.Sin(90) {
+		t.Errorf("eval() without WithDegrees = %v, expected %v", result, math.Sin(90))
+	}
+
+	result, err = eval("sin(90)", WithDegrees(), WithFunction("sin", 1, func(args ...float64) (float64, error) {
+		return math.Sin(args[0]), nil
+	}))
+	if err != nil {
+		t.Fatalf("eval() unexpected error: %v", err)
+	}
+	if result != math.Sin(90) {
+		t.Errorf("eval() with registered sin = %v, expected %v", result, math.Sin(90))
+	}
+}
@@ -0,0 +1,78 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT renders an expression tree, as built by ParseAST, as a Graphviz DOT
+// graph, for teaching and debugging how an expression parses:
+//
+//	root, err := ParseAST([]string{"2", "+", "3", "*", "x"})
+//	...
+//	os.WriteFile("tree.dot", []byte(ToDOT(root)), 0o644) // dot -Tsvg tree.dot
+//
+// Operators and calls are ellipses labelled with their symbol or name, with
+// operands drawn left to right in order below them, and numbers and
+// variables are boxes. The edges of a conditional are labelled "cond",
+// "then" and "else". A nil node is drawn as a box labelled "nil".
+func ToDOT(root Node) string {
+	var b strings.Builder
+	b.WriteString("digraph expression {\n\tordering=out;\n")
+
+	// write writes node n, numbered id, and its subtree, returning the next
+	// free number
+	var write func(n Node, id int) int
+	write = func(n Node, id int) int {
+		node := func(label, shape string) {
+			fmt.Fprintf(&b, "\tn%d [label=%s, shape=%s];\n", id, dotQuote(label), shape)
+		}
+		next := id + 1
+		edge := func(child Node, label string) {
+			if label == "" {
+				fmt.Fprintf(&b, "\tn%d -> n%d;\n", id, next)
+			} else {
+				fmt.Fprintf(&b, "\tn%d -> n%d [label=%s];\n", id, next, dotQuote(label))
+			}
+			next = write(child, next)
+		}
+
+		if isNilNode(n) {
+			node("nil", "box")
+			return next
+		}
+		switch n := n.(type) {
+		case *NumberNode:
+			node(n.Value, "box")
+		case *VariableNode:
+			node(n.Name, "box")
+		case *UnaryNode:
+			node(n.Operator, "ellipse")
+			edge(n.Operand, "")
+		case *BinaryNode:
+			node(n.Operator, "ellipse")
+			edge(n.Left, "")
+			edge(n.Right, "")
+		case *ConditionalNode:
+			node("?:", "ellipse")
+			edge(n.Condition, "cond")
+			edge(n.Then, "then")
+			edge(n.Else, "else")
+		case *CallNode:
+			node(n.Name+"()", "ellipse")
+			for _, arg := range n.Args {
+				edge(arg, "")
+			}
+		}
+		return next
+	}
+	write(root, 0)
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
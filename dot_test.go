@@ -0,0 +1,70 @@
+package shuntingyard
+
+import "testing"
+
+func TestToDOT(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Node
+		expected string
+	}{
+		{
+			name:     "leaf",
+			input:    &NumberNode{Value: "42"},
+			expected: "digraph expression {\n\tordering=out;\n\tn0 [label=\"42\", shape=box];\n}\n",
+		},
+		{
+			name:  "precedence",
+			input: mustParseAST(t, "2 + 3 * x"),
+			expected: "digraph expression {\n\tordering=out;\n" +
+				"\tn0 [label=\"+\", shape=ellipse];\n" +
+				"\tn0 -> n1;\n" +
+				"\tn1 [label=\"2\", shape=box];\n" +
+				"\tn0 -> n2;\n" +
+				"\tn2 [label=\"*\", shape=ellipse];\n" +
+				"\tn2 -> n3;\n" +
+				"\tn3 [label=\"3\", shape=box];\n" +
+				"\tn2 -> n4;\n" +
+				"\tn4 [label=\"x\", shape=box];\n" +
+				"}\n",
+		},
+		{
+			name:  "conditional and call",
+			input: mustParseAST(t, "c ? max(-a, 1) : 0"),
+			expected: "digraph expression {\n\tordering=out;\n" +
+				"\tn0 [label=\"?:\", shape=ellipse];\n" +
+				"\tn0 -> n1 [label=\"cond\"];\n" +
+				"\tn1 [label=\"c\", shape=box];\n" +
+				"\tn0 -> n2 [label=\"then\"];\n" +
+				"\tn2 [label=\"max()\", shape=ellipse];\n" +
+				"\tn2 -> n3;\n" +
+				"\tn3 [label=\"-\", shape=ellipse];\n" +
+				"\tn3 -> n4;\n" +
+				"\tn4 [label=\"a\", shape=box];\n" +
+				"\tn2 -> n5;\n" +
+				"\tn5 [label=\"1\", shape=box];\n" +
+				"\tn0 -> n6 [label=\"else\"];\n" +
+				"\tn6 [label=\"0\", shape=box];\n" +
+				"}\n",
+		},
+		{
+			name:  "nil operand and quoting",
+			input: &BinaryNode{Operator: `"\`, Left: &VariableNode{Name: "x"}},
+			expected: "digraph expression {\n\tordering=out;\n" +
+				"\tn0 [label=\"\\\"\\\\\", shape=ellipse];\n" +
+				"\tn0 -> n1;\n" +
+				"\tn1 [label=\"x\", shape=box];\n" +
+				"\tn0 -> n2;\n" +
+				"\tn2 [label=\"nil\", shape=box];\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ToDOT(tt.input); result != tt.expected {
+				t.Errorf("ToDOT() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrEmptyExpression is returned by Scan when the expression is empty or
+// contains only whitespace.
+var ErrEmptyExpression = errors.New("empty expression")
+
+// ErrUnterminatedComment is wrapped by the error Scan returns for a "/*"
+// comment that isn't closed by "*/".
+var ErrUnterminatedComment = errors.New("unterminated comment")
+
+// ErrLimitExceeded is wrapped by the errors of expressions that exceed a limit
+// set by WithMaxTokens, WithMaxOperators, WithMaxDepth or WithMaxSteps.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError is the error for an expression that exceeds a resource limit.
+// Limit names the limit, "token count", "operator count", "nesting depth" or
+// "evaluation steps", and Max is its value. Parse and Evaluate return it
+// wrapped in a *ParseError or *TokenError locating where the limit was passed.
+type LimitError struct {
+	Limit string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeds limit of %d", e.Limit, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// commentError is the error for a "/*" comment at the byte offset pos that
+// isn't closed, described in messages as where.
+type commentError struct {
+	pos   int
+	where string
+}
+
+func (e *commentError) Error() string {
+	return fmt.Sprintf("%v at %s", ErrUnterminatedComment, e.where)
+}
+
+func (e *commentError) Unwrap() error {
+	return ErrUnterminatedComment
+}
+
+// InvalidCharError is returned by Scan when the expression contains a character
+// that is not part of the grammar. Pos is the byte offset of the character,
+// and Line and Column its 1-based line and column, counted in characters.
+type InvalidCharError struct {
+	Rune   rune
+	Pos    int
+	Line   int
+	Column int
+
+	// multiline reports the position as line:column, for an expression
+	// spanning several lines
+	multiline bool
+	// redacted omits the character from the message (see WithRedactedErrors)
+	redacted bool
+}
+
+func (e *InvalidCharError) Error() string {
+	where := fmt.Sprintf("position %d", e.Pos)
+	if e.multiline {
+		where = fmt.Sprintf("%d:%d", e.Line, e.Column)
+	}
+	if e.redacted {
+		return "invalid character at " + where
+	}
+	return fmt.Sprintf("invalid character '%c' at %s", e.Rune, where)
+}
+
+// ParseError is returned by Parse when the tokens don't fit the grammar, as
+// for an unmatched ')' or a missing operand. Index is the offending infix
+// token, or len(tokens) for the end of input, and Pos its byte offset in the
+// source when Parse knows the spans of the tokens (see ParseSpans), otherwise
+// -1. The message is that of Err.
+type ParseError struct {
+	Index int
+	Pos   int
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// TokenError is returned by Evaluate when a failure can be attributed to a single
+// postfix token, such as the '/' of a division by zero. Index is the position of
+// that token in the postfix slice; combined with the spans from ParseSpans it
+// locates the failure in the original expression. The message is that of Err.
+type TokenError struct {
+	Index int
+	Err   error
+}
+
+func (e *TokenError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
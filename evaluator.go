@@ -0,0 +1,178 @@
+package shuntingyard
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrEvaluatorClosed is returned by Evaluator.Submit once Shutdown has been called.
+var ErrEvaluatorClosed = errors.New("evaluator is shut down")
+
+// EvaluatorConfig configures an Evaluator. Zero values select the defaults.
+type EvaluatorConfig struct {
+	// Workers is the number of goroutines evaluating jobs.
+	// Defaults to runtime.GOMAXPROCS(0).
+	Workers int
+
+	// QueueSize bounds the number of jobs waiting for a worker.
+	// Defaults to Workers.
+	QueueSize int
+
+	// Timeout limits how long a single job may take, measured from submission
+	// and including time spent in the queue. Zero means no limit.
+	Timeout time.Duration
+
+	// Options are passed to every stage of the pipeline for each job.
+	Options []Option
+}
+
+// Evaluator is a long-running service that evaluates infix expressions on a
+// fixed pool of workers fed by a bounded queue. It is safe for concurrent use.
+type Evaluator struct {
+	jobs    chan evaluatorJob
+	timeout time.Duration
+
+	// evalFunc evaluates a single expression, giving up once ctx is done;
+	// replaced in tests
+	evalFunc func(ctx context.Context, expression string) (float64, error)
+
+	mu     sync.RWMutex
+	closed bool
+
+	workers   sync.WaitGroup
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// evaluatorJob is a queued expression along with the channel its result is
+// delivered on. The channel is buffered so workers never block on a caller
+// that has already given up.
+type evaluatorJob struct {
+	ctx        context.Context
+	expression string
+	result     chan evaluatorResult
+}
+
+type evaluatorResult struct {
+	value float64
+	err   error
+}
+
+// NewEvaluator starts an Evaluator with the given configuration. Call Shutdown
+// to stop its workers.
+func NewEvaluator(cfg EvaluatorConfig) *Evaluator {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = workers
+	}
+
+	opts := append([]Option(nil), cfg.Options...)
+	e := &Evaluator{
+		jobs:    make(chan evaluatorJob, queueSize),
+		timeout: cfg.Timeout,
+		evalFunc: func(ctx context.Context, expression string) (float64, error) {
+			return eval(expression, append(opts[:len(opts):len(opts)], withContext(ctx))...)
+		},
+		done: make(chan struct{}),
+	}
+
+	e.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+
+	return e
+}
+
+// work evaluates queued jobs until the queue is closed and drained.
+func (e *Evaluator) work() {
+	defer e.workers.Done()
+
+	for job := range e.jobs {
+		// Skip jobs whose caller has gone away or whose timeout expired in the queue
+		if err := job.ctx.Err(); err != nil {
+			job.result <- evaluatorResult{err: err}
+			continue
+		}
+
+		// A job whose caller gives up while it runs stops, freeing the worker
+		value, err := e.evalFunc(job.ctx, job.expression)
+		job.result <- evaluatorResult{value: value, err: err}
+	}
+}
+
+// Submit queues an infix expression for evaluation and waits for its result.
+// If the queue is full, Submit blocks until space frees up. The wait for both
+// queueing and evaluation is bounded by ctx and by the configured Timeout,
+// and an evaluation still running when either ends stops as EvaluateContext
+// does, freeing its worker.
+//
+// Returns the result, the evaluation error, the context error if ctx is done or
+// the job timed out, or ErrEvaluatorClosed after Shutdown.
+func (e *Evaluator) Submit(ctx context.Context, expression string) (float64, error) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	job := evaluatorJob{
+		ctx:        ctx,
+		expression: expression,
+		result:     make(chan evaluatorResult, 1),
+	}
+
+	// Hold the read lock while enqueueing so Shutdown can't close the queue
+	// underneath us
+	e.mu.RLock()
+	if e.closed {
+		e.mu.RUnlock()
+		return 0, ErrEvaluatorClosed
+	}
+	select {
+	case e.jobs <- job:
+	case <-ctx.Done():
+		e.mu.RUnlock()
+		return 0, ctx.Err()
+	}
+	e.mu.RUnlock()
+
+	select {
+	case r := <-job.result:
+		return r.value, r.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Shutdown stops accepting new jobs, lets the workers finish every job that was
+// already queued, and waits for them to exit. If ctx is done first, Shutdown
+// returns the context error while the workers keep draining in the background.
+// It is safe to call Shutdown more than once.
+func (e *Evaluator) Shutdown(ctx context.Context) error {
+	e.closeOnce.Do(func() {
+		go func() {
+			e.mu.Lock()
+			e.closed = true
+			close(e.jobs)
+			e.mu.Unlock()
+
+			e.workers.Wait()
+			close(e.done)
+		}()
+	})
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
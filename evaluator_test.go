@@ -0,0 +1,175 @@
+package shuntingyard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestEvaluatorSubmit tests concurrent evaluation through the worker pool
+func TestEvaluatorSubmit(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{expression: "2 + 3", expected: 5.0},
+		{expression: "2 + 3 * 4", expected: 14.0},
+		{expression: "(2 + 3) * (4 + 1)", expected: 25.0},
+		{expression: "10.5 / 2 + 3.5", expected: 8.75},
+		{expression: "10 / 0", wantErr: true},
+		{expression: "2 + a", wantErr: true},
+	}
+
+	e := NewEvaluator(EvaluatorConfig{Workers: 3, QueueSize: 2})
+	defer e.Shutdown(context.Background())
+
+	// Submit every expression many times at once to exercise the bounded queue
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, tt := range tests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result, err := e.Submit(context.Background(), tt.expression)
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("Submit(%q) expected error, got %v", tt.expression, result)
+					}
+					return
+				}
+				if err != nil {
+					t.Errorf("Submit(%q) unexpected error: %v", tt.expression, err)
+					return
+				}
+				if !almostEqual(result, tt.expected, 0.0000001) {
+					t.Errorf("Submit(%q) = %v, expected %v", tt.expression, result, tt.expected)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// TestEvaluatorTimeout tests that a job exceeding the configured timeout is abandoned
+func TestEvaluatorTimeout(t *testing.T) {
+	e := NewEvaluator(EvaluatorConfig{Workers: 1, Timeout: 10 * time.Millisecond})
+	defer e.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	e.evalFunc = func(context.Context, string) (float64, error) {
+		<-release
+		return 0, nil
+	}
+
+	_, err := e.Submit(context.Background(), "1 + 1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit() error = %v, expected context.DeadlineExceeded", err)
+	}
+}
+
+// TestEvaluatorCancelled tests that a cancelled context is reported without evaluating
+func TestEvaluatorCancelled(t *testing.T) {
+	e := NewEvaluator(EvaluatorConfig{Workers: 1})
+	defer e.Shutdown(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := e.Submit(ctx, "1 + 1")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Submit() error = %v, expected context.Canceled", err)
+	}
+}
+
+// TestEvaluatorCancelledWhileRunning tests that a job whose caller gives up
+// stops evaluating, freeing its worker
+func TestEvaluatorCancelledWhileRunning(t *testing.T) {
+	var calls atomic.Int64
+	slow := WithFunction("slow", 1, func(args ...float64) (float64, error) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond)
+		return args[0], nil
+	})
+	e := NewEvaluator(EvaluatorConfig{Workers: 1, Options: []Option{slow}})
+	defer e.Shutdown(context.Background())
+
+	const terms = 1000
+	expression := "slow(1)" + strings.Repeat(" + slow(1)", terms-1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.Submit(ctx, expression); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit() error = %v, expected context.DeadlineExceeded", err)
+	}
+
+	// The next job runs once the worker has given up on the first
+	if result, err := e.Submit(context.Background(), "1 + 1"); err != nil || result != 2 {
+		t.Fatalf("Submit() = %v, %v, expected 2", result, err)
+	}
+	if n := calls.Load(); n >= terms {
+		t.Errorf("cancelled job made all %d calls", n)
+	}
+}
+
+// TestEvaluatorShutdown tests that queued jobs finish and new jobs are rejected
+func TestEvaluatorShutdown(t *testing.T) {
+	e := NewEvaluator(EvaluatorConfig{Workers: 1, QueueSize: 4})
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	e.evalFunc = func(_ context.Context, expression string) (float64, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return eval(expression)
+	}
+
+	// Occupy the worker, then queue more jobs behind it
+	results := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := e.Submit(context.Background(), "2 * 3")
+			results <- err
+		}()
+	}
+	<-started
+
+	// Wait until every job has been queued before shutting down
+	for len(e.jobs) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- e.Shutdown(context.Background())
+	}()
+
+	// Shutdown must not complete while queued jobs are still running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := e.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() with pending jobs = %v, expected context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("queued Submit() unexpected error: %v", err)
+		}
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() unexpected error: %v", err)
+	}
+
+	if _, err := e.Submit(context.Background(), "1 + 1"); !errors.Is(err, ErrEvaluatorClosed) {
+		t.Errorf("Submit() after Shutdown() error = %v, expected ErrEvaluatorClosed", err)
+	}
+}
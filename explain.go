@@ -0,0 +1,289 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// operatorNames are the spoken names of the binary operators used by Explain.
+var operatorNames = map[string]string{
+	"+": "addition",
+	"-": "subtraction",
+	"*": "multiplication",
+	"/": "division",
+	"%": "modulo",
+	"^": "exponentiation",
+
+	"<":  "less-than comparison",
+	">":  "greater-than comparison",
+	"<=": "less-or-equal comparison",
+	">=": "greater-or-equal comparison",
+	"==": "equality comparison",
+	"!=": "inequality comparison",
+
+	"&&": "logical AND",
+	"||": "logical OR",
+
+	"&":   "bitwise AND",
+	"|":   "bitwise OR",
+	"xor": "bitwise XOR",
+	"<<":  "left shift",
+	">>":  "right shift",
+}
+
+// unaryNames are the spoken names of the unary operators, by postfix token.
+var unaryNames = map[string]string{
+	negate: "negation",
+	"!":    "logical NOT",
+	"~":    "bitwise NOT",
+}
+
+// Explain evaluates an infix expression and describes, step by step, the order
+// in which its operations happen and why, for display next to the result:
+//
+//  1. multiplication 3 * 4 = 12 happens before the addition because multiplication has higher precedence
+//  2. addition 2 + 12 = 14
+//     Result: 14
+//
+// Each step names the operation with its operand values and gives the rule that
+// put it at that point: higher precedence, grouping by parentheses, or the
+// associativity of operators with the same precedence.
+//
+// Functions registered with WithFunction are called again for each step they
+// appear in, after the evaluation that produces the result.
+//
+// Returns the explanation or the Scan, Parse, or Evaluate error.
+func Explain(expression string, opts ...Option) (string, error) {
+	cfg := newConfig(opts)
+	cfg.source = expression
+
+	tokens, spans, err := scan(expression, cfg, true)
+	if err != nil {
+		return "", err
+	}
+	postfix, postfixSpans, err := parse(tokens, spans, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	// Evaluate up front so invalid postfix and runtime errors are reported
+	// exactly as Evaluate reports them
+	result, err := Evaluate(postfix, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	x := explanation{
+		cfg:        cfg,
+		expression: expression,
+		tokens:     tokens,
+		spans:      spans,
+		group:      make([]int, len(tokens)),
+		closing:    make(map[int]int),
+		pending:    make(map[int]bool),
+	}
+
+	// For every infix token, record the innermost open parenthesis enclosing
+	// it (-1 at top level) and where each parenthesized group closes
+	var open []int
+	for i, token := range tokens {
+		if token == ")" {
+			x.closing[open[len(open)-1]] = i
+			open = open[:len(open)-1]
+		}
+		x.group[i] = -1
+		if len(open) > 0 {
+			x.group[i] = open[len(open)-1]
+		}
+		if token == "(" {
+			open = append(open, i)
+		}
+	}
+
+	// Spans start at unique offsets, which maps postfix tokens back to infix tokens
+	infixIndex := make(map[int]int, len(spans))
+	for i, span := range spans {
+		infixIndex[span.Start] = i
+	}
+
+	// Only binary operators compete by precedence; signs are taken from the
+	// postfix output since Parse alone tells them apart from '+' and '-'
+	for i, token := range postfix {
+		if _, ok := cfg.operatorName(token); ok {
+			x.pending[infixIndex[postfixSpans[i].Start]] = true
+		}
+	}
+
+	var b strings.Builder
+	var stack []float64
+	step := 0
+
+	for i, token := range postfix {
+		if isUnaryOperator(token) {
+			operand := stack[len(stack)-1]
+			value, _ := applyUnary(token, operand)
+			stack[len(stack)-1] = value
+
+			// Show the operator as written rather than its postfix form
+			symbol := tokens[infixIndex[postfixSpans[i].Start]]
+
+			step++
+			fmt.Fprintf(&b, "%d. %s %s(%s) = %s\n", step, unaryNames[token], symbol, formatValue(operand), formatValue(value))
+			continue
+		}
+
+		if cfg.isCall(token) {
+			name, _, _ := splitCall(token)
+			fn, argc, _ := cfg.checkCall(token, len(stack))
+			args := stack[len(stack)-argc:]
+			value, _ := fn.invoke(token, args)
+
+			formatted := make([]string, argc)
+			for j, arg := range args {
+				formatted[j] = formatValue(arg)
+			}
+			stack = append(stack[:len(stack)-argc], value)
+
+			step++
+			fmt.Fprintf(&b, "%d. function %s(%s) = %s\n", step, name, strings.Join(formatted, ", "), formatValue(value))
+			continue
+		}
+
+		if token == ternary {
+			cond, ifTrue, ifFalse := stack[len(stack)-3], stack[len(stack)-2], stack[len(stack)-1]
+			value := applyTernary(cond, ifTrue, ifFalse)
+			stack = stack[:len(stack)-2]
+			stack[len(stack)-1] = value
+
+			step++
+			fmt.Fprintf(&b, "%d. conditional %s ? %s : %s = %s\n", step,
+				formatValue(cond), formatValue(ifTrue), formatValue(ifFalse), formatValue(value))
+			continue
+		}
+
+		name, isOperator := cfg.operatorName(token)
+		if !isOperator {
+			value, err := strconv.ParseFloat(token, 64)
+			if err != nil {
+				value, _ = cfg.variable(token, i)
+			}
+			stack = append(stack, value)
+			continue
+		}
+
+		right := stack[len(stack)-1]
+		left := stack[len(stack)-2]
+		stack = stack[:len(stack)-2]
+		var value float64
+		if custom, ok := cfg.operators[token]; ok {
+			value, _ = custom.fn.call(left, right)
+		} else {
+			value, _ = applyBinary(token, left, right)
+		}
+
+		position := infixIndex[postfixSpans[i].Start]
+		delete(x.pending, position)
+
+		step++
+		fmt.Fprintf(&b, "%d. %s %s %s %s = %s", step, name,
+			formatValue(left), token, formatValue(right), formatValue(value))
+		if reason := x.reason(position); reason != "" {
+			b.WriteString(" " + reason)
+		}
+		b.WriteByte('\n')
+
+		stack = append(stack, value)
+	}
+
+	fmt.Fprintf(&b, "Result: %s", formatValue(result))
+	return b.String(), nil
+}
+
+// explanation is the state Explain consults for the reason behind each step.
+type explanation struct {
+	cfg        config
+	expression string
+	tokens     []string
+	spans      []Span
+
+	// group is the index of the innermost '(' enclosing each token, or -1
+	group []int
+	// closing maps the index of each '(' to the index of its ')'
+	closing map[int]int
+	// pending holds the infix indices of operators not yet applied
+	pending map[int]bool
+}
+
+// reason gives the rule that makes the operator at infix index position happen
+// before the operators still pending, or "" if it is simply next.
+func (x *explanation) reason(position int) string {
+	token := x.tokens[position]
+	name, _ := x.cfg.operatorName(token)
+
+	// A pending operator in the same group with lower precedence; scan in
+	// source order so explanations refer to the leftmost match
+	for _, i := range slices.Sorted(maps.Keys(x.pending)) {
+		if x.group[i] == x.group[position] && x.cfg.binding(x.tokens[i]).precedence < x.cfg.binding(token).precedence {
+			other, _ := x.cfg.operatorName(x.tokens[i])
+			return fmt.Sprintf("happens before the %s because %s has higher precedence", other, name)
+		}
+	}
+
+	// The last operation inside parentheses while operators outside are pending
+	if open := x.group[position]; open >= 0 {
+		call := x.cfg.opensCall(x.tokens, open)
+		finished := true
+		outside := false
+		for i := range x.pending {
+			if x.group[i] == open {
+				finished = false
+			}
+			if i < open || i > x.closing[open] {
+				outside = true
+			}
+		}
+		if finished && outside && call {
+			return fmt.Sprintf("happens first because it is an argument of %s", x.tokens[open-1])
+		}
+		if finished && outside {
+			inner := strings.TrimSpace(x.expression[x.spans[open].End:x.spans[x.closing[open]].Start])
+			return fmt.Sprintf("happens first because the parentheses group (%s)", inner)
+		}
+	}
+
+	// A pending operator in the same group with equal precedence, to the
+	// right for left-associative operators and to the left for right-associative ones
+	bound := x.cfg.binding(token)
+	for _, i := range slices.Sorted(maps.Keys(x.pending)) {
+		if x.group[i] != x.group[position] || x.cfg.binding(x.tokens[i]).precedence != bound.precedence {
+			continue
+		}
+		other, _ := x.cfg.operatorName(x.tokens[i])
+		if bound.assoc == RightAssociative && i < position {
+			return fmt.Sprintf("happens before the %s on its left because %s is evaluated right to left", other, name)
+		}
+		if bound.assoc == LeftAssociative && i > position {
+			return fmt.Sprintf("happens before the %s because operators with the same precedence are evaluated left to right", other)
+		}
+	}
+
+	return ""
+}
+
+// operatorName returns the spoken name of a binary operator, such as
+// "addition", or "'//' operation" for a registered one.
+func (c config) operatorName(token string) (string, bool) {
+	if _, ok := c.operators[token]; ok {
+		return fmt.Sprintf("'%s' operation", token), true
+	}
+	name, ok := operatorNames[token]
+	return name, ok
+}
+
+// formatValue renders a number in its shortest exact form.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
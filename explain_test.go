@@ -0,0 +1,165 @@
+package shuntingyard
+
+import "testing"
+
+// TestExplain tests the step-by-step description of evaluation order
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:       "single literal",
+			expression: "42",
+			expected:   "Result: 42",
+		},
+		{
+			name:       "single operation",
+			expression: "2 + 3",
+			expected: "1. addition 2 + 3 = 5\n" +
+				"Result: 5",
+		},
+		{
+			name:       "precedence",
+			expression: "2 + 3 * 4",
+			expected: "1. multiplication 3 * 4 = 12 happens before the addition because multiplication has higher precedence\n" +
+				"2. addition 2 + 12 = 14\n" +
+				"Result: 14",
+		},
+		{
+			name:       "parentheses",
+			expression: "(2 + 3) * 4",
+			expected: "1. addition 2 + 3 = 5 happens first because the parentheses group (2 + 3)\n" +
+				"2. multiplication 5 * 4 = 20\n" +
+				"Result: 20",
+		},
+		{
+			name:       "left to right",
+			expression: "8 - 4 - 2",
+			expected: "1. subtraction 8 - 4 = 4 happens before the subtraction because operators with the same precedence are evaluated left to right\n" +
+				"2. subtraction 4 - 2 = 2\n" +
+				"Result: 2",
+		},
+		{
+			name:       "nested parentheses",
+			expression: "((2+3)*4)-5",
+			expected: "1. addition 2 + 3 = 5 happens first because the parentheses group (2+3)\n" +
+				"2. multiplication 5 * 4 = 20 happens first because the parentheses group ((2+3)*4)\n" +
+				"3. subtraction 20 - 5 = 15\n" +
+				"Result: 15",
+		},
+		{
+			name:       "precedence inside parentheses",
+			expression: "(1 + 2 * 3) / 7",
+			expected: "1. multiplication 2 * 3 = 6 happens before the addition because multiplication has higher precedence\n" +
+				"2. addition 1 + 6 = 7 happens first because the parentheses group (1 + 2 * 3)\n" +
+				"3. division 7 / 7 = 1\n" +
+				"Result: 1",
+		},
+		{
+			name:       "right associativity",
+			expression: "2 ^ 3 ^ 2",
+			expected: "1. exponentiation 3 ^ 2 = 9 happens before the exponentiation on its left because exponentiation is evaluated right to left\n" +
+				"2. exponentiation 2 ^ 9 = 512\n" +
+				"Result: 512",
+		},
+		{
+			name:       "negation",
+			expression: "-(2 + 3) * 4",
+			expected: "1. addition 2 + 3 = 5 happens first because the parentheses group (2 + 3)\n" +
+				"2. negation -(5) = -5\n" +
+				"3. multiplication -5 * 4 = -20\n" +
+				"Result: -20",
+		},
+		{
+			name:       "signs are not binary operators",
+			expression: "2 * -3 + 1",
+			expected: "1. negation -(3) = -3\n" +
+				"2. multiplication 2 * -3 = -6 happens before the addition because multiplication has higher precedence\n" +
+				"3. addition -6 + 1 = -5\n" +
+				"Result: -5",
+		},
+		{
+			name:       "comparison",
+			expression: "2 * 3 > 5",
+			expected: "1. multiplication 2 * 3 = 6 happens before the greater-than comparison because multiplication has higher precedence\n" +
+				"2. greater-than comparison 6 > 5 = 1\n" +
+				"Result: 1",
+		},
+		{
+			name:       "logical operators",
+			expression: "!0 || 1 && 0",
+			expected: "1. logical NOT !(0) = 1\n" +
+				"2. logical AND 1 && 0 = 0 happens before the logical OR because logical AND has higher precedence\n" +
+				"3. logical OR 1 || 0 = 1\n" +
+				"Result: 1",
+		},
+		{
+			name:       "conditional",
+			expression: "2 > 1 ? 3 : 4",
+			expected: "1. greater-than comparison 2 > 1 = 1\n" +
+				"2. conditional 1 ? 3 : 4 = 3\n" +
+				"Result: 3",
+		},
+		{
+			name:       "bitwise operators",
+			expression: "~0 & 6 xor 3",
+			expected: "1. bitwise NOT ~(0) = -1\n" +
+				"2. bitwise AND -1 & 6 = 6 happens before the bitwise XOR because bitwise AND has higher precedence\n" +
+				"3. bitwise XOR 6 xor 3 = 5\n" +
+				"Result: 5",
+		},
+		{
+			name:       "shift",
+			expression: "1 << 2 + 1",
+			expected: "1. addition 2 + 1 = 3 happens before the left shift because addition has higher precedence\n" +
+				"2. left shift 1 << 3 = 8\n" +
+				"Result: 8",
+		},
+		{
+			name:       "function call",
+			expression: "sqrt(9 + 16) * 2",
+			expected: "1. addition 9 + 16 = 25 happens first because it is an argument of sqrt\n" +
+				"2. function sqrt(25) = 5\n" +
+				"3. multiplication 5 * 2 = 10\n" +
+				"Result: 10",
+		},
+		{
+			name:       "function with several arguments",
+			expression: "max(1 + 2, 4) - 1",
+			expected: "1. addition 1 + 2 = 3 happens first because it is an argument of max\n" +
+				"2. function max(3, 4) = 4\n" +
+				"3. subtraction 4 - 1 = 3\n" +
+				"Result: 3",
+		},
+
+		// Error cases
+		{name: "division by zero", expression: "1 / 0", wantErr: true},
+		{name: "invalid character", expression: "2 + a", wantErr: true},
+		{name: "mismatched parens", expression: "(2 + 3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Explain(tt.expression)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Explain() expected error, got %q", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Explain() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("Explain(%q) =\n%s\nexpected\n%s", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package shuntingyard
+
+import "strconv"
+
+// evalTrivial evaluates the two most common expression shapes, a single literal
+// ("42") and a single binary operation on two literals ("6 * 7"), directly from
+// infix tokens without building postfix output or an evaluation stack.
+//
+// It reports false for any other shape and for inputs that would fail, leaving
+// the full pipeline to produce the result or its usual error.
+func evalTrivial(tokens []string) (float64, bool) {
+	switch len(tokens) {
+	case 1:
+		value, err := strconv.ParseFloat(tokens[0], 64)
+		if err != nil {
+			return 0, false
+		}
+		return value, true
+
+	case 3:
+		if !isBinaryOperator(tokens[1]) {
+			return 0, false
+		}
+
+		a, err := strconv.ParseFloat(tokens[0], 64)
+		if err != nil {
+			return 0, false
+		}
+		b, err := strconv.ParseFloat(tokens[2], 64)
+		if err != nil {
+			return 0, false
+		}
+
+		result, err := applyBinary(tokens[1], a, b)
+		if err != nil {
+			return 0, false
+		}
+		return result, true
+	}
+
+	return 0, false
+}
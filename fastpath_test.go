@@ -0,0 +1,84 @@
+package shuntingyard
+
+import "testing"
+
+// TestEvalTrivial tests which token shapes take the fast path
+func TestEvalTrivial(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   []string
+		expected float64
+		ok       bool
+	}{
+		{name: "single literal", tokens: []string{"42"}, expected: 42, ok: true},
+		{name: "single float literal", tokens: []string{"2.5"}, expected: 2.5, ok: true},
+		{name: "addition", tokens: []string{"2", "+", "3"}, expected: 5, ok: true},
+		{name: "subtraction", tokens: []string{"5", "-", "3"}, expected: 2, ok: true},
+		{name: "multiplication", tokens: []string{"6", "*", "7"}, expected: 42, ok: true},
+		{name: "division", tokens: []string{"10", "/", "4"}, expected: 2.5, ok: true},
+
+		// Shapes left to the full pipeline
+		{name: "lone operator", tokens: []string{"+"}},
+		{name: "parenthesized literal", tokens: []string{"(", "2", ")"}},
+		{name: "division by zero", tokens: []string{"10", "/", "0"}},
+		{name: "invalid literal", tokens: []string{"1.2.3", "+", "1"}},
+		{name: "two operations", tokens: []string{"1", "+", "2", "*", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := evalTrivial(tt.tokens)
+			if ok != tt.ok {
+				t.Fatalf("evalTrivial(%v) ok = %v, expected %v", tt.tokens, ok, tt.ok)
+			}
+			if ok && result != tt.expected {
+				t.Errorf("evalTrivial(%v) = %v, expected %v", tt.tokens, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvalTrivialMatchesPipeline tests that the fast path agrees with Parse and Evaluate
+func TestEvalTrivialMatchesPipeline(t *testing.T) {
+	expressions := []string{"42", "0.1", "2 + 3", "0.1 + 0.2", "10 - 6", "1.5 * 4", "10 / 3", "10 / 0", "+ 3", "(7)"}
+
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			tokens, err := Scan(expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+
+			var expected float64
+			postfix, err := Parse(tokens)
+			if err == nil {
+				expected, err = Evaluate(postfix)
+			}
+
+			result, evalErr := eval(expression)
+			if (err != nil) != (evalErr != nil) {
+				t.Fatalf("eval() error = %v, pipeline error = %v", evalErr, err)
+			}
+			if err != nil {
+				if evalErr.Error() != err.Error() {
+					t.Errorf("eval() error = %q, pipeline error = %q", evalErr, err)
+				}
+				return
+			}
+			if result != expected {
+				t.Errorf("eval() = %v, pipeline = %v", result, expected)
+			}
+		})
+	}
+}
+
+// BenchmarkEvalTrivial benchmarks the fast path for a single binary operation
+func BenchmarkEvalTrivial(b *testing.B) {
+	expression := "6 * 7"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = eval(expression)
+	}
+}
@@ -0,0 +1,117 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+)
+
+// WithFinanceFunctions makes a pack of financial functions available to
+// expressions, following spreadsheet conventions so that analysts' formulas
+// carry over unchanged. Rates are per period, and money paid out is negative:
+//
+//   - npv(rate, cf...): the net present value of cash flows at the end of
+//     periods 1, 2, ...
+//   - irr(cf...): the internal rate of return of cash flows at periods 0, 1, ...
+//   - pmt(rate, nper, pv): the payment per period that repays pv over nper periods
+//   - fv(rate, nper, pmt): the future value of nper payments of pmt
+//   - pv(rate, nper, pmt): the present value of nper payments of pmt
+//   - compound(principal, rate, periods): principal compounded over periods
+//
+// npv rejects rates of -100% or less, pmt a zero number of periods, and irr
+// cash flows without both signs or whose rate can't be found.
+func WithFinanceFunctions() Option {
+	return withFunctions(financeFunctions)
+}
+
+// financeFunctions is the pack registered by WithFinanceFunctions.
+var financeFunctions = map[string]function{
+	"npv":      {arity: 2, variadic: true, call: npv},
+	"irr":      {arity: 2, variadic: true, call: irr},
+	"pmt":      {arity: 3, call: pmt},
+	"fv":       {arity: 3, call: fv},
+	"pv":       {arity: 3, call: pv},
+	"compound": {arity: 3, call: compound},
+}
+
+func npv(args ...float64) (float64, error) {
+	rate := args[0]
+	if rate <= -1 {
+		return 0, errors.New("rate must be greater than -1")
+	}
+	return discount(rate, args[1:], 1), nil
+}
+
+// discount sums cash flows discounted at rate, the first at period first.
+func discount(rate float64, flows []float64, first int) float64 {
+	total := 0.0
+	for i, flow := range flows {
+		total += flow / math.Pow(1+rate, float64(first+i))
+	}
+	return total
+}
+
+func irr(flows ...float64) (float64, error) {
+	positive, negative := false, false
+	for _, flow := range flows {
+		positive = positive || flow > 0
+		negative = negative || flow < 0
+	}
+	if !positive || !negative {
+		return 0, errors.New("cash flows must include both positive and negative values")
+	}
+
+	// Newton's method from the customary 10% guess
+	rate := 0.1
+	for range 100 {
+		value, slope := 0.0, 0.0
+		for i, flow := range flows {
+			value += flow / math.Pow(1+rate, float64(i))
+			slope -= float64(i) * flow / math.Pow(1+rate, float64(i+1))
+		}
+		if slope == 0 {
+			break
+		}
+		next := rate - value/slope
+		if next <= -1 || math.IsNaN(next) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-12 {
+			return next, nil
+		}
+		rate = next
+	}
+	return 0, errors.New("internal rate of return did not converge")
+}
+
+func pmt(args ...float64) (float64, error) {
+	rate, nper, present := args[0], args[1], args[2]
+	if nper == 0 {
+		return 0, errors.New("number of periods must not be zero")
+	}
+	if rate == 0 {
+		return -present / nper, nil
+	}
+	growth := math.Pow(1+rate, nper)
+	return -present * growth * rate / (growth - 1), nil
+}
+
+func fv(args ...float64) (float64, error) {
+	rate, nper, payment := args[0], args[1], args[2]
+	if rate == 0 {
+		return -payment * nper, nil
+	}
+	return -payment * (math.Pow(1+rate, nper) - 1) / rate, nil
+}
+
+func pv(args ...float64) (float64, error) {
+	rate, nper, payment := args[0], args[1], args[2]
+	if rate == 0 {
+		return -payment * nper, nil
+	}
+	return -payment * (1 - math.Pow(1+rate, -nper)) / rate, nil
+}
+
+func compound(args ...float64) (float64, error) {
+	principal, rate, periods := args[0], args[1], args[2]
+	return principal * math.Pow(1+rate, periods), nil
+}
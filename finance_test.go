@@ -0,0 +1,55 @@
+package shuntingyard
+
+import "testing"
+
+// TestFinanceFunctions tests the financial function pack through the full pipeline
+func TestFinanceFunctions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		// Reference values agree with the spreadsheet functions of the same name
+		{name: "net present value", expression: "npv(0.1, -10000, 3000, 4200, 6800)", expected: 1188.4434123352207},
+		{name: "net present value at zero rate", expression: "npv(0, 100, 200)", expected: 300},
+		{name: "internal rate of return", expression: "irr(-70000, 12000, 15000, 18000, 21000, 26000)", expected: 0.08663094803653162},
+		{name: "internal rate of return of one period", expression: "irr(-100, 110)", expected: 0.1},
+		{name: "loan payment", expression: "pmt(0.08 / 12, 10, 10000)", expected: -1037.0320893591606},
+		{name: "payment at zero rate", expression: "pmt(0, 4, 1000)", expected: -250},
+		{name: "future value", expression: "fv(0.06 / 12, 10, -200)", expected: 2045.6052816315662},
+		{name: "future value at zero rate", expression: "fv(0, 12, -100)", expected: 1200},
+		{name: "present value", expression: "pv(0.08 / 12, 240, 500)", expected: -59777.14585118638},
+		{name: "present value at zero rate", expression: "pv(0, 10, 50)", expected: -500},
+		{name: "compound growth", expression: "compound(1000, 0.05, 10)", expected: 1628.894626777442},
+		{name: "payment repays present value", expression: "pv(0.01, 36, pmt(0.01, 36, 5000))", expected: 5000},
+
+		// Error cases
+		{name: "net present value at -100%", expression: "npv(-1, 100)", wantErr: true},
+		{name: "internal rate of return without outflow", expression: "irr(100, 200)", wantErr: true},
+		{name: "payment over zero periods", expression: "pmt(0.05, 0, 1000)", wantErr: true},
+		{name: "payment with missing argument", expression: "pmt(0.05, 12)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithFinanceFunctions())
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
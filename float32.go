@@ -0,0 +1,85 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// EvaluateFloat32 computes the result of a postfix (RPN) expression in single
+// precision. Literals are rounded to float32 when parsed and every intermediate
+// result is rounded to float32, so the result matches targets that compute in
+// single precision (GPUs, embedded FPUs) rather than float64 rounded at the end.
+//
+// Returns the computed float32 result or an error for invalid expressions, division by zero,
+// modulo by zero, or non-integer bitwise operands.
+func EvaluateFloat32(postfixTokens []string, opts ...Option) (float32, error) {
+	return evaluateWith(postfixTokens, Arithmetic[float32](float32Arithmetic{}), newConfig(opts))
+}
+
+// float32Arithmetic implements EvaluateFloat32's single-precision arithmetic.
+// Explicit conversions keep the compiler from fusing operations at a higher
+// precision.
+type float32Arithmetic struct{}
+
+func (float32Arithmetic) ParseLiteral(token string) (float32, error) {
+	value, err := strconv.ParseFloat(token, 32)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return float32(value), nil
+}
+
+func (float32Arithmetic) Add(a, b float32) (float32, error) { return float32(a + b), nil }
+func (float32Arithmetic) Sub(a, b float32) (float32, error) { return float32(a - b), nil }
+func (float32Arithmetic) Mul(a, b float32) (float32, error) { return float32(a * b), nil }
+
+func (float32Arithmetic) Div(a, b float32) (float32, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	return float32(a / b), nil
+}
+
+func (float32Arithmetic) Binary(operator string, a, b float32) (float32, error) {
+	switch operator {
+	case "%":
+		if b == 0 {
+			return 0, fmt.Errorf("modulo by zero")
+		}
+		// The remainder is exact, so computing it in float64 loses nothing
+		return float32(math.Mod(float64(a), float64(b))), nil
+	case "^":
+		// There is no single-precision Pow; rounding the float64 result gives
+		// the nearest float32 in all but rare ties
+		return float32(math.Pow(float64(a), float64(b))), nil
+	}
+	// Widening to float64 is exact, so comparisons are unchanged, as are
+	// bitwise results that fit in single precision
+	value, err := applyBinary(operator, float64(a), float64(b))
+	return float32(value), err
+}
+
+func (float32Arithmetic) Unary(operator string, a float32) (float32, error) {
+	value, err := applyUnary(operator, float64(a))
+	return float32(value), err
+}
+
+func (float32Arithmetic) Truth(a float32) bool { return a != 0 }
+
+func (float32Arithmetic) Variable(_ string, value float64) (float32, error) {
+	return float32(value), nil
+}
+
+func (float32Arithmetic) Round(a float32, scale int, mode RoundingMode) (float32, error) {
+	return float32(roundFloat(float64(a), scale, mode, 32)), nil
+}
+
+func (float32Arithmetic) invoke(fn function, token string, args []float32) (float32, error) {
+	wide := make([]float64, len(args))
+	for i, arg := range args {
+		wide[i] = float64(arg)
+	}
+	value, err := fn.invoke(token, wide)
+	return float32(value), err
+}
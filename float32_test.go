@@ -0,0 +1,145 @@
+package shuntingyard
+
+import (
+	"math"
+	"testing"
+)
+
+// TestEvaluateFloat32 tests single-precision evaluation of postfix expressions
+func TestEvaluateFloat32(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected float32
+		wantErr  bool
+	}{
+		{
+			name:     "simple addition",
+			input:    []string{"2", "3", "+"},
+			expected: 5,
+		},
+		{
+			name:     "complex expression",
+			input:    []string{"2", "3", "4", "*", "+"},
+			expected: 14,
+		},
+		{
+			name:     "literal rounded to single precision",
+			input:    []string{"0.1", "1", "*"},
+			expected: float32(0.1),
+		},
+		{
+			name:     "precision loss beyond 24 bits",
+			input:    []string{"16777216", "1", "+"},
+			expected: 16777216,
+		},
+		{
+			name:     "repeating decimal",
+			input:    []string{"10", "3", "/"},
+			expected: float32(10) / float32(3),
+		},
+		{
+			name:     "exponentiation",
+			input:    []string{"2", "10", "^"},
+			expected: 1024,
+		},
+		{
+			name:     "modulo",
+			input:    []string{"5.5", "2", "%"},
+			expected: 1.5,
+		},
+		{
+			name:     "negation",
+			input:    []string{"2", "neg", "3", "*"},
+			expected: -6,
+		},
+		{
+			name:     "comparison in single precision",
+			input:    []string{"0.1", "0.2", "+", "0.3", "=="},
+			expected: 1,
+		},
+		{
+			name:     "logical operators",
+			input:    []string{"0", "!", "0", "&&"},
+			expected: 0,
+		},
+		{
+			name:     "conditional",
+			input:    []string{"0", "1", "2", "?:"},
+			expected: 2,
+		},
+		{
+			name:     "bitwise operators",
+			input:    []string{"12", "~", "255", "&"},
+			expected: 243,
+		},
+		{
+			name:     "shift",
+			input:    []string{"1", "10", "<<", "2", ">>"},
+			expected: 256,
+		},
+		{
+			name:    "bitwise operator on fraction",
+			input:   []string{"0.5", "1", "|"},
+			wantErr: true,
+		},
+		{
+			name:     "function",
+			input:    []string{"2", "sqrt/1"},
+			expected: float32(math.Sqrt2),
+		},
+		{
+			name:    "modulo by zero",
+			input:   []string{"5", "0", "%"},
+			wantErr: true,
+		},
+		{
+			name:    "division by zero",
+			input:   []string{"10", "0", "/"},
+			wantErr: true,
+		},
+		{
+			name:    "insufficient operands",
+			input:   []string{"2", "+"},
+			wantErr: true,
+		},
+		{
+			name:    "too many operands",
+			input:   []string{"2", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "empty expression",
+			input:   []string{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			input:   []string{"abc", "2", "+"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvaluateFloat32(tt.input)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateFloat32() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateFloat32() unexpected error: %v", err)
+				return
+			}
+
+			// Results must match single-precision arithmetic bit for bit
+			if result != tt.expected {
+				t.Errorf("EvaluateFloat32() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package shuntingyard
+
+import "strconv"
+
+// WithoutConstantFolding makes Compile keep subexpressions of constant
+// numbers as written instead of computing them once at compile time, so that
+// the compiled program follows the source operation for operation, as when
+// debugging an arithmetic or a registered function.
+func WithoutConstantFolding() Option {
+	return func(c *config) {
+		c.noFolding = true
+	}
+}
+
+// foldConstants computes the subexpressions of a postfix expression whose operands are
+// all numbers, for Compile: "x 60 60 * 24 * *" becomes "x 86400 *". A
+// conditional or a "&&" or "||" whose constant operand decides it is replaced
+// by its result, or the operand it chooses. origin[i] is the index in
+// postfix of the token the folded token i stands for, so that errors locate
+// the source token. A subexpression that fails, such as "1 / 0", is left for
+// evaluation to reject, since only evaluating it is an error. Malformed
+// expressions are returned unchanged, for compilation to report.
+func foldConstants(postfix []string, cfg config) (folded []string, origin []int) {
+	if _, ok := cfg.operands(postfix, nil); !ok {
+		return postfix, nil
+	}
+
+	// Operations are computed alone, with the rounding evaluation gives
+	// their results and nothing else
+	evalCfg := cfg
+	evalCfg.rounding = cfg.roundIntermediates
+	evalCfg.maxSteps = 0
+
+	// Each entry is a subexpression, with the postfix index of each of its
+	// tokens; constant ones are a single number token
+	type entry struct {
+		tokens   []string
+		origin   []int
+		constant bool
+	}
+	number := func(value float64, i int) entry {
+		return entry{tokens: []string{formatValue(value)}, origin: []int{i}, constant: true}
+	}
+	stack := make([]entry, 0, len(postfix)/2+1)
+
+	for i, token := range postfix {
+		argc := 0
+		switch {
+		case cfg.isBinaryOperator(token):
+			argc = 2
+		case isUnaryOperator(token):
+			argc = 1
+		case token == ternary:
+			argc = 3
+		case cfg.isCall(token):
+			_, argc, _ = splitCall(token)
+		case isVariableName(token) && !isFloatKeyword(token):
+			// Eval may give any name a value, constants included
+			stack = append(stack, entry{tokens: []string{token}, origin: []int{i}})
+			continue
+		default:
+			_, err := strconv.ParseFloat(token, 64)
+			stack = append(stack, entry{tokens: []string{token}, origin: []int{i}, constant: err == nil})
+			continue
+		}
+		args := stack[len(stack)-argc:]
+		stack = stack[:len(stack)-argc]
+
+		var first float64
+		if argc > 0 && args[0].constant {
+			first, _ = strconv.ParseFloat(args[0].tokens[0], 64)
+		}
+		if token == ternary && args[0].constant {
+			chosen := args[2]
+			if first != 0 {
+				chosen = args[1]
+			}
+			stack = append(stack, chosen)
+			continue
+		}
+		if (token == "&&" || token == "||") && args[0].constant && (first != 0) == (token == "||") {
+			// Evaluation combines the deciding operand with itself
+			args = []entry{args[0], args[0]}
+		}
+
+		// A call without arguments has no operands to make it constant
+		result := entry{constant: argc > 0}
+		for _, arg := range args {
+			result.constant = result.constant && arg.constant
+			result.tokens = append(result.tokens, arg.tokens...)
+			result.origin = append(result.origin, arg.origin...)
+		}
+		result.tokens = append(result.tokens, token)
+		result.origin = append(result.origin, i)
+		if result.constant {
+			if value, err := evaluate(result.tokens, evalCfg); err == nil {
+				result = number(value, i)
+			} else {
+				result.constant = false
+			}
+		}
+		stack = append(stack, result)
+	}
+
+	return stack[0].tokens, stack[0].origin
+}
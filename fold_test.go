@@ -0,0 +1,100 @@
+package shuntingyard
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFoldConstants(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   []string
+	}{
+		{name: "constant factor", expression: "x * (60 * 60 * 24)", expected: []string{"x", "86400", "*"}},
+		{name: "whole expression", expression: "2 ^ 10 - 24", expected: []string{"1000"}},
+		{name: "functions of constants", expression: "sqrt(16) * x + max(1, 2, 3)", expected: []string{"4", "x", "*", "3", "+"}},
+		{name: "negation", expression: "-(2 + 3) * x", expected: []string{"-5", "x", "*"}},
+		{name: "left-associative chain keeps its order", expression: "x * 60 * 60", expected: []string{"x", "60", "*", "60", "*"}},
+		{name: "named constants stay names", expression: "2 * pi * r", expected: []string{"2", "pi", "*", "r", "*"}},
+		{name: "constant condition", expression: "1 < 2 ? x : y", expected: []string{"x"}},
+		{name: "deciding left operand", expression: "2 > 1 || y", expected: []string{"1"}},
+		{name: "undecided left operand", expression: "1 && x", expected: []string{"1", "x", "&&"}},
+		{name: "failing subexpression", expression: "x + 1 / 0", expected: []string{"x", "1", "0", "/", "+"}},
+		{name: "call without arguments", expression: "1 + sum()", expected: []string{"1", "sum/0", "+"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			if !slices.Equal(expr.postfix, tt.expected) {
+				t.Errorf("folded = %v, expected %v", expr.postfix, tt.expected)
+			}
+
+			unfolded, err := Compile(tt.expression, WithoutConstantFolding())
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			vars := map[string]float64{"x": 2, "y": 5, "r": 1}
+			expected, expectedErr := unfolded.Eval(vars)
+			result, err := expr.Eval(vars)
+			if result != expected || (err == nil) != (expectedErr == nil) {
+				t.Errorf("Eval() = %v, %v, unfolded %v, %v", result, err, expected, expectedErr)
+			}
+		})
+	}
+}
+
+func TestFoldZeroArgumentCall(t *testing.T) {
+	calls := 0
+	tick := WithFunction("tick", 0, func(args ...float64) (float64, error) {
+		calls++
+		return float64(calls), nil
+	})
+	expr, err := Compile("tick() * 10", tick)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	fn, err := CompileFunc("tick() * 10", tick)
+	if err != nil {
+		t.Fatalf("CompileFunc() unexpected error: %v", err)
+	}
+	// Each evaluation calls the function anew
+	if result, err := expr.Eval(nil); err != nil || result != 10 {
+		t.Errorf("Eval() = %v, %v, expected 10", result, err)
+	}
+	if result, err := fn(nil); err != nil || result != 20 {
+		t.Errorf("CompileFunc() = %v, %v, expected 20", result, err)
+	}
+}
+
+func TestWithoutConstantFolding(t *testing.T) {
+	expr, err := Compile("x * (60 * 60 * 24)", WithoutConstantFolding())
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	expected := []string{"x", "60", "60", "*", "24", "*", "*"}
+	if !slices.Equal(expr.postfix, expected) {
+		t.Errorf("postfix = %v, expected %v", expr.postfix, expected)
+	}
+}
+
+func TestFoldConstantsErrorIndex(t *testing.T) {
+	// The '/' is token 7 of the postfix as parsed, "2 3 * 4 + 1 x / +"
+	expr, err := Compile("(2 * 3 + 4) + 1 / x")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	_, err = expr.Eval(map[string]float64{"x": 0})
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Index != 7 {
+		t.Fatalf("Eval() error = %#v, expected a *TokenError at token 7", err)
+	}
+	if caret := Caret("(2 * 3 + 4) + 1 / x", err); caret != "(2 * 3 + 4) + 1 / x\n                ^" {
+		t.Errorf("Caret() = %q", caret)
+	}
+}
@@ -0,0 +1,53 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// basePrefixes maps the supported output bases to their literal prefixes.
+var basePrefixes = map[int]string{
+	2:  "0b",
+	8:  "0o",
+	10: "",
+	16: "0x",
+}
+
+// FormatBase renders an integer-valued result in the given base using the
+// conventional literal prefix: "0b" for binary, "0o" for octal and "0x" for
+// hexadecimal (e.g., 31 becomes "0x1F" or "0b11111"). Base 10 is rendered
+// without a prefix. Negative values keep their sign in front of the prefix.
+//
+// Returns an error if the base is unsupported or the value is not an integer
+// representable as int64.
+func FormatBase(value float64, base int) (string, error) {
+	prefix, ok := basePrefixes[base]
+	if !ok {
+		return "", fmt.Errorf("unsupported base: %d", base)
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) || value != math.Trunc(value) {
+		return "", fmt.Errorf("value %v is not an integer", value)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, so it must be excluded
+	if value < math.MinInt64 || value >= math.MaxInt64 {
+		return "", fmt.Errorf("value %v is out of integer range", value)
+	}
+
+	n := int64(value)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+	}
+
+	// Format the magnitude as unsigned so math.MinInt64 doesn't overflow
+	magnitude := uint64(n)
+	if n < 0 {
+		magnitude = uint64(-(n + 1)) + 1
+	}
+
+	digits := strings.ToUpper(strconv.FormatUint(magnitude, base))
+	return sign + prefix + digits, nil
+}
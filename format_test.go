@@ -0,0 +1,54 @@
+package shuntingyard
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFormatBase tests rendering integer results in alternate bases
+func TestFormatBase(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		base     int
+		expected string
+		wantErr  bool
+	}{
+		{name: "hexadecimal", value: 31, base: 16, expected: "0x1F"},
+		{name: "binary", value: 31, base: 2, expected: "0b11111"},
+		{name: "octal", value: 493, base: 8, expected: "0o755"},
+		{name: "decimal", value: 42, base: 10, expected: "42"},
+		{name: "zero", value: 0, base: 16, expected: "0x0"},
+		{name: "negative", value: -255, base: 16, expected: "-0xFF"},
+		{name: "min int64", value: math.MinInt64, base: 16, expected: "-0x8000000000000000"},
+
+		// Error cases
+		{name: "fractional value", value: 2.5, base: 16, wantErr: true},
+		{name: "unsupported base", value: 10, base: 3, wantErr: true},
+		{name: "out of range", value: 1e19, base: 16, wantErr: true},
+		{name: "NaN", value: math.NaN(), base: 2, wantErr: true},
+		{name: "infinity", value: math.Inf(1), base: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := FormatBase(tt.value, tt.base)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("FormatBase() expected error, got %q", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("FormatBase() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("FormatBase(%v, %d) = %q, expected %q", tt.value, tt.base, result, tt.expected)
+			}
+		})
+	}
+}
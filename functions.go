@@ -0,0 +1,240 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// function is a function callable from expressions. A variadic function
+// takes arity or more arguments; any other function takes arity arguments and
+// up to optional more. An operator registered with WithOperator is a
+// function of two arguments that is named in messages as an operator.
+type function struct {
+	arity    int
+	optional int
+	variadic bool
+	operator bool
+	call     func(args ...float64) (float64, error)
+}
+
+// unary adapts a single-argument math function.
+func unary(fn func(float64) float64) function {
+	return function{arity: 1, call: func(args ...float64) (float64, error) { return fn(args[0]), nil }}
+}
+
+// binary adapts a two-argument math function.
+func binary(fn func(a, b float64) float64) function {
+	return function{arity: 2, call: func(args ...float64) (float64, error) { return fn(args[0], args[1]), nil }}
+}
+
+// fold adapts a two-argument math function into a variadic function of one or
+// more arguments, applying it left to right.
+func fold(fn func(a, b float64) float64) function {
+	return function{arity: 1, variadic: true, call: func(args ...float64) (float64, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = fn(result, arg)
+		}
+		return result, nil
+	}}
+}
+
+// sum adds any number of arguments; with none it is zero.
+var sum = function{arity: 0, variadic: true, call: func(args ...float64) (float64, error) {
+	total := 0.0
+	for _, arg := range args {
+		total += arg
+	}
+	return total, nil
+}}
+
+// functions are the built-in functions available in expressions, by name.
+// Those backed by the Go math package yield NaN for domain errors such as
+// sqrt(-1) as they do there; the integer functions ncr, npr, fact, gcd and lcm
+// instead reject fractional arguments and results that overflow int64.
+// Functions registered with WithFunction are consulted first and may shadow
+// these.
+var functions = map[string]function{
+	"abs":   unary(math.Abs),
+	"sqrt":  unary(math.Sqrt),
+	"cbrt":  unary(math.Cbrt),
+	"exp":   unary(math.Exp),
+	"log":   unary(math.Log),
+	"log2":  unary(math.Log2),
+	"log10": unary(math.Log10),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"sinh":  unary(math.Sinh),
+	"cosh":  unary(math.Cosh),
+	"tanh":  unary(math.Tanh),
+
+	"min":   fold(math.Min),
+	"max":   fold(math.Max),
+	"sum":   sum,
+	"pow":   binary(math.Pow),
+	"atan2": binary(math.Atan2),
+	"hypot": binary(math.Hypot),
+
+	"ncr":  {arity: 2, call: ncr},
+	"npr":  {arity: 2, call: npr},
+	"fact": {arity: 1, call: fact},
+	"gcd":  {arity: 1, variadic: true, call: gcd},
+	"lcm":  {arity: 1, variadic: true, call: lcm},
+
+	"round": {arity: 1, optional: 1, call: round},
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"trunc": unary(math.Trunc),
+
+	"if":       {arity: 3, call: ifThenElse},
+	"coalesce": {arity: 1, variadic: true, call: coalesce},
+	"clamp":    {arity: 3, call: clamp},
+}
+
+// WithFunction registers a function that expressions can call by name, so
+// applications can add domain-specific functions such as vat(x) or
+// discount(price, pct). fn receives exactly arity arguments, in the order
+// written, and must not retain the slice; an error it returns fails the
+// evaluation. A registered function shadows a built-in one of the same name.
+//
+// WithFunction panics if name is not a valid name (ASCII letters, digits, and
+// underscores, not starting with a digit), is a keyword operator such as
+// "xor", or if arity is negative.
+func WithFunction(name string, arity int, fn func(args ...float64) (float64, error)) Option {
+	return withFunction(name, function{arity: arity, call: fn})
+}
+
+// WithVariadicFunction registers a function like WithFunction, except that it
+// accepts minArgs or more arguments, as in sum(a, b, c, d). fn receives the
+// arguments of each call as written.
+//
+// WithVariadicFunction panics under the same conditions as WithFunction, with
+// minArgs in place of arity.
+func WithVariadicFunction(name string, minArgs int, fn func(args ...float64) (float64, error)) Option {
+	return withFunction(name, function{arity: minArgs, variadic: true, call: fn})
+}
+
+// withFunction registers fn under name, panicking if the registration is invalid.
+func withFunction(name string, fn function) Option {
+	if !isIdentifier(name) || isBinaryOperator(name) {
+		panic(fmt.Sprintf("shuntingyard: invalid function name %q", name))
+	}
+	if fn.arity < 0 {
+		panic(fmt.Sprintf("shuntingyard: negative arity %d for function %q", fn.arity, name))
+	}
+
+	return withFunctions(map[string]function{name: fn})
+}
+
+// withFunctions registers a set of functions by name.
+func withFunctions(pack map[string]function) Option {
+	return func(c *config) {
+		// Copy so that options sharing a config don't share the map
+		registered := make(map[string]function, len(c.functions)+len(pack))
+		maps.Copy(registered, c.functions)
+		maps.Copy(registered, pack)
+		c.functions = registered
+	}
+}
+
+// function returns the function callable by name, registered or built-in.
+func (c config) function(name string) (function, bool) {
+	if fn, ok := c.functions[name]; ok {
+		return fn, true
+	}
+	if fn, ok := degreeFunctions[name]; ok && c.degrees {
+		return fn, true
+	}
+	fn, ok := functions[name]
+	return fn, ok
+}
+
+// isFunction reports whether name is a function available in expressions.
+func (c config) isFunction(name string) bool {
+	_, ok := c.function(name)
+	return ok
+}
+
+// callToken is the postfix token for a call of the named function with argc
+// arguments, e.g. "max/2".
+func callToken(name string, argc int) string {
+	return name + "/" + strconv.Itoa(argc)
+}
+
+// isCall reports whether a postfix token is a call of an available function.
+func (c config) isCall(token string) bool {
+	name, _, ok := splitCall(token)
+	return ok && c.isFunction(name)
+}
+
+// splitCall splits a postfix call token into the function name and the
+// number of arguments it was called with.
+func splitCall(token string) (name string, argc int, ok bool) {
+	slash := strings.LastIndexByte(token, '/')
+	if slash <= 0 {
+		return "", 0, false
+	}
+	argc, err := strconv.Atoi(token[slash+1:])
+	if err != nil || argc < 0 {
+		return "", 0, false
+	}
+	return token[:slash], argc, true
+}
+
+// checkCall resolves a postfix call token against the number of operands
+// available, returning the function and how many arguments to pass it.
+func (c config) checkCall(token string, available int) (function, int, error) {
+	name, argc, _ := splitCall(token)
+	fn, _ := c.function(name)
+	if fn.variadic && argc < fn.arity {
+		return function{}, 0, fmt.Errorf("function '%s' takes at least %s, got %d", name, plural(fn.arity, "argument"), argc)
+	}
+	if !fn.variadic && (argc < fn.arity || argc > fn.arity+fn.optional) {
+		takes := plural(fn.arity, "argument")
+		switch {
+		case fn.optional == 1:
+			takes = fmt.Sprintf("%d or %s", fn.arity, plural(fn.arity+1, "argument"))
+		case fn.optional > 1:
+			takes = fmt.Sprintf("%d to %s", fn.arity, plural(fn.arity+fn.optional, "argument"))
+		}
+		return function{}, 0, fmt.Errorf("function '%s' takes %s, got %d", name, takes, argc)
+	}
+	if available < argc {
+		return function{}, 0, fmt.Errorf("invalid expression: insufficient arguments for function '%s'", name)
+	}
+	return fn, argc, nil
+}
+
+// invoke calls fn, attributing any error it returns to the function by name.
+func (fn function) invoke(token string, args []float64) (float64, error) {
+	value, err := fn.call(args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn.describe(token), err)
+	}
+	return value, nil
+}
+
+// describe names the function called by token in messages, e.g. "function
+// 'max'" or "operator '//'".
+func (fn function) describe(token string) string {
+	name, _, _ := splitCall(token)
+	if fn.operator {
+		return "operator '" + name + "'"
+	}
+	return "function '" + name + "'"
+}
+
+// plural formats a count with a noun, e.g. "1 argument" or "2 arguments".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
@@ -0,0 +1,259 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestFunctions tests the built-in functions through the full pipeline
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "sine", expression: "sin(0.5)", expected: math.Sin(0.5)},
+		{name: "square root", expression: "sqrt(2)", expected: math.Sqrt2},
+		{name: "natural logarithm", expression: "log(10)", expected: math.Ln10},
+		{name: "absolute value of negative", expression: "abs(-3)", expected: 3},
+		{name: "expression argument", expression: "sqrt(3 * 3 + 4 * 4)", expected: 5},
+		{name: "nested calls", expression: "abs(log10(0.001))", expected: 3},
+		{name: "call in arithmetic", expression: "2 * cos(0) + 1", expected: 3},
+		{name: "binds tighter than exponentiation", expression: "sqrt(4) ^ 3", expected: 8},
+		{name: "negated call", expression: "-exp(0)", expected: -1},
+		{name: "space before parenthesis", expression: "sqrt (16)", expected: 4},
+		{name: "maximum", expression: "max(1, 2)", expected: 2},
+		{name: "minimum of expressions", expression: "min(2 * 3, 10 - 5)", expected: 5},
+		{name: "power", expression: "pow(2, 10)", expected: 1024},
+		{name: "two-argument arctangent", expression: "atan2(1, 1)", expected: math.Pi / 4},
+		{name: "hypotenuse", expression: "hypot(3, 4)", expected: 5},
+		{name: "nested multi-argument calls", expression: "max(min(1, 2), pow(2, 0.5))", expected: math.Sqrt2},
+		{name: "variadic maximum", expression: "max(1, 5, 3, 9)", expected: 9},
+		{name: "variadic minimum", expression: "min(4, -2, 7)", expected: -2},
+		{name: "maximum of one", expression: "max(3)", expected: 3},
+		{name: "sum", expression: "sum(1, 2, 3, 4)", expected: 10},
+		{name: "empty sum", expression: "sum() + 1", expected: 1},
+
+		// Error cases
+		{name: "unknown function", expression: "foo(2)", wantErr: true},
+		{name: "undefined variable", expression: "2 + x", wantErr: true},
+		{name: "missing parentheses", expression: "sqrt 4", wantErr: true},
+		{name: "missing argument", expression: "sqrt()", wantErr: true},
+		{name: "call after operand", expression: "2 sqrt(4)", wantErr: true},
+		{name: "unclosed call", expression: "sqrt(4", wantErr: true},
+		{name: "too few arguments", expression: "pow(2)", wantErr: true},
+		{name: "too many arguments", expression: "sqrt(4, 9)", wantErr: true},
+		{name: "no arguments", expression: "max()", wantErr: true},
+		{name: "variadic without enough arguments", expression: "min() + 1", wantErr: true},
+		{name: "trailing comma", expression: "max(1, 2,)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFunctionDomainError tests that math domain errors surface as NaN
+func TestFunctionDomainError(t *testing.T) {
+	result, err := eval("sqrt(-1)")
+	if err != nil {
+		t.Fatalf("eval() unexpected error: %v", err)
+	}
+	if !math.IsNaN(result) {
+		t.Errorf("eval(\"sqrt(-1)\") = %v, expected NaN", result)
+	}
+}
+
+// TestFunctionArgumentCountError tests the message for calls with the wrong number of arguments
+func TestFunctionArgumentCountError(t *testing.T) {
+	_, err := eval("1 + pow(2)")
+	if err == nil {
+		t.Fatal("eval() expected error, got nil")
+	}
+
+	expected := "function 'pow' takes 2 arguments, got 1 at token 2"
+	if err.Error() != expected {
+		t.Errorf("eval() error = %q, expected %q", err.Error(), expected)
+	}
+
+	_, err = eval("max()")
+	if err == nil {
+		t.Fatal("eval() expected error, got nil")
+	}
+
+	expected = "function 'max' takes at least 1 argument, got 0 at token 0"
+	if err.Error() != expected {
+		t.Errorf("eval() error = %q, expected %q", err.Error(), expected)
+	}
+
+	_, err = eval("round(1, 2, 3)")
+	if err == nil {
+		t.Fatal("eval() expected error, got nil")
+	}
+
+	expected = "function 'round' takes 1 or 2 arguments, got 3 at token 0"
+	if err.Error() != expected {
+		t.Errorf("eval() error = %q, expected %q", err.Error(), expected)
+	}
+}
+
+// TestWithFunction tests calling registered functions
+func TestWithFunction(t *testing.T) {
+	errNegativePrice := errors.New("negative price")
+
+	opts := []Option{
+		WithFunction("vat", 1, func(args ...float64) (float64, error) {
+			return args[0] * 1.25, nil
+		}),
+		WithFunction("discount", 2, func(args ...float64) (float64, error) {
+			if args[0] < 0 {
+				return 0, errNegativePrice
+			}
+			return args[0] * (1 - args[1]/100), nil
+		}),
+		WithFunction("answer", 0, func(args ...float64) (float64, error) {
+			return 42, nil
+		}),
+		WithFunction("sqrt", 1, func(args ...float64) (float64, error) {
+			return -1, nil
+		}),
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    error
+	}{
+		{name: "single argument", expression: "vat(100)", expected: 125},
+		{name: "arguments in order", expression: "discount(200, 10)", expected: 180},
+		{name: "composed with built-ins", expression: "vat(discount(max(80, 100), 20))", expected: 100},
+		{name: "no arguments", expression: "answer() + 1", expected: 43},
+		{name: "shadows built-in", expression: "sqrt(4)", expected: -1},
+		{name: "function error", expression: "1 + discount(-5, 10)", wantErr: errNegativePrice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, opts...)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("eval() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+
+	// Without the option the names are unknown
+	if _, err := eval("vat(100)"); err == nil {
+		t.Error("eval() without WithFunction expected error, got nil")
+	}
+}
+
+// TestWithFunctionInvalid tests that invalid registrations panic
+func TestWithFunctionInvalid(t *testing.T) {
+	fn := func(args ...float64) (float64, error) { return 0, nil }
+
+	tests := []struct {
+		name  string
+		fname string
+		arity int
+	}{
+		{name: "empty name", fname: "", arity: 1},
+		{name: "leading digit", fname: "2x", arity: 1},
+		{name: "operator characters", fname: "a+b", arity: 1},
+		{name: "keyword operator", fname: "xor", arity: 2},
+		{name: "negative arity", fname: "f", arity: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithFunction(%q, %d) expected panic", tt.fname, tt.arity)
+				}
+			}()
+			WithFunction(tt.fname, tt.arity, fn)
+		})
+	}
+}
+
+// TestWithVariadicFunction tests calling registered functions with any number of arguments
+func TestWithVariadicFunction(t *testing.T) {
+	opts := []Option{
+		WithVariadicFunction("count", 0, func(args ...float64) (float64, error) {
+			return float64(len(args)), nil
+		}),
+		WithVariadicFunction("first", 1, func(args ...float64) (float64, error) {
+			return args[0], nil
+		}),
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "no arguments", expression: "count()", expected: 0},
+		{name: "several arguments", expression: "count(1, 2, 3, 4, 5)", expected: 5},
+		{name: "arguments in order", expression: "first(7, 8, 9)", expected: 7},
+		{name: "nested variadic calls", expression: "count(count(1, 2), first(3), max(4, 5, 6))", expected: 3},
+
+		// Error cases
+		{name: "too few arguments", expression: "first()", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package shuntingyard
+
+import "strings"
+
+// ToInfix converts postfix tokens, as returned by Parse, back to an infix
+// expression, for showing users expressions stored in Reverse Polish Notation:
+//
+//	ToInfix([]string{"2", "3", "x", "+", "*"}) // "2 * (3 + x)"
+//
+// Parentheses appear only where the precedence and associativity of the
+// operators, including those configured with WithOperator and
+// WithPrecedenceTable, require them, so that Parse turns the result back into
+// the same postfix tokens: ["8", "4", "-", "2", "-"] is "8 - 4 - 2" and
+// ["8", "4", "2", "-", "-"] is "8 - (4 - 2)". Binary operators are spaced,
+// prefix operators are not, and operators are written by their default
+// symbols, whatever WithBitwiseSymbols says.
+//
+// Returns the infix expression, or a *TokenError for tokens that don't form
+// an expression.
+func ToInfix(postfixTokens []string, opts ...Option) (string, error) {
+	cfg := newConfig(opts)
+	root, err := buildAST(postfixTokens, cfg)
+	if err != nil {
+		return "", err
+	}
+	w := infixWriter{cfg: cfg}
+	w.write(root, nil, nil)
+	return w.b.String(), nil
+}
+
+// infixWriter writes expression trees in infix notation.
+type infixWriter struct {
+	cfg config
+	b   strings.Builder
+}
+
+// write writes n, head being the binding of the operator written before it
+// and tail that of the operator after it, or nil where a parenthesis, comma,
+// '?', ':' or either end of the expression delimits it.
+func (w *infixWriter) write(n Node, head, tail *binding) {
+	if w.parenthesize(n, head, tail) {
+		w.b.WriteByte('(')
+		w.write(n, nil, nil)
+		w.b.WriteByte(')')
+		return
+	}
+
+	switch n := n.(type) {
+	case *NumberNode:
+		w.b.WriteString(n.Value)
+	case *VariableNode:
+		w.b.WriteString(n.Name)
+	case *UnaryNode:
+		w.b.WriteString(n.Operator)
+		w.write(n.Operand, &unaryBinding, tail)
+	case *BinaryNode:
+		b := w.cfg.binding(n.Operator)
+		w.write(n.Left, head, &b)
+		w.b.WriteString(" " + n.Operator + " ")
+		w.write(n.Right, &b, tail)
+	case *ConditionalNode:
+		// A conditional condition would take the '?' as its own
+		if _, ok := n.Condition.(*ConditionalNode); ok {
+			w.b.WriteByte('(')
+			w.write(n.Condition, nil, nil)
+			w.b.WriteByte(')')
+		} else {
+			w.write(n.Condition, nil, nil)
+		}
+		w.b.WriteString(" ? ")
+		w.write(n.Then, nil, nil)
+		w.b.WriteString(" : ")
+		w.write(n.Else, nil, tail)
+	case *CallNode:
+		w.b.WriteString(n.Name + "(")
+		for i, arg := range n.Args {
+			if i > 0 {
+				w.b.WriteString(", ")
+			}
+			w.write(arg, nil, nil)
+		}
+		w.b.WriteByte(')')
+	}
+}
+
+// parenthesize reports whether n needs parentheses between operators with the
+// bindings head and tail: whether Parse would apply either before the
+// operator of n.
+func (w *infixWriter) parenthesize(n Node, head, tail *binding) bool {
+	var b binding
+	prefix := false
+	switch n := n.(type) {
+	case *ConditionalNode:
+		// The conditional binds loosest of all
+		return head != nil || tail != nil
+	case *BinaryNode:
+		b = w.cfg.binding(n.Operator)
+	case *UnaryNode:
+		b, prefix = unaryBinding, true
+	case *NumberNode:
+		// A negative literal, as PartialEvaluate writes, reads as a sign
+		if !strings.HasPrefix(n.Value, "-") {
+			return false
+		}
+		b, prefix = unaryBinding, true
+	default:
+		return false
+	}
+
+	// A prefix operator starts its operand, so nothing before it can take
+	// that from it
+	if head != nil && !prefix && head.precedes(b) {
+		return true
+	}
+	return tail != nil && !b.precedes(*tail)
+}
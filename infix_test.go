@@ -0,0 +1,141 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestToInfix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected string
+	}{
+		{name: "single number", input: []string{"42"}, expected: "42"},
+		{name: "precedence needs no parentheses", input: []string{"1", "2", "3", "*", "+"}, expected: "1 + 2 * 3"},
+		{name: "lower precedence operand", input: []string{"2", "3", "x", "+", "*"}, expected: "2 * (3 + x)"},
+		{name: "left associative chain", input: []string{"8", "4", "-", "2", "-"}, expected: "8 - 4 - 2"},
+		{name: "right grouping of left associative", input: []string{"8", "4", "2", "-", "-"}, expected: "8 - (4 - 2)"},
+		{name: "right associative chain", input: []string{"2", "3", "2", "^", "^"}, expected: "2 ^ 3 ^ 2"},
+		{name: "left grouping of right associative", input: []string{"2", "3", "^", "2", "^"}, expected: "(2 ^ 3) ^ 2"},
+		{name: "negation of a power", input: []string{"2", "2", "^", "neg"}, expected: "-2 ^ 2"},
+		{name: "power of a negation", input: []string{"2", "neg", "2", "^"}, expected: "(-2) ^ 2"},
+		{name: "negative exponent", input: []string{"2", "3", "neg", "^"}, expected: "2 ^ -3"},
+		{name: "negation of a sum", input: []string{"2", "3", "+", "neg"}, expected: "-(2 + 3)"},
+		{name: "nested prefix operators", input: []string{"x", "neg", "!"}, expected: "!-x"},
+		{name: "negative literal", input: []string{"-3", "2", "^"}, expected: "(-3) ^ 2"},
+		{name: "comparison and logic", input: []string{"a", "1", ">", "b", "2", "<", "&&"}, expected: "a > 1 && b < 2"},
+		{name: "calls", input: []string{"x", "1", "+", "y", "max/2", "sqrt/1"}, expected: "sqrt(max(x + 1, y))"},
+		{name: "conditional", input: []string{"a", "b", "c", "d", "?:", "e", "?:"}, expected: "a ? b ? c : d : e"},
+		{name: "nested else", input: []string{"a", "b", "c", "d", "e", "?:", "?:"}, expected: "a ? b : c ? d : e"},
+		{name: "conditional condition", input: []string{"a", "b", "c", "?:", "d", "e", "?:"}, expected: "(a ? b : c) ? d : e"},
+		{name: "conditional operand", input: []string{"a", "b", "c", "?:", "1", "+"}, expected: "(a ? b : c) + 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToInfix(tt.input)
+			if err != nil {
+				t.Fatalf("ToInfix() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ToInfix() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestToInfixRoundTrip checks that Parse turns the infix ToInfix writes back
+// into the postfix it was given.
+func TestToInfixRoundTrip(t *testing.T) {
+	opts := []Option{
+		WithOperator("//", 9, LeftAssociative, func(a, b float64) (float64, error) { return math.Floor(a / b), nil }),
+		WithOperator("**", 12, LeftAssociative, func(a, b float64) (float64, error) { return math.Pow(a, b), nil }),
+	}
+	expressions := []string{
+		"a - (b - c) - d",
+		"a / (b * c) % d",
+		"-(a - b) * -c ^ -d",
+		"(-a) ^ b ^ (c ^ d)",
+		"a | b xor c & d << 1 == 0 || !e",
+		"(a || b) && (c | d)",
+		"(a ? b : c) * (d ? e : f ? g : h)",
+		"max(a ? 1 : 2, -b, ~c) + 1",
+		"a // (b // c) * d",
+		"a ** -b ** c",
+		"(a ** b) ^ c ** d",
+	}
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			postfix := mustParse(t, expression, opts)
+			infix, err := ToInfix(postfix, opts...)
+			if err != nil {
+				t.Fatalf("ToInfix() unexpected error: %v", err)
+			}
+			if again := mustParse(t, infix, opts); !slices.Equal(again, postfix) {
+				t.Errorf("ToInfix() = %q, which parses to %v, expected %v", infix, again, postfix)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, expression string, opts []Option) []string {
+	t.Helper()
+	tokens, err := Scan(expression, opts...)
+	if err != nil {
+		t.Fatalf("Scan(%q) unexpected error: %v", expression, err)
+	}
+	postfix, err := Parse(tokens, opts...)
+	if err != nil {
+		t.Fatalf("Parse(%q) unexpected error: %v", expression, err)
+	}
+	return postfix
+}
+
+func TestToInfixPrecedenceTable(t *testing.T) {
+	table := DefaultPrecedence()
+	table["+"] = Precedence{Level: 10, Assoc: LeftAssociative}
+	postfix := []string{"a", "b", "+", "c", "*"}
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{name: "default precedence", expected: "(a + b) * c"},
+		{name: "addition binding tighter", opts: []Option{WithPrecedenceTable(table)}, expected: "a + b * c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToInfix(postfix, tt.opts...)
+			if err != nil || result != tt.expected {
+				t.Errorf("ToInfix() = %q, %v, expected %q", result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToInfixErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		index int // -1 for an error without a token
+	}{
+		{name: "empty", input: []string{}, index: -1},
+		{name: "missing operand", input: []string{"1", "+"}, index: 1},
+		{name: "too many operands", input: []string{"1", "2"}, index: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ToInfix(tt.input)
+			if err == nil {
+				t.Fatal("ToInfix() expected error, got nil")
+			}
+			var tokenErr *TokenError
+			if errors.As(err, &tokenErr) != (tt.index >= 0) || tt.index >= 0 && tokenErr.Index != tt.index {
+				t.Errorf("ToInfix() error = %v, expected index %d", err, tt.index)
+			}
+		})
+	}
+}
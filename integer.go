@@ -0,0 +1,426 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+	"strconv"
+)
+
+// errInexactDivision is the error for an integer division with a remainder
+// when EvaluateInt isn't truncating.
+var errInexactDivision = errors.New("division has a remainder")
+
+// WithTruncatingDivision makes EvaluateInt truncate quotients toward zero, as
+// Go's integer division does, so that "7 / 2" is 3. By default a division
+// with a remainder is an error.
+func WithTruncatingDivision() Option {
+	return func(c *config) {
+		c.truncateDivision = true
+	}
+}
+
+// EvaluateInt computes the result of a postfix (RPN) expression in int64
+// arithmetic, for counters, indexes, and quantities where a silently rounded
+// or wrapped result would be a bug. Literals must be integers, and variables
+// must hold integral values. Division fails when it leaves a remainder,
+// unless WithTruncatingDivision is given; "%" is the remainder of truncated
+// division, with the sign of the dividend. A negative exponent is a division
+// by a power. Comparisons and logical operators yield 1 or 0.
+//
+// Unlike Go's arithmetic, "+", "-", "*", "^", "<<", negation and the functions
+// report overflow instead of wrapping around. The functions available are abs,
+// min, max, sum, floor, ceil, trunc, round, if, coalesce, clamp, gcd and lcm,
+// unless shadowed by WithFunction.
+//
+// Returns the result or an error for invalid expressions, non-integer
+// operands, division or modulo by zero, inexact division, and overflow.
+// Failures caused by a specific token are reported as a *TokenError holding
+// its index.
+func EvaluateInt(postfixTokens []string, opts ...Option) (int64, error) {
+	cfg := newConfig(opts)
+	return evaluateWith(postfixTokens, newIntegerArithmetic[int64](&cfg), cfg)
+}
+
+// integer is the set of types integerArithmetic computes in.
+type integer interface {
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64
+}
+
+// integerArithmetic implements the checked arithmetic of EvaluateInt, in the
+// width and signedness of T.
+type integerArithmetic[T integer] struct {
+	cfg *config
+
+	// lo and hi bound T, which is width bits wide
+	lo, hi T
+	width  int
+	signed bool
+}
+
+// newIntegerArithmetic returns the checked arithmetic of T.
+func newIntegerArithmetic[T integer](cfg *config) Arithmetic[T] {
+	n := integerArithmetic[T]{cfg: cfg, hi: ^T(0), signed: ^T(0) < 0}
+	for v := T(1); v != 0; v <<= 1 {
+		n.width++
+	}
+	if n.signed {
+		n.lo = T(1) << (n.width - 1)
+		n.hi = ^n.lo
+	}
+	return n
+}
+
+// overflow is the error for a result outside T.
+func (n integerArithmetic[T]) overflow() error {
+	if n.signed && n.width == 64 {
+		return errOverflow
+	}
+	return fmt.Errorf("result overflows %T", n.hi)
+}
+
+// ParseLiteral reads a literal exactly, so that "1e3" is as much an integer
+// as "1000".
+func (n integerArithmetic[T]) ParseLiteral(token string) (T, error) {
+	if _, err := strconv.ParseFloat(token, 64); err != nil {
+		return 0, ErrInvalidNumber
+	}
+	exact, ok := new(big.Rat).SetString(token)
+	if !ok || !exact.IsInt() {
+		return 0, &literalError{token: token, problem: "is not an integer"}
+	}
+	value, ok := n.fromBig(exact.Num())
+	if !ok {
+		return 0, &literalError{token: token, problem: fmt.Sprintf("overflows %T", n.hi)}
+	}
+	return value, nil
+}
+
+func (n integerArithmetic[T]) Add(a, b T) (T, error) {
+	sum := a + b
+	if n.signed && (sum > a) != (b > 0) || !n.signed && sum < a {
+		return 0, n.overflow()
+	}
+	return sum, nil
+}
+
+func (n integerArithmetic[T]) Sub(a, b T) (T, error) {
+	difference := a - b
+	if n.signed && (difference < a) != (b > 0) || !n.signed && b > a {
+		return 0, n.overflow()
+	}
+	return difference, nil
+}
+
+func (n integerArithmetic[T]) Mul(a, b T) (T, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	// The quotient check misses the one signed product that wraps onto itself
+	if product/b != a || n.signed && (a == n.lo && b == ^T(0) || b == n.lo && a == ^T(0)) {
+		return 0, n.overflow()
+	}
+	return product, nil
+}
+
+// Div divides a by b, failing on a remainder unless division truncates.
+func (n integerArithmetic[T]) Div(a, b T) (T, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	if n.signed && a == n.lo && b == ^T(0) {
+		return 0, n.overflow()
+	}
+	if a%b != 0 && !n.cfg.truncateDivision {
+		return 0, errInexactDivision
+	}
+	return a / b, nil
+}
+
+func (n integerArithmetic[T]) Binary(operator string, a, b T) (T, error) {
+	switch operator {
+	case "%":
+		if b == 0 {
+			return 0, fmt.Errorf("modulo by zero")
+		}
+		if n.signed && b == ^T(0) {
+			// The most negative value % -1 overflows the intermediate quotient
+			return 0, nil
+		}
+		return a % b, nil
+	case "^":
+		return n.pow(a, b)
+	case "<":
+		return integerFromBool[T](a < b), nil
+	case ">":
+		return integerFromBool[T](a > b), nil
+	case "<=":
+		return integerFromBool[T](a <= b), nil
+	case ">=":
+		return integerFromBool[T](a >= b), nil
+	case "==":
+		return integerFromBool[T](a == b), nil
+	case "!=":
+		return integerFromBool[T](a != b), nil
+	case "&&":
+		return integerFromBool[T](a != 0 && b != 0), nil
+	case "||":
+		return integerFromBool[T](a != 0 || b != 0), nil
+	case "&":
+		return a & b, nil
+	case "|":
+		return a | b, nil
+	case "xor":
+		return a ^ b, nil
+	}
+
+	// Shifts
+	if b < 0 {
+		return 0, fmt.Errorf("negative shift count")
+	}
+	if operator == ">>" {
+		// A signed value shifted out keeps its sign, as -1 or 0
+		if n.signed {
+			return a >> min(uint64(b), uint64(n.width-1)), nil
+		}
+		if uint64(b) >= uint64(n.width) {
+			return 0, nil
+		}
+		return a >> b, nil
+	}
+	if a == 0 {
+		return 0, nil
+	}
+	if uint64(b) >= uint64(n.width) || a<<b>>b != a {
+		return 0, n.overflow()
+	}
+	return a << b, nil
+}
+
+// pow raises a to the power e by repeated squaring, reporting overflow. A
+// negative power is the quotient 1 / a^-e.
+func (n integerArithmetic[T]) pow(a, e T) (T, error) {
+	if e < 0 {
+		switch {
+		case a == 0:
+			return 0, fmt.Errorf("division by zero")
+		case a == 1:
+			return 1, nil
+		case a == ^T(0):
+			// Only a signed T reaches here, where ^0 is -1
+			if e%2 == 0 {
+				return 1, nil
+			}
+			return a, nil
+		}
+		if !n.cfg.truncateDivision {
+			return 0, errInexactDivision
+		}
+		return 0, nil
+	}
+
+	result := T(1)
+	for e > 0 {
+		var err error
+		if e&1 == 1 {
+			if result, err = n.Mul(result, a); err != nil {
+				return 0, err
+			}
+		}
+		e >>= 1
+		if e > 0 {
+			if a, err = n.Mul(a, a); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func (n integerArithmetic[T]) Unary(operator string, a T) (T, error) {
+	switch operator {
+	case negate:
+		return n.Sub(0, a)
+	case "!":
+		return integerFromBool[T](a == 0), nil
+	}
+	return ^a, nil
+}
+
+func (integerArithmetic[T]) Truth(a T) bool { return a != 0 }
+
+func (n integerArithmetic[T]) Variable(name string, value float64) (T, error) {
+	// The bounds are powers of two, which float64 holds exactly
+	if value != math.Trunc(value) || value < float64(n.lo) || value >= 2*float64(n.hi/2+1) {
+		return 0, fmt.Errorf("variable '%s' is not %s", name, n.kind())
+	}
+	return T(value), nil
+}
+
+// Round rounds to tens, hundreds, and so on for a negative scale; integers
+// already have every non-negative number of places.
+func (n integerArithmetic[T]) Round(a T, scale int, mode RoundingMode) (T, error) {
+	if scale >= 0 {
+		return a, nil
+	}
+	var exact *big.Rat
+	if n.signed {
+		exact = new(big.Rat).SetInt64(int64(a))
+	} else {
+		exact = new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(a)))
+	}
+	// Rounding can carry past the largest value, as 127 to tens does in int8
+	rounded, ok := n.fromBig(ratRoundMode(exact, int64(scale), mode).Num())
+	if !ok {
+		return 0, n.overflow()
+	}
+	return rounded, nil
+}
+
+// fromBig converts x to T, reporting whether it fits.
+func (n integerArithmetic[T]) fromBig(x *big.Int) (T, bool) {
+	if n.signed && x.IsInt64() && int64(T(x.Int64())) == x.Int64() {
+		return T(x.Int64()), true
+	}
+	if !n.signed && x.IsUint64() && uint64(T(x.Uint64())) == x.Uint64() {
+		return T(x.Uint64()), true
+	}
+	return 0, false
+}
+
+// kind describes T in messages, e.g. "an integer" or "a uint8".
+func (n integerArithmetic[T]) kind() string {
+	if n.signed && n.width == 64 {
+		return "an integer"
+	}
+	return fmt.Sprintf("a %T", n.hi)
+}
+
+// invoke computes the function in int64, whose range covers every argument
+// that fits one; unsigned 64-bit arguments beyond it overflow.
+func (n integerArithmetic[T]) invoke(call function, token string, args []T) (T, error) {
+	name, _, _ := splitCall(token)
+	fn, ok := intFunctions[name]
+	if _, registered := n.cfg.functions[name]; !ok || registered || call.operator {
+		return 0, fmt.Errorf("%s has no integer result", call.describe(token))
+	}
+	wide := make([]int64, len(args))
+	for i, arg := range args {
+		if !n.signed && uint64(arg) > math.MaxInt64 {
+			return 0, fmt.Errorf("function '%s': %w", name, errOverflow)
+		}
+		wide[i] = int64(arg)
+	}
+	result, err := fn(wide)
+	if err != nil {
+		return 0, fmt.Errorf("function '%s': %w", name, err)
+	}
+	if int64(T(result)) != result || !n.signed && result < 0 {
+		return 0, fmt.Errorf("function '%s': %w", name, n.overflow())
+	}
+	return T(result), nil
+}
+
+// integerFromBool converts the outcome of a comparison to its numeric form, 1
+// or 0.
+func integerFromBool[T integer](b bool) T {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// int64Arithmetic is the checked arithmetic of the functions EvaluateInt
+// computes.
+var int64Arithmetic = newIntegerArithmetic[int64](nil)
+
+// intFunctions are the built-in functions EvaluateInt computes on integers.
+// Their argument counts are checked against the function table beforehand.
+var intFunctions = map[string]func(args []int64) (int64, error){
+	"abs": func(args []int64) (int64, error) {
+		if args[0] == math.MinInt64 {
+			return 0, errOverflow
+		}
+		return int64(absInt(args[0])), nil
+	},
+	"min": func(args []int64) (int64, error) { return slices.Min(args), nil },
+	"max": func(args []int64) (int64, error) { return slices.Max(args), nil },
+	"sum": func(args []int64) (int64, error) {
+		total := int64(0)
+		for _, arg := range args {
+			sum := total + arg
+			if (sum > total) != (arg > 0) {
+				return 0, errOverflow
+			}
+			total = sum
+		}
+		return total, nil
+	},
+	// Integers are already whole
+	"floor": func(args []int64) (int64, error) { return args[0], nil },
+	"ceil":  func(args []int64) (int64, error) { return args[0], nil },
+	"trunc": func(args []int64) (int64, error) { return args[0], nil },
+	"round": func(args []int64) (int64, error) {
+		if len(args) == 1 || args[1] >= 0 {
+			return args[0], nil
+		}
+		if args[1] < -18 {
+			return 0, nil
+		}
+		// Round half away from zero to a multiple of unit
+		unit := int64(math.Pow10(int(-args[1])))
+		quotient := args[0] / unit
+		switch {
+		case 2*absInt(args[0]%unit) < uint64(unit):
+		case args[0] < 0:
+			quotient--
+		default:
+			quotient++
+		}
+		return int64Arithmetic.Mul(quotient, unit)
+	},
+	"if": func(args []int64) (int64, error) {
+		if args[0] != 0 {
+			return args[1], nil
+		}
+		return args[2], nil
+	},
+	// Integers are never missing, so coalesce always yields its first argument
+	"coalesce": func(args []int64) (int64, error) { return args[0], nil },
+	"clamp": func(args []int64) (int64, error) {
+		x, lo, hi := args[0], args[1], args[2]
+		if lo > hi {
+			return 0, errors.New("lower bound exceeds upper bound")
+		}
+		return min(max(x, lo), hi), nil
+	},
+	"gcd": func(args []int64) (int64, error) {
+		result := uint64(0)
+		for _, n := range args {
+			result = euclid(result, absInt(n))
+		}
+		if result > math.MaxInt64 {
+			return 0, errOverflow
+		}
+		return int64(result), nil
+	},
+	"lcm": func(args []int64) (int64, error) {
+		result := uint64(1)
+		for _, n := range args {
+			m := absInt(n)
+			if m == 0 {
+				return 0, nil
+			}
+			hi, lo := bits.Mul64(result/euclid(result, m), m)
+			if hi != 0 || lo > math.MaxInt64 {
+				return 0, errOverflow
+			}
+			result = lo
+		}
+		return int64(result), nil
+	},
+}
@@ -0,0 +1,94 @@
+package shuntingyard
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestEvaluateInt tests integer evaluation through the full pipeline
+func TestEvaluateInt(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   int64
+		wantErr    error // the error expected to be wrapped
+		wantAny    bool  // expects an error of any kind
+	}{
+		{name: "arithmetic", expression: "2 + 3 * 4 - 1", expected: 13},
+		{name: "exact division", expression: "12 / 4", expected: 3},
+		{name: "truncating division", expression: "-7 / 2", opts: []Option{WithTruncatingDivision()}, expected: -3},
+		{name: "modulo keeps sign of dividend", expression: "-7 % 3", expected: -1},
+		{name: "power", expression: "3 ^ 4", expected: 81},
+		{name: "negative power of one", expression: "(-1) ^ -3", expected: -1},
+		{name: "truncated negative power", expression: "2 ^ -1", opts: []Option{WithTruncatingDivision()}, expected: 0},
+		{name: "largest value", expression: "9223372036854775806 + 1", expected: 9223372036854775807},
+		{name: "smallest value", expression: "-9223372036854775807 - 1", expected: -9223372036854775808},
+		{name: "smallest product", expression: "-4611686018427387904 * 2", expected: -9223372036854775808},
+		{name: "scientific notation", expression: "1.5e3 + 2E2", expected: 1700},
+		{name: "hexadecimal beyond float64 precision", expression: "0x7FFFFFFFFFFFFFFF - 1", expected: 9223372036854775806},
+		{name: "beyond float64 precision", expression: "9007199254740993 * 3", expected: 27021597764222979},
+		{name: "comparison and logic", expression: "(3 > 2) + (1 && 0) + !0", expected: 2},
+		{name: "bitwise", expression: "(12 & 10) | (1 << 4) xor ~0", expected: -17},
+		{name: "arithmetic right shift", expression: "-9 >> 1", expected: -5},
+		{name: "conditional", expression: "1 < 2 ? 10 : 20", expected: 10},
+		{name: "functions", expression: "max(1, 5, 3) + abs(-2) + clamp(9, 0, 4) + gcd(12, 18) + lcm(4, 6)", expected: 29},
+		{name: "round to tens", expression: "round(-1250, -2) + round(1249, -1)", expected: -50},
+		{name: "variable", expression: "n * 2", opts: []Option{WithConstant("n", 21)}, expected: 42},
+
+		// Error cases
+		{name: "addition overflow", expression: "9223372036854775807 + 1", wantErr: errOverflow},
+		{name: "subtraction overflow", expression: "-9223372036854775807 - 2", wantErr: errOverflow},
+		{name: "multiplication overflow", expression: "4611686018427387904 * 2", wantErr: errOverflow},
+		{name: "power overflow", expression: "2 ^ 63", wantErr: errOverflow},
+		{name: "shift overflow", expression: "1 << 63", wantErr: errOverflow},
+		{name: "negation overflow", expression: "-(-9223372036854775807 - 1)", wantErr: errOverflow},
+		{name: "quotient overflow", expression: "(-9223372036854775807 - 1) / -1", wantErr: errOverflow},
+		{name: "sum overflow", expression: "sum(9223372036854775807, 1)", wantErr: errOverflow},
+		{name: "inexact division", expression: "7 / 2", wantErr: errInexactDivision},
+		{name: "inexact negative power", expression: "2 ^ -1", wantErr: errInexactDivision},
+		{name: "division by zero", expression: "1 / 0", wantAny: true},
+		{name: "modulo by zero", expression: "1 % 0", wantAny: true},
+		{name: "fractional literal", expression: "1.5 + 1", wantAny: true},
+		{name: "literal out of range", expression: "9223372036854775808", wantAny: true},
+		{name: "fractional exponent literal", expression: "25e-1", wantAny: true},
+		{name: "exponent literal out of range", expression: "1e19", wantAny: true},
+		{name: "fractional variable", expression: "pi * 2", wantAny: true},
+		{name: "function without integer result", expression: "sqrt(4)", wantAny: true},
+		{name: "negative shift", expression: "1 << -1", wantAny: true},
+		{name: "undefined variable", expression: "y + 1", wantErr: ErrUndefinedVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			result, err := EvaluateInt(postfix, tt.opts...)
+
+			if tt.wantErr != nil || tt.wantAny {
+				if err == nil {
+					t.Errorf("EvaluateInt() expected error, got %d", result)
+				} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("EvaluateInt() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateInt() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("EvaluateInt(%q) = %d, expected %d", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
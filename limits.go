@@ -0,0 +1,68 @@
+package shuntingyard
+
+// WithMaxTokens makes Scan and Parse reject expressions of more than n tokens,
+// bounding the memory and work spent on untrusted input. Scan stops as soon as
+// the limit is passed. Zero or less means no limit, which is the default.
+func WithMaxTokens(n int) Option {
+	return func(c *config) {
+		c.maxTokens = n
+	}
+}
+
+// WithMaxOperators makes Parse reject expressions with more than n operators,
+// counting prefix operators, the '?' of each conditional, and function calls.
+// Zero or less means no limit, which is the default.
+func WithMaxOperators(n int) Option {
+	return func(c *config) {
+		c.maxOperators = n
+	}
+}
+
+// WithMaxSteps makes evaluation fail once it has taken n steps, a step being
+// one postfix token, or one instruction of a compiled Expression. It applies
+// to Evaluate and the evaluators built on EvaluateWith as well as to compiled
+// expressions; CompileFunc, whose functions take one step per instruction at
+// most, rejects expressions that could exceed it. Zero or less means no
+// limit, which is the default.
+func WithMaxSteps(n int) Option {
+	return func(c *config) {
+		c.maxSteps = n
+	}
+}
+
+// checkLimits applies WithMaxTokens and WithMaxOperators to the infix tokens
+// of Parse.
+func (c config) checkLimits(tokens []string, spans []Span) error {
+	if c.maxTokens > 0 && len(tokens) > c.maxTokens {
+		return c.errorAt(spans, c.maxTokens, "%w", &LimitError{Limit: "token count", Max: c.maxTokens})
+	}
+	if c.maxOperators <= 0 {
+		return nil
+	}
+	operators := 0
+	for i, token := range tokens {
+		if c.isBinaryOperator(token) || token == "!" || token == "~" || token == "?" || c.isFunction(token) {
+			operators++
+		}
+		if operators > c.maxOperators {
+			return c.errorAt(spans, i, "%w", &LimitError{Limit: "operator count", Max: c.maxOperators})
+		}
+	}
+	return nil
+}
+
+// bounded reports whether an evaluation has limits or a context to check,
+// which keep it from taking a shortcut past them.
+func (c config) bounded() bool {
+	return c.maxTokens > 0 || c.maxOperators > 0 || c.maxSteps > 0 || c.ctx != nil
+}
+
+// step returns the error that stops an evaluation before its step i: the
+// error of the context of EvaluateContext, or once a limit set by
+// WithMaxSteps is reached, a *TokenError for the postfix token at index.
+func (c config) step(i, index int) error {
+	if c.maxSteps > 0 && i >= c.maxSteps {
+		return &TokenError{Index: index, Err: &LimitError{Limit: "evaluation steps", Max: c.maxSteps}}
+	}
+	return c.interrupted(i)
+}
@@ -0,0 +1,113 @@
+package shuntingyard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   string // empty when within the limits
+		limit      string
+		index      int
+	}{
+		{name: "tokens within limit", expression: "1 + 2 * 3", opts: []Option{WithMaxTokens(5)}},
+		{name: "too many tokens", expression: "1 + 2 * 3 - 4", opts: []Option{WithMaxTokens(5)}, expected: "token count exceeds limit of 5", limit: "token count"},
+		{name: "operators within limit", expression: "-max(1, 2) + 3", opts: []Option{WithMaxOperators(3)}},
+		{name: "too many operators", expression: "1 + 2 * 3 - 4", opts: []Option{WithMaxOperators(2)}, expected: "operator count exceeds limit of 2 at token 5", limit: "operator count", index: 5},
+		{name: "function calls count as operators", expression: "sqrt(abs(x))", opts: []Option{WithMaxOperators(1)}, expected: "operator count exceeds limit of 1 at token 2", limit: "operator count", index: 2},
+		{name: "nesting too deep", expression: "((1))", opts: []Option{WithMaxDepth(1)}, expected: "nesting depth exceeds limit of 1 at token 1", limit: "nesting depth", index: 1},
+		{name: "steps within limit", expression: "1 + 2 * 3", opts: []Option{WithMaxSteps(5)}},
+		{name: "too many steps", expression: "1 + 2 * 3", opts: []Option{WithMaxSteps(4)}, expected: "evaluation steps exceeds limit of 4", limit: "evaluation steps", index: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evalLimited(tt.expression, tt.opts)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var limitErr *LimitError
+			if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &limitErr) {
+				t.Fatalf("error = %v, expected a *LimitError", err)
+			}
+			if err.Error() != tt.expected || limitErr.Limit != tt.limit {
+				t.Errorf("error = %q (%s), expected %q (%s)", err, limitErr.Limit, tt.expected, tt.limit)
+			}
+			var parseErr *ParseError
+			var tokenErr *TokenError
+			switch {
+			case errors.As(err, &parseErr) && parseErr.Index != tt.index:
+				t.Errorf("ParseError.Index = %d, expected %d", parseErr.Index, tt.index)
+			case errors.As(err, &tokenErr) && tokenErr.Index != tt.index:
+				t.Errorf("TokenError.Index = %d, expected %d", tokenErr.Index, tt.index)
+			}
+		})
+	}
+}
+
+func TestLimitsCompiled(t *testing.T) {
+	expr, err := Compile("x * 2 + 1", WithMaxSteps(4))
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	if _, err := expr.Eval(map[string]float64{"x": 1}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Eval() error = %v, expected ErrLimitExceeded", err)
+	}
+
+	if _, err := CompileFunc("x * 2 + 1", WithMaxSteps(4)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("CompileFunc() error = %v, expected ErrLimitExceeded", err)
+	}
+	fn, err := CompileFunc("x * 2 + 1", WithMaxSteps(5))
+	if err != nil {
+		t.Fatalf("CompileFunc() unexpected error: %v", err)
+	}
+	if result, err := fn(map[string]float64{"x": 1}); err != nil || result != 3 {
+		t.Errorf("fn() = %v, %v, expected 3", result, err)
+	}
+}
+
+// TestLimitsFastPath checks the entry points that evaluate a single
+// operation on two literals without parsing it.
+func TestLimitsFastPath(t *testing.T) {
+	for _, opts := range [][]Option{{WithMaxSteps(1)}, {WithMaxTokens(2)}} {
+		if _, err := EvalInt("6 * 7", opts...); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("EvalInt() error = %v, expected ErrLimitExceeded", err)
+		}
+		var lineErr error
+		EvalLines(strings.NewReader("6 * 7"), func(_ int, _ float64, err error) { lineErr = err }, opts...)
+		if !errors.Is(lineErr, ErrLimitExceeded) {
+			t.Errorf("EvalLines() error = %v, expected ErrLimitExceeded", lineErr)
+		}
+	}
+}
+
+// evalLimited scans, parses and evaluates an expression, passing opts to
+// every stage.
+func evalLimited(expression string, opts []Option) (float64, error) {
+	tokens, err := Scan(expression, opts...)
+	if err != nil {
+		return 0, err
+	}
+	postfix, err := Parse(tokens, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return Evaluate(postfix, opts...)
+}
+
+func TestParseMaxTokens(t *testing.T) {
+	_, err := Parse([]string{"1", "+", "2", "+", "3"}, WithMaxTokens(3))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Index != 3 || err.Error() != "token count exceeds limit of 3 at token 3" {
+		t.Errorf("Parse() error = %v, expected the token count limit at token 3", err)
+	}
+}
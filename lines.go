@@ -0,0 +1,34 @@
+package shuntingyard
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// EvalLines reads r line by line and evaluates each line as a separate infix
+// expression, calling handler with the 1-based line number and either the result
+// or the error for that line. Blank lines are skipped but still counted, so line
+// numbers always match the input. Only one line is held in memory at a time.
+//
+// A failing expression does not stop processing; EvalLines only returns an error
+// if reading from r fails, including lines longer than bufio.MaxScanTokenSize.
+// opts are passed to every stage of the pipeline.
+func EvalLines(r io.Reader, handler func(line int, result float64, err error), opts ...Option) error {
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		result, err := eval(text, opts...)
+		handler(line, result, err)
+	}
+
+	return scanner.Err()
+}
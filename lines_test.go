@@ -0,0 +1,60 @@
+package shuntingyard
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestEvalLines tests evaluating one expression per line from a reader
+func TestEvalLines(t *testing.T) {
+	input := "2 + 3\n10 / 0\n\n(2 + 3) * 4\r\n  \n1.5 + 2.5"
+
+	type lineResult struct {
+		line    int
+		result  float64
+		wantErr bool
+	}
+	expected := []lineResult{
+		{line: 1, result: 5.0},
+		{line: 2, wantErr: true},
+		{line: 4, result: 20.0},
+		{line: 6, result: 4.0},
+	}
+
+	var got []lineResult
+	err := EvalLines(strings.NewReader(input), func(line int, result float64, err error) {
+		got = append(got, lineResult{line: line, result: result, wantErr: err != nil})
+	})
+	if err != nil {
+		t.Fatalf("EvalLines() unexpected error: %v", err)
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("EvalLines() handled %d lines, expected %d: %v", len(got), len(expected), got)
+	}
+
+	for i, g := range got {
+		e := expected[i]
+		if g.line != e.line {
+			t.Errorf("EvalLines() call[%d] line = %d, expected %d", i, g.line, e.line)
+		}
+		if g.wantErr != e.wantErr {
+			t.Errorf("EvalLines() line %d error = %v, expected error %v", g.line, g.wantErr, e.wantErr)
+		}
+		if !e.wantErr && !almostEqual(g.result, e.result, 0.0000001) {
+			t.Errorf("EvalLines() line %d = %v, expected %v", g.line, g.result, e.result)
+		}
+	}
+}
+
+// TestEvalLinesTooLong tests that oversized lines are reported as a read error
+func TestEvalLinesTooLong(t *testing.T) {
+	input := strings.Repeat("1+", bufio.MaxScanTokenSize) + "1"
+
+	err := EvalLines(strings.NewReader(input), func(int, float64, error) {})
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("EvalLines() error = %v, expected bufio.ErrTooLong", err)
+	}
+}
@@ -0,0 +1,103 @@
+package shuntingyard
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// NumberFormat describes how a locale writes numbers, for expressions pasted
+// from spreadsheets: German writes 1234.56 as "1.234,56", French as
+// "1 234,56", and Swiss German as "1'234.56". A zero field keeps the default.
+type NumberFormat struct {
+	Decimal   rune // default '.'
+	Thousands rune // default none
+}
+
+// WithNumberFormat makes Scan read numbers written in format, so that
+// "1.234,56 + 1" with NumberFormat{Decimal: ',', Thousands: '.'} is 1235.56.
+// Thousands separators must split the integer part into groups of three
+// digits. A space as the thousands separator also matches the no-break
+// spaces spreadsheets put between groups. Scan translates such a number to the
+// default notation, as in "1234.56", so Parse and Evaluate need no further
+// configuration.
+//
+// When either separator is a comma, function arguments are separated by
+// semicolons instead, as in "max(1,5; 2)", and a comma outside a number is an
+// invalid character.
+func WithNumberFormat(format NumberFormat) Option {
+	return func(c *config) {
+		c.numbers = format
+		if c.numbers.Decimal == 0 {
+			c.numbers.Decimal = '.'
+		}
+	}
+}
+
+// localized reports whether numbers are read in a format other than the
+// default.
+func (f NumberFormat) localized() bool {
+	return f.Decimal != 0
+}
+
+// commaSeparated reports whether a comma belongs to numbers, so that
+// semicolons separate function arguments.
+func (f NumberFormat) commaSeparated() bool {
+	return f.Decimal == ',' || f.Thousands == ','
+}
+
+// thousandsAt returns the length of the thousands separator s starts with, or
+// 0 if it doesn't start with one.
+func (f NumberFormat) thousandsAt(s string) int {
+	if f.Thousands == 0 || s == "" {
+		return 0
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	if r == f.Thousands || f.Thousands == ' ' && (r == '\u00a0' || r == '\u202f') {
+		return n
+	}
+	return 0
+}
+
+// scan reads the number s starts with, returning it in the default notation
+// and its length in the source, or n == 0 if s doesn't start with a number.
+func (f NumberFormat) scan(s string) (token string, n int) {
+	var b strings.Builder
+	digits := func() int {
+		start := n
+		for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+			n++
+		}
+		b.WriteString(s[start:n])
+		return n - start
+	}
+
+	// The integer part, whose groups of three are each introduced by a
+	// separator
+	if integer := digits(); integer > 0 && integer <= 3 {
+		for sep := f.thousandsAt(s[n:]); sep > 0; sep = f.thousandsAt(s[n:]) {
+			group := n + sep
+			if group+3 > len(s) || strings.Trim(s[group:group+3], "0123456789") != "" ||
+				group+3 < len(s) && '0' <= s[group+3] && s[group+3] <= '9' {
+				break
+			}
+			b.WriteString(s[group : group+3])
+			n = group + 3
+		}
+	}
+
+	// The decimal separator needs a digit after it, so that "max(1, 2)"
+	// doesn't read as a number wherever a comma separates decimals
+	decimal := n + utf8.RuneLen(f.Decimal)
+	if strings.HasPrefix(s[n:], string(f.Decimal)) && decimal < len(s) && '0' <= s[decimal] && s[decimal] <= '9' {
+		n = decimal
+		b.WriteByte('.')
+		digits()
+	}
+	if n == 0 {
+		return "", 0
+	}
+
+	exponent := exponentLength(s[n:])
+	b.WriteString(s[n : n+exponent])
+	return b.String(), n + exponent
+}
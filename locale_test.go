@@ -0,0 +1,77 @@
+package shuntingyard
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWithNumberFormat(t *testing.T) {
+	german := NumberFormat{Decimal: ',', Thousands: '.'}
+	french := NumberFormat{Decimal: ',', Thousands: ' '}
+	swiss := NumberFormat{Thousands: '\''}
+
+	tests := []struct {
+		name       string
+		expression string
+		format     NumberFormat
+		expected   []string
+		wantErr    bool
+	}{
+		{name: "decimal comma and thousands dot", expression: "1.234,56 + 1", format: german, expected: []string{"1234.56", "+", "1"}},
+		{name: "several groups", expression: "12.345.678", format: german, expected: []string{"12345678"}},
+		{name: "thousands space", expression: "1 234,5 * 2", format: french, expected: []string{"1234.5", "*", "2"}},
+		{name: "narrow no-break space", expression: "1\u202f000 + 1\u00a0000", format: french, expected: []string{"1000", "+", "1000"}},
+		{name: "space between numbers", expression: "2 * 3 0", format: french, expected: []string{"2", "*", "3", "0"}},
+		{name: "apostrophe with decimal point", expression: "1'234.5-0.5", format: swiss, expected: []string{"1234.5", "-", "0.5"}},
+		{name: "semicolon separates arguments", expression: "max(1,5; 2,5)", format: german, expected: []string{"max", "(", "1.5", ",", "2.5", ")"}},
+		{name: "comma in thousands keeps semicolons", expression: "sum(1,000; 2)", format: NumberFormat{Thousands: ','}, expected: []string{"sum", "(", "1000", ",", "2", ")"}},
+		{name: "leading decimal comma", expression: ",5", format: german, expected: []string{".5"}},
+		{name: "exponent", expression: "1,5e3", format: german, expected: []string{"1.5e3"}},
+		{name: "hexadecimal", expression: "0xFF + 1", format: german, expected: []string{"255", "+", "1"}},
+		{name: "uncertainty", expression: "9,81 ± 0,02", format: german, expected: []string{"9.81 ± 0.02"}},
+		{name: "default format", expression: "max(1.5, 2)", expected: []string{"max", "(", "1.5", ",", "2", ")"}},
+
+		// Error cases
+		{name: "decimal point in comma locale", expression: "1.5 + 1", format: german, wantErr: true},
+		{name: "short group", expression: "1.23,4", format: german, wantErr: true},
+		{name: "long group", expression: "1.2345", format: german, wantErr: true},
+		{name: "second decimal comma", expression: "1,5,3", format: german, wantErr: true},
+		{name: "comma separating arguments", expression: "max(1, 2)", format: german, wantErr: true},
+		{name: "lone decimal comma", expression: "1 + ,", format: german, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.format != (NumberFormat{}) {
+				opts = append(opts, WithNumberFormat(tt.format))
+			}
+			tokens, err := Scan(tt.expression, opts...)
+
+			if tt.wantErr {
+				var charErr *InvalidCharError
+				if !errors.As(err, &charErr) {
+					t.Errorf("Scan() error = %v, expected *InvalidCharError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			if !slices.Equal(tokens, tt.expected) {
+				t.Errorf("Scan() = %q, expected %q", tokens, tt.expected)
+			}
+		})
+	}
+
+	t.Run("evaluates translated numbers", func(t *testing.T) {
+		result, err := eval("1.234,56 + max(0,44; 0,2)", WithNumberFormat(german))
+		if err != nil {
+			t.Fatalf("eval() unexpected error: %v", err)
+		}
+		if result != 1235 {
+			t.Errorf("eval() = %v, expected 1235", result)
+		}
+	})
+}
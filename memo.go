@@ -0,0 +1,153 @@
+package shuntingyard
+
+import "strings"
+
+// memo is a cache slot for the value of a repeated subexpression during one
+// run of a program.
+type memo struct {
+	value float64
+	set   bool
+}
+
+// sharing is the plan for computing the repeated subexpressions of a postfix
+// expression once, each numbered by a cache slot in the order evaluation
+// first reaches it.
+type sharing struct {
+	// roots[i] is the slot of the subexpression ending at token i, or -1,
+	// and starts[i] the last tokens of those beginning at token i, longest
+	// first
+	roots  []int
+	starts [][]int
+	// first is the last token of the first occurrence of each slot, and
+	// hoisted whether that occurrence is always evaluated, so that the
+	// others can take its value without checking it was computed
+	first   []int
+	hoisted []bool
+}
+
+// share finds the subexpressions of a postfix expression that occur more than
+// once, such as the "sqrt(x*x+y*y)" of a formula that refers to a distance
+// three times, and plans their sharing. Single operands, and operators applied
+// to them alone, cost less to compute than to cache and are left out. It
+// returns nil for expressions without repeats.
+func (c config) share(postfix []string) *sharing {
+	// conditional marks the tokens in a branch of a conditional or the right
+	// operand of a "&&" or "||", which evaluation may skip, by counting the
+	// regions each token starts or ends
+	conditional := make([]int, len(postfix)+1)
+	start, ok := c.operands(postfix, func(i int, operands []int, _ []int) {
+		if postfix[i] == ternary || postfix[i] == "&&" || postfix[i] == "||" {
+			conditional[operands[0]+1]++
+			conditional[i]--
+		}
+	})
+	if !ok {
+		return nil
+	}
+	for i := 1; i < len(conditional); i++ {
+		conditional[i] += conditional[i-1]
+	}
+
+	// Postfix is unambiguous, so equal token sequences are equal trees
+	key := func(i int) string { return strings.Join(postfix[start[i]:i+1], "\x00") }
+	worthwhile := func(i int) bool { return i-start[i] >= 2 || i > start[i] && c.isCall(postfix[i]) }
+
+	occurrences := make(map[string][]int)
+	for i := range postfix {
+		if worthwhile(i) {
+			occurrences[key(i)] = append(occurrences[key(i)], i)
+		}
+	}
+	// The tokens within an occurrence after the first of a repeated
+	// subexpression are never evaluated, so that subexpressions recurring
+	// only there, such as the "x*x" of a repeated "sqrt(x*x + y*y)", are
+	// not worth sharing
+	skipped := make([]int, len(postfix)+1)
+	for _, roots := range occurrences {
+		if len(roots) < 2 {
+			continue
+		}
+		for _, i := range roots[1:] {
+			skipped[start[i]]++
+			skipped[i]--
+		}
+	}
+	for i := 1; i < len(skipped); i++ {
+		skipped[i] += skipped[i-1]
+	}
+	counts := make(map[string]int)
+	for i := range postfix {
+		if worthwhile(i) && skipped[i] == 0 {
+			counts[key(i)]++
+		}
+	}
+
+	s := &sharing{roots: make([]int, len(postfix)), starts: make([][]int, len(postfix))}
+	slots := make(map[string]int)
+	for i := range postfix {
+		s.roots[i] = -1
+		if !worthwhile(i) || counts[key(i)] < 2 || skipped[i] > 0 {
+			continue
+		}
+		slot, ok := slots[key(i)]
+		if !ok {
+			slot = len(slots)
+			slots[key(i)] = slot
+			s.first = append(s.first, i)
+			s.hoisted = append(s.hoisted, conditional[i] == 0)
+		}
+		s.roots[i] = slot
+	}
+	if len(slots) == 0 {
+		return nil
+	}
+	for i := len(postfix) - 1; i >= 0; i-- {
+		if s.roots[i] >= 0 {
+			s.starts[start[i]] = append(s.starts[start[i]], i)
+		}
+	}
+	return s
+}
+
+// Shared returns the subexpressions that Eval computes once and reuses
+// wherever they recur, as written in the source, in the order they are first
+// computed, so that users can see what compilation hoisted:
+//
+//	expr, _ := Compile("sqrt(x*x + y*y) / (1 + sqrt(x*x + y*y))")
+//	expr.Shared() // ["sqrt(x*x + y*y)"]
+//
+// A subexpression that recurs only within another shared one, as "x*x" does
+// here, is computed once with it and not listed. Returns nil when nothing is
+// shared.
+func (e *Expression) Shared() []string {
+	if len(e.program.shared) == 0 {
+		return nil
+	}
+	tokens, spans, err := scan(e.source, e.cfg, true)
+	if err != nil {
+		return nil
+	}
+	postfix, spans, err := parse(tokens, spans, e.cfg)
+	if err != nil {
+		return nil
+	}
+	start, _ := e.cfg.operands(postfix, nil)
+
+	shared := make([]string, len(e.program.shared))
+	for slot, root := range e.program.shared {
+		first, end := len(e.source), 0
+		for _, span := range spans[start[root] : root+1] {
+			first = min(first, span.Start)
+			end = max(end, span.End)
+		}
+		// A call's span is its name; the parentheses closing its
+		// arguments follow the last of them
+		for open := strings.Count(e.source[first:end], "(") - strings.Count(e.source[first:end], ")"); open > 0 && end < len(e.source); end++ {
+			if e.source[end] == ')' {
+				open--
+			}
+		}
+		shared[slot] = e.source[first:end]
+	}
+	return shared
+}
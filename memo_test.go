@@ -0,0 +1,130 @@
+package shuntingyard
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpressionMemoization(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		vars       map[string]float64
+		calls      int
+	}{
+		{name: "repeated call", expression: "tick(x) * 2 + tick(x) * 3", vars: map[string]float64{"x": 2}, calls: 1},
+		{name: "repeated subtree", expression: "tick(x * x + y * y) / sqrt(x * x + y * y) + tick(x * x + y * y)", vars: map[string]float64{"x": 3, "y": 4}, calls: 1},
+		{name: "different arguments", expression: "tick(x) + tick(y)", vars: map[string]float64{"x": 1, "y": 2}, calls: 2},
+		{name: "nested repeats", expression: "tick(tick(x) + 1) + tick(tick(x) + 1) + tick(x)", vars: map[string]float64{"x": 1}, calls: 2},
+		{name: "first occurrence skipped", expression: "(x > 5 ? tick(x) : 0) + tick(x)", vars: map[string]float64{"x": 1}, calls: 1},
+		{name: "occurrence in both branches", expression: "x > 0 ? tick(x) : -tick(x)", vars: map[string]float64{"x": 1}, calls: 1},
+		{name: "cache across operators", expression: "tick(x) > 0 && tick(x) < 10 || tick(x) == 0", vars: map[string]float64{"x": 4}, calls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tick := WithFunction("tick", 1, func(args ...float64) (float64, error) {
+				calls++
+				return args[0], nil
+			})
+			expr, err := Compile(tt.expression, tick)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			expected, err := EvaluateWithVars(expr.postfix, tt.vars, tick)
+			if err != nil {
+				t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+			}
+
+			// Each run computes the repeats afresh
+			for range 2 {
+				calls = 0
+				result, err := expr.Eval(tt.vars)
+				if err != nil {
+					t.Fatalf("Eval() unexpected error: %v", err)
+				}
+				if result != expected || calls != tt.calls {
+					t.Errorf("Eval() = %v with %d calls, expected %v with %d", result, calls, expected, tt.calls)
+				}
+			}
+		})
+	}
+}
+
+func TestShare(t *testing.T) {
+	tests := []struct {
+		name    string
+		postfix []string
+		slots   int
+	}{
+		{name: "no repeats", postfix: []string{"x", "y", "*", "x", "+"}},
+		{name: "repeated operands are not cached", postfix: []string{"x", "neg", "x", "neg", "*"}},
+		{name: "repeated product", postfix: []string{"x", "y", "*", "x", "y", "*", "+"}, slots: 1},
+		{name: "repeated call", postfix: []string{"x", "1", "+", "sqrt/1", "x", "1", "+", "sqrt/1", "/"}, slots: 1},
+		{name: "argument also used alone", postfix: []string{"x", "1", "+", "sqrt/1", "x", "1", "+", "sqrt/1", "/", "x", "1", "+", "*"}, slots: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots := 0
+			if shared := newConfig(nil).share(tt.postfix); shared != nil {
+				slots = len(shared.first)
+			}
+			if slots != tt.slots {
+				t.Errorf("share() = %d slots, expected %d", slots, tt.slots)
+			}
+		})
+	}
+}
+
+func TestExpressionShared(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   []string
+	}{
+		{name: "nothing shared", expression: "x * y + x"},
+		{name: "distance", expression: "sqrt(x*x + y*y) / (1 + sqrt(x*x + y*y))", expected: []string{"sqrt(x*x + y*y)"}},
+		{name: "nested calls", expression: "max(abs(x - 1), 2) * max(abs(x - 1), 2)", expected: []string{"max(abs(x - 1), 2)"}},
+		{name: "inner subexpression recurring alone", expression: "sqrt(x*x + 1) + sqrt(x*x + 1) + x*x", expected: []string{"x*x", "sqrt(x*x + 1)"}},
+		{name: "conditional occurrence", expression: "x > 0 ? (x + 1) * 2 : (x + 1) * 3", expected: []string{"x + 1"}},
+		{name: "folded constants", expression: "x*(2*3+1) - x*(2*3+1)", expected: []string{"x*(2*3+1)"}},
+		{name: "multi-line", expression: "(a + b) *\n  (a + b)", expected: []string{"a + b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			if shared := expr.Shared(); !slices.Equal(shared, tt.expected) {
+				t.Errorf("Shared() = %q, expected %q", shared, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpressionSharedHoisting(t *testing.T) {
+	// The first occurrence always runs, so the second is compiled to a load
+	expr, err := Compile("(x + 1) * 2 + (x + 1) * 3")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	hasOp := func(op opcode) bool {
+		return slices.ContainsFunc(expr.program.code, func(in instruction) bool { return in.op == op })
+	}
+	if !hasOp(opLoad) || hasOp(opMemo) {
+		t.Errorf("program = %v, expected a load and no memo", expr.program.code)
+	}
+
+	// Here it may be skipped, so each occurrence checks the cache
+	expr, err = Compile("x > 0 ? (x + 1) * 2 : 0 + (x + 1) * 3")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	if hasOp(opLoad) || !hasOp(opMemo) {
+		t.Errorf("program = %v, expected memos and no load", expr.program.code)
+	}
+}
@@ -0,0 +1,74 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNonFinite is wrapped by the error for an operation whose result is NaN or
+// infinite under WithNonFiniteError.
+var ErrNonFinite = errors.New("non-finite result")
+
+// nonFinitePolicy is what evaluation does with NaN and infinite results.
+type nonFinitePolicy int
+
+const (
+	// nonFinitePropagate keeps them, as IEEE 754 arithmetic does
+	nonFinitePropagate nonFinitePolicy = iota
+	nonFiniteError
+	nonFiniteDefault
+)
+
+// WithNonFiniteError makes an operation whose result is NaN or infinite, such
+// as "log(-1)", "0 / 0 * 0" or an overflowing "10 ^ 400", fail the evaluation
+// with a *TokenError wrapping ErrNonFinite, rather than pass the IEEE 754
+// value on to the operations around it, as by default. NaN and infinite
+// operands, from literals such as "inf" or from variables, are accepted, but
+// an operation they make non-finite fails. It applies to the float64 and
+// float32 evaluators, compiled expressions included.
+func WithNonFiniteError() Option {
+	return func(c *config) {
+		c.nonFinite = nonFiniteError
+	}
+}
+
+// WithNonFiniteDefault makes an operation whose result is NaN or infinite
+// yield value instead, as WithNonFiniteError would otherwise fail it: with 0,
+// "log(-1) + 1" is 1.
+func WithNonFiniteDefault(value float64) Option {
+	return func(c *config) {
+		c.nonFinite = nonFiniteDefault
+		c.nonFiniteDefault = value
+	}
+}
+
+// finiteArithmetic is implemented by arithmetics whose numbers include NaN
+// and infinities, for WithNonFiniteError and WithNonFiniteDefault.
+type finiteArithmetic[T any] interface {
+	isFinite(a T) bool
+	fromFloat(value float64) T
+}
+
+// checkFinite applies the policy of cfg to the result of an operation.
+func checkFinite[T any](arith finiteArithmetic[T], result T, cfg config) (T, error) {
+	if cfg.nonFinite == nonFinitePropagate || arith == nil || arith.isFinite(result) {
+		return result, nil
+	}
+	if cfg.nonFinite == nonFiniteError {
+		return result, fmt.Errorf("%w %v", ErrNonFinite, result)
+	}
+	return arith.fromFloat(cfg.nonFiniteDefault), nil
+}
+
+func (floatArithmetic) isFinite(a float64) bool {
+	return !math.IsNaN(a) && !math.IsInf(a, 0)
+}
+
+func (floatArithmetic) fromFloat(value float64) float64 { return value }
+
+func (float32Arithmetic) isFinite(a float32) bool {
+	return !math.IsNaN(float64(a)) && !math.IsInf(float64(a), 0)
+}
+
+func (float32Arithmetic) fromFloat(value float64) float32 { return float32(value) }
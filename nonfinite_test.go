@@ -0,0 +1,129 @@
+package shuntingyard
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNonFinitePolicy(t *testing.T) {
+	vars := map[string]float64{"x": -1, "big": 400, "huge": math.Inf(1)}
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   float64
+		wantErr    string // empty when the expression evaluates
+		index      int
+	}{
+		{name: "NaN propagates by default", expression: "log(x) + 1", expected: math.NaN()},
+		{name: "overflow propagates by default", expression: "10 ^ big", expected: math.Inf(1)},
+		{name: "NaN fails", expression: "log(x) + 1", opts: []Option{WithNonFiniteError()}, wantErr: "non-finite result NaN", index: 1},
+		{name: "overflow fails", expression: "2 * 10 ^ big", opts: []Option{WithNonFiniteError()}, wantErr: "non-finite result +Inf", index: 3},
+		{name: "constant overflow fails", expression: "10 ^ 400 - 1", opts: []Option{WithNonFiniteError()}, wantErr: "non-finite result +Inf", index: 2},
+		{name: "finite results pass", expression: "sqrt(-x) + 1", opts: []Option{WithNonFiniteError()}, expected: 2},
+		{name: "infinite operands pass", expression: "huge", opts: []Option{WithNonFiniteError()}, expected: math.Inf(1)},
+		{name: "operations on infinite operands fail", expression: "huge - huge", opts: []Option{WithNonFiniteError()}, wantErr: "non-finite result NaN", index: 2},
+		{name: "NaN replaced", expression: "log(x) + 1", opts: []Option{WithNonFiniteDefault(0)}, expected: 1},
+		{name: "overflow replaced", expression: "10 ^ big / 2", opts: []Option{WithNonFiniteDefault(8)}, expected: 4},
+		{name: "last option wins", expression: "log(x)", opts: []Option{WithNonFiniteError(), WithNonFiniteDefault(-1)}, expected: -1},
+		{name: "skipped branch not evaluated", expression: "x < 0 ? 0 : log(x)", opts: []Option{WithNonFiniteError()}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			expr, err := Compile(tt.expression, opts...)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			fn, err := CompileFunc(tt.expression, opts...)
+			if err != nil {
+				t.Fatalf("CompileFunc() unexpected error: %v", err)
+			}
+
+			evaluators := map[string]func() (float64, error){
+				"Evaluate": func() (float64, error) {
+					tokens, err := Scan(tt.expression, opts...)
+					if err != nil {
+						return 0, err
+					}
+					postfix, err := Parse(tokens, opts...)
+					if err != nil {
+						return 0, err
+					}
+					return EvaluateWithVars(postfix, vars, opts...)
+				},
+				"Eval":        func() (float64, error) { return expr.Eval(vars) },
+				"CompileFunc": func() (float64, error) { return fn(vars) },
+			}
+			for name, evaluate := range evaluators {
+				result, err := evaluate()
+				if tt.wantErr != "" {
+					var tokenErr *TokenError
+					if !errors.Is(err, ErrNonFinite) || !errors.As(err, &tokenErr) {
+						t.Fatalf("%s() error = %v, expected a *TokenError wrapping ErrNonFinite", name, err)
+					}
+					if err.Error() != tt.wantErr || tokenErr.Index != tt.index {
+						t.Errorf("%s() error = %q at %d, expected %q at %d", name, err, tokenErr.Index, tt.wantErr, tt.index)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s() unexpected error: %v", name, err)
+				}
+				if result != tt.expected && !(math.IsNaN(result) && math.IsNaN(tt.expected)) {
+					t.Errorf("%s() = %v, expected %v", name, result, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestNonFiniteFloat32(t *testing.T) {
+	// 1e30 squared overflows single precision but not double
+	postfix := []string{"1e30", "1e30", "*"}
+	if _, err := EvaluateFloat32(postfix, WithNonFiniteError()); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("EvaluateFloat32() error = %v, expected ErrNonFinite", err)
+	}
+	result, err := EvaluateFloat32(postfix, WithNonFiniteDefault(math.MaxFloat32))
+	if err != nil || result != math.MaxFloat32 {
+		t.Errorf("EvaluateFloat32() = %v, %v, expected %v", result, err, float32(math.MaxFloat32))
+	}
+}
+
+// TestNonFiniteFastPath checks the entry points that evaluate a single
+// operation on two literals without parsing it.
+func TestNonFiniteFastPath(t *testing.T) {
+	opts := []Option{WithNonFiniteError()}
+	evaluator := NewEvaluator(EvaluatorConfig{Workers: 1, Options: opts})
+	defer evaluator.Shutdown(context.Background())
+
+	entryPoints := map[string]func(expression string) (float64, error){
+		"EvalInt": func(expression string) (float64, error) {
+			result, err := EvalInt(expression, opts...)
+			return float64(result), err
+		},
+		"EvalLines": func(expression string) (result float64, err error) {
+			EvalLines(strings.NewReader(expression), func(_ int, r float64, e error) {
+				result, err = r, e
+			}, opts...)
+			return result, err
+		},
+		"Evaluator.Submit": func(expression string) (float64, error) {
+			return evaluator.Submit(context.Background(), expression)
+		},
+	}
+	for name, eval := range entryPoints {
+		if _, err := eval("2 ^ 2000"); !errors.Is(err, ErrNonFinite) {
+			t.Errorf("%s() error = %v, expected ErrNonFinite", name, err)
+		}
+	}
+
+	// Like an infinite variable, an infinite literal is no operation to fail
+	if result, err := entryPoints["EvalLines"]("inf"); err != nil || !math.IsInf(result, 1) {
+		t.Errorf("EvalLines() = %v, %v, expected +Inf", result, err)
+	}
+}
@@ -0,0 +1,56 @@
+package shuntingyard
+
+// Number is the set of types EvaluateAs evaluates into.
+type Number interface {
+	float32 | float64 | int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64
+}
+
+// EvaluateAs computes the result of a postfix (RPN) expression directly in the
+// numeric type T, with the arithmetic that suits it:
+//
+//	n, err := EvaluateAs[uint8](postfix)
+//
+// float64 evaluates as Evaluate does and float32 as EvaluateFloat32.
+// Integer types evaluate as EvaluateInt does, in their own width: literals
+// and variables must be integers that fit T, and every intermediate result
+// that leaves T's range, such as "200 + 100" for uint8 or "3 - 5" for any
+// unsigned type, is an overflow error rather than wrapping around. Function
+// arguments and results of unsigned 64-bit types must also fit an int64.
+//
+// Returns the result or an error as described for the evaluator of T.
+func EvaluateAs[T Number](postfixTokens []string, opts ...Option) (T, error) {
+	cfg := newConfig(opts)
+
+	var result any
+	var err error
+	switch any(T(0)).(type) {
+	case float64:
+		result, err = evaluate(postfixTokens, cfg)
+	case float32:
+		result, err = evaluateWith(postfixTokens, Arithmetic[float32](float32Arithmetic{}), cfg)
+	case int:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[int](&cfg), cfg)
+	case int8:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[int8](&cfg), cfg)
+	case int16:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[int16](&cfg), cfg)
+	case int32:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[int32](&cfg), cfg)
+	case int64:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[int64](&cfg), cfg)
+	case uint:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[uint](&cfg), cfg)
+	case uint8:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[uint8](&cfg), cfg)
+	case uint16:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[uint16](&cfg), cfg)
+	case uint32:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[uint32](&cfg), cfg)
+	case uint64:
+		result, err = evaluateWith(postfixTokens, newIntegerArithmetic[uint64](&cfg), cfg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.(T), nil
+}
@@ -0,0 +1,102 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// evaluateAs runs the full pipeline into T, rendering the result for comparison
+func evaluateAs[T Number](expression string, opts ...Option) (string, error) {
+	tokens, err := Scan(expression, opts...)
+	if err != nil {
+		return "", err
+	}
+	postfix, err := Parse(tokens, opts...)
+	if err != nil {
+		return "", err
+	}
+	result, err := EvaluateAs[T](postfix, opts...)
+	return fmt.Sprint(result), err
+}
+
+// TestEvaluateAs tests evaluating directly into each numeric type
+func TestEvaluateAs(t *testing.T) {
+	tests := []struct {
+		name     string
+		eval     func() (string, error)
+		expected string
+		wantErr  bool
+	}{
+		{name: "float64", eval: func() (string, error) { return evaluateAs[float64]("0.1 + 0.2") }, expected: "0.30000000000000004"},
+		{name: "float32", eval: func() (string, error) { return evaluateAs[float32]("0.1 + 0.2") }, expected: "0.3"},
+		{name: "float32 overflows to infinity", eval: func() (string, error) { return evaluateAs[float32]("300000000000000000000000000000000000000 * 10") }, expected: "+Inf"},
+		{name: "int", eval: func() (string, error) { return evaluateAs[int]("2 + 3 * 4") }, expected: "14"},
+		{name: "int8 literal out of range", eval: func() (string, error) { return evaluateAs[int8]("128 - 1") }, wantErr: true},
+		{name: "int8 in range", eval: func() (string, error) { return evaluateAs[int8]("-100 - 28") }, expected: "-128"},
+		{name: "int8 overflow", eval: func() (string, error) { return evaluateAs[int8]("100 + 28") }, wantErr: true},
+		{name: "int8 intermediate overflow", eval: func() (string, error) { return evaluateAs[int8]("100 + 100 - 150") }, wantErr: true},
+		{name: "int8 negation overflow", eval: func() (string, error) { return evaluateAs[int8]("-(-127 - 1)") }, wantErr: true},
+		{name: "int8 quotient overflow", eval: func() (string, error) { return evaluateAs[int8]("(-127 - 1) / -1") }, wantErr: true},
+		{name: "int8 product overflow", eval: func() (string, error) { return evaluateAs[int8]("(-127 - 1) * -1") }, wantErr: true},
+		{name: "int16 power", eval: func() (string, error) { return evaluateAs[int16]("2 ^ 14") }, expected: "16384"},
+		{name: "int16 power overflow", eval: func() (string, error) { return evaluateAs[int16]("2 ^ 15") }, wantErr: true},
+		{name: "int32 shift", eval: func() (string, error) { return evaluateAs[int32]("1 << 30") }, expected: "1073741824"},
+		{name: "int32 shift overflow", eval: func() (string, error) { return evaluateAs[int32]("1 << 31") }, wantErr: true},
+		{name: "int64", eval: func() (string, error) { return evaluateAs[int64]("9223372036854775806 + 1") }, expected: "9223372036854775807"},
+		{name: "uint8", eval: func() (string, error) { return evaluateAs[uint8]("200 + 55") }, expected: "255"},
+		{name: "uint8 overflow", eval: func() (string, error) { return evaluateAs[uint8]("200 + 56") }, wantErr: true},
+		{name: "uint8 literal out of range", eval: func() (string, error) { return evaluateAs[uint8]("256 - 1") }, wantErr: true},
+		{name: "unsigned below zero", eval: func() (string, error) { return evaluateAs[uint16]("3 - 5") }, wantErr: true},
+		{name: "unsigned negation of zero", eval: func() (string, error) { return evaluateAs[uint32]("-0 + 1") }, expected: "1"},
+		{name: "unsigned negation", eval: func() (string, error) { return evaluateAs[uint32]("-1 + 2") }, wantErr: true},
+		{name: "uint64 beyond int64", eval: func() (string, error) { return evaluateAs[uint64]("9223372036854775807 * 2 + 1") }, expected: "18446744073709551615"},
+		{name: "uint64 overflow", eval: func() (string, error) { return evaluateAs[uint64]("18446744073709551615 + 1") }, wantErr: true},
+		{name: "uint bitwise", eval: func() (string, error) { return evaluateAs[uint]("~0 >> 60") }, expected: "15"},
+		{name: "uint8 right shift", eval: func() (string, error) { return evaluateAs[uint8]("200 >> 7") }, expected: "1"},
+		{name: "uint8 right shift by width", eval: func() (string, error) { return evaluateAs[uint8]("200 >> 8") }, expected: "0"},
+		{name: "uint64 right shift past width", eval: func() (string, error) { return evaluateAs[uint64]("2 ^ 63 >> 70") }, expected: "0"},
+		{name: "int8 right shift past width keeps sign", eval: func() (string, error) { return evaluateAs[int8]("-100 >> 20") }, expected: "-1"},
+		{name: "uint8 function", eval: func() (string, error) { return evaluateAs[uint8]("max(3, 250) + gcd(4, 6)") }, expected: "252"},
+		{name: "uint8 function result overflow", eval: func() (string, error) { return evaluateAs[uint8]("lcm(16, 17)") }, wantErr: true},
+		{name: "uint8 variable", eval: func() (string, error) { return evaluateAs[uint8]("n + 1", WithConstant("n", 254)) }, expected: "255"},
+		{name: "uint8 variable out of range", eval: func() (string, error) { return evaluateAs[uint8]("n", WithConstant("n", 256)) }, wantErr: true},
+		{name: "truncating division", eval: func() (string, error) { return evaluateAs[int16]("7 / 2", WithTruncatingDivision()) }, expected: "3"},
+		{name: "inexact division", eval: func() (string, error) { return evaluateAs[int16]("7 / 2") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.eval()
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateAs() expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateAs() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("EvaluateAs() = %s, expected %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluateAsOverflowMessage tests that overflow errors name the type
+func TestEvaluateAsOverflowMessage(t *testing.T) {
+	_, err := evaluateAs[uint8]("200 + 56")
+	if err == nil || err.Error() != "result overflows uint8" {
+		t.Errorf("EvaluateAs() error = %v, expected result overflows uint8", err)
+	}
+
+	_, err = evaluateAs[int64]("9223372036854775807 + 1")
+	if !errors.Is(err, errOverflow) {
+		t.Errorf("EvaluateAs() error = %v, expected %v", err, errOverflow)
+	}
+}
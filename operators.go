@@ -0,0 +1,260 @@
+package shuntingyard
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Associativity is the direction in which operators of equal precedence group.
+type Associativity int
+
+const (
+	// LeftAssociative operators group left to right: "8 - 4 - 2" means
+	// "(8 - 4) - 2".
+	LeftAssociative Associativity = iota
+	// RightAssociative operators group right to left: "2 ^ 3 ^ 2" means
+	// "2 ^ (3 ^ 2)".
+	RightAssociative
+)
+
+// String returns "left" or "right".
+func (a Associativity) String() string {
+	if a == RightAssociative {
+		return "right"
+	}
+	return "left"
+}
+
+// binding is how tightly an operator holds its operands: its precedence,
+// where higher binds tighter, and how operators of equal precedence group.
+type binding struct {
+	precedence int
+	assoc      Associativity
+}
+
+// precedes reports whether an operator with binding b, already on the Parse
+// stack, applies before an incoming operator with binding in: it binds
+// tighter, or as tightly and in groups left to right.
+func (b binding) precedes(in binding) bool {
+	return b.precedence > in.precedence || b.precedence == in.precedence && in.assoc == LeftAssociative
+}
+
+// customOperator is a binary operator registered with WithOperator.
+type customOperator struct {
+	binding
+	fn function
+}
+
+// WithOperator registers a binary operator that expressions can write between
+// two operands, such as "//" for floor division or "mod" for a modulo with the
+// sign of the divisor. precedence ranks it among the built-in operators: 1 for
+// "||", 2 for "&&", 3 for "|", 4 for "xor", 5 for "&", 6 for the comparisons,
+// 7 for the shifts, 8 for "+" and "-", 9 for "*", "/" and "%", and 11 for "^",
+// with the prefix operators at 10. Operators of equal precedence group as
+// assoc says. fn computes the operator; an error it returns fails the
+// evaluation.
+//
+// symbol is either a name, as in "7 mod 2", or a run of punctuation, such as
+// "//", "**" or "??", which Scan matches before the built-in operators and
+// comments, longest first. The float64 evaluators, and EvaluateFloat32,
+// EvaluateResult and EvaluateUncertain, compute the operator through fn; the
+// exact and integer ones reject it.
+//
+// WithOperator panics if symbol is neither a valid name nor punctuation,
+// belongs to the built-in grammar, or if precedence is less than 1.
+func WithOperator(symbol string, precedence int, assoc Associativity, fn func(a, b float64) (float64, error)) Option {
+	if !isIdentifier(symbol) && !isOperatorSymbol(symbol) || isGrammarToken(symbol) || unaryOperators[symbol] != "" || symbol == ";" {
+		panic(fmt.Sprintf("shuntingyard: invalid operator symbol %q", symbol))
+	}
+	if precedence < 1 {
+		panic(fmt.Sprintf("shuntingyard: precedence %d for operator %q is below 1", precedence, symbol))
+	}
+
+	op := customOperator{
+		binding: binding{precedence, assoc},
+		fn: function{arity: 2, operator: true, call: func(args ...float64) (float64, error) {
+			return fn(args[0], args[1])
+		}},
+	}
+	return func(c *config) {
+		// Copy so that options sharing a config don't share the map
+		registered := make(map[string]customOperator, len(c.operators)+1)
+		maps.Copy(registered, c.operators)
+		registered[symbol] = op
+		c.operators = registered
+
+		// Scan tries punctuation symbols longest first, so that "**" isn't
+		// read as two "*"
+		c.operatorSymbols = nil
+		for s := range c.operators {
+			if !isIdentifier(s) {
+				c.operatorSymbols = append(c.operatorSymbols, s)
+			}
+		}
+		slices.SortFunc(c.operatorSymbols, func(a, b string) int {
+			return len(b) - len(a)
+		})
+	}
+}
+
+// isOperatorSymbol reports whether s is a run of punctuation that can name an
+// operator: no letters, digits, spaces, parentheses, commas or dots, which
+// would make it part of an operand or break up the expression.
+func isOperatorSymbol(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || strings.ContainsRune("(),._", r) ||
+			!unicode.IsPunct(r) && !unicode.IsSymbol(r)
+	})
+}
+
+// matchOperator returns the registered punctuation operator s starts with, or
+// n == 0 if none.
+func (c config) matchOperator(s string) (symbol string, n int) {
+	for _, symbol := range c.operatorSymbols {
+		if strings.HasPrefix(s, symbol) {
+			return symbol, len(symbol)
+		}
+	}
+	return "", 0
+}
+
+// isBinaryOperator reports whether token is a binary operator, built-in or
+// registered.
+func (c config) isBinaryOperator(token string) bool {
+	if isBinaryOperator(token) {
+		return true
+	}
+	_, ok := c.operators[token]
+	return ok
+}
+
+// isGrammarToken reports whether an infix token belongs to the grammar,
+// including the registered operators.
+func (c config) isGrammarToken(token string) bool {
+	if isGrammarToken(token) {
+		return true
+	}
+	_, ok := c.operators[token]
+	return ok
+}
+
+// binding returns the binding of a binary operator, built-in or registered.
+func (c config) binding(token string) binding {
+	if op, ok := c.operators[token]; ok {
+		return op.binding
+	}
+	if b, ok := c.precedence[token]; ok {
+		return b
+	}
+	return binaryOperators[token]
+}
+
+// Precedence is how tightly a binary operator binds in a table given to
+// WithPrecedenceTable: its Level, where higher binds tighter, and how
+// operators of the same level group.
+type Precedence struct {
+	Level int
+	Assoc Associativity
+}
+
+// DefaultPrecedence returns the precedence table of the built-in binary
+// operators, a starting point for WithPrecedenceTable.
+func DefaultPrecedence() map[string]Precedence {
+	table := make(map[string]Precedence, len(binaryOperators))
+	for symbol, b := range binaryOperators {
+		table[symbol] = Precedence{Level: b.precedence, Assoc: b.assoc}
+	}
+	return table
+}
+
+// WithPrecedenceTable replaces the precedence and associativity of the
+// built-in binary operators with table, for emulating the rules of another
+// language or making an operator bind tighter in a DSL:
+//
+//	table := DefaultPrecedence()
+//	table["+"] = Precedence{Level: 10, Assoc: LeftAssociative}
+//	Parse(tokens, WithPrecedenceTable(table)) // "2 * 3 + 4" is "2 * (3 + 4)"
+//
+// The prefix operators keep level 10 and the conditional binds loosest.
+// Operators registered with WithOperator keep their own precedence.
+//
+// WithPrecedenceTable panics if table misses a built-in binary operator, has
+// an entry for any other symbol, gives a level below 1, or gives operators of
+// the same level different associativity, which would leave "a op b op c"
+// ambiguous.
+func WithPrecedenceTable(table map[string]Precedence) Option {
+	bindings := make(map[string]binding, len(binaryOperators))
+	assoc := make(map[int]Associativity)
+	for _, symbol := range slices.Sorted(maps.Keys(table)) {
+		p := table[symbol]
+		if !isBinaryOperator(symbol) {
+			panic(fmt.Sprintf("shuntingyard: precedence table has unknown operator %q", symbol))
+		}
+		if p.Level < 1 {
+			panic(fmt.Sprintf("shuntingyard: precedence %d for operator %q is below 1", p.Level, symbol))
+		}
+		if a, ok := assoc[p.Level]; ok && a != p.Assoc {
+			panic(fmt.Sprintf("shuntingyard: operators at precedence %d have different associativity", p.Level))
+		}
+		assoc[p.Level] = p.Assoc
+		bindings[symbol] = binding{p.Level, p.Assoc}
+	}
+	for _, symbol := range slices.Sorted(maps.Keys(binaryOperators)) {
+		if _, ok := bindings[symbol]; !ok {
+			panic(fmt.Sprintf("shuntingyard: precedence table misses operator %q", symbol))
+		}
+	}
+
+	return func(c *config) {
+		c.precedence = bindings
+	}
+}
+
+// OperatorInfo describes an operator the parser supports, as listed by
+// Operators.
+type OperatorInfo struct {
+	// Symbol is the operator's token, such as "+", "xor" or "?:" for the
+	// conditional "c ? a : b"
+	Symbol string
+	// Precedence ranks the operator; higher binds tighter
+	Precedence    int
+	Associativity Associativity
+	// Arity is 1 for the prefix operators, 2 for the binary ones and 3 for
+	// the conditional
+	Arity int
+}
+
+// Operators lists the operators Parse supports with opts, for tools such as
+// syntax highlighters and documentation generators: the built-in binary
+// operators with the precedence set by WithPrecedenceTable, if any, those
+// registered with WithOperator, the prefix operators "-", "!" and "~", and the
+// conditional. The list is ordered from the tightest binding to the loosest,
+// then by arity and symbol. Symbols are the tokens Parse takes, so a bitwise
+// operator renamed by WithBitwiseSymbols is listed under its default symbol.
+func Operators(opts ...Option) []OperatorInfo {
+	cfg := newConfig(opts)
+	infos := make([]OperatorInfo, 0, len(binaryOperators)+len(cfg.operators)+len(unaryOperators)+1)
+
+	add := func(symbol string, b binding, arity int) {
+		infos = append(infos, OperatorInfo{Symbol: symbol, Precedence: b.precedence, Associativity: b.assoc, Arity: arity})
+	}
+	for symbol := range binaryOperators {
+		add(symbol, cfg.binding(symbol), 2)
+	}
+	for symbol, op := range cfg.operators {
+		add(symbol, op.binding, 2)
+	}
+	for symbol := range unaryOperators {
+		add(symbol, unaryBinding, 1)
+	}
+	add(ternary, ternaryBinding, 3)
+
+	slices.SortFunc(infos, func(a, b OperatorInfo) int {
+		return cmp.Or(b.Precedence-a.Precedence, a.Arity-b.Arity, strings.Compare(a.Symbol, b.Symbol))
+	})
+	return infos
+}
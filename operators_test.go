@@ -0,0 +1,347 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestWithOperator tests registered binary operators through the full pipeline
+func TestWithOperator(t *testing.T) {
+	opts := []Option{
+		WithOperator("//", 9, LeftAssociative, func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errors.New("integer division by zero")
+			}
+			return math.Floor(a / b), nil
+		}),
+		WithOperator("**", 11, RightAssociative, func(a, b float64) (float64, error) {
+			return math.Pow(a, b), nil
+		}),
+		WithOperator("mod", 9, LeftAssociative, func(a, b float64) (float64, error) {
+			return a - b*math.Floor(a/b), nil
+		}),
+		WithOperator("??", 1, LeftAssociative, func(a, b float64) (float64, error) {
+			if math.IsNaN(a) {
+				return b, nil
+			}
+			return a, nil
+		}),
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "integer division", expression: "7 // 2", expected: 3},
+		{name: "negative integer division", expression: "-7 // 2", expected: -4},
+		{name: "same precedence as multiplication", expression: "2 * 7 // 4", expected: 3},
+		{name: "binds tighter than addition", expression: "1 + 7 // 2", expected: 4},
+		{name: "without spaces", expression: "7//2", expected: 3},
+		{name: "right-associative", expression: "2 ** 3 ** 2", expected: 512},
+		{name: "groups with exponentiation", expression: "2 ^ 3 ** 2", expected: 512},
+		{name: "exponentiation groups with it", expression: "2 ** 3 ^ 2", expected: 512},
+		{name: "longer than multiplication", expression: "2**3*2", expected: 16},
+		{name: "keyword", expression: "-7 mod 3", expected: 2},
+		{name: "longer than the conditional", expression: "nan ?? 5", expected: 5},
+		{name: "conditional still scans", expression: "1 ? 2 : 3", expected: 2},
+		{name: "line comment still scans", expression: "7 // 2 # halved", expected: 3},
+
+		// Error cases
+		{name: "operator error", expression: "1 // 0", wantErr: true},
+		{name: "missing operand", expression: "7 //", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("eval() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("eval() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("unregistered symbol", func(t *testing.T) {
+		// Without the registration "//" starts a comment
+		result, err := eval("7 // 2")
+		if err != nil || result != 7 {
+			t.Errorf("eval() without WithOperator = %v, %v, expected 7", result, err)
+		}
+	})
+}
+
+// TestWithOperatorEvaluators tests registered operators in the other consumers
+// of the postfix
+func TestWithOperatorEvaluators(t *testing.T) {
+	div := WithOperator("//", 9, LeftAssociative, func(a, b float64) (float64, error) {
+		if b == 0 {
+			return 0, errors.New("integer division by zero")
+		}
+		return math.Floor(a / b), nil
+	})
+
+	tokens, err := Scan("7 // x", div)
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	postfix, err := Parse(tokens, div)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	t.Run("ast", func(t *testing.T) {
+		node, err := ParseAST(tokens, div)
+		if err != nil {
+			t.Fatalf("ParseAST() unexpected error: %v", err)
+		}
+		expected := &BinaryNode{Operator: "//", Left: &NumberNode{Value: "7"}, Right: &VariableNode{Name: "x"}}
+		if !reflect.DeepEqual(node, expected) {
+			t.Errorf("ParseAST() = %#v, expected %#v", node, expected)
+		}
+	})
+
+	t.Run("variables", func(t *testing.T) {
+		names, err := Variables("7 // x", div)
+		if err != nil {
+			t.Fatalf("Variables() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(names, []string{"x"}) {
+			t.Errorf("Variables() = %v, expected [x]", names)
+		}
+	})
+
+	t.Run("operator error", func(t *testing.T) {
+		_, err := Evaluate(postfix, div, WithMissingVariableDefault(0))
+		if err == nil || err.Error() != "operator '//': integer division by zero" {
+			t.Errorf("Evaluate() error = %v, expected operator '//': integer division by zero", err)
+		}
+	})
+
+	t.Run("exact evaluator", func(t *testing.T) {
+		_, err := EvaluateRat([]string{"7", "2", "//"}, div)
+		if err == nil || err.Error() != "operator '//' has no exact rational result" {
+			t.Errorf("EvaluateRat() error = %v, expected operator '//' has no exact rational result", err)
+		}
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		explanation, err := Explain("1 + 7 // 2", div)
+		if err != nil {
+			t.Fatalf("Explain() unexpected error: %v", err)
+		}
+		expected := "1. '//' operation 7 // 2 = 3 happens before the addition because '//' operation has higher precedence\n" +
+			"2. addition 1 + 3 = 4\n" +
+			"Result: 4"
+		if explanation != expected {
+			t.Errorf("Explain() = %q, expected %q", explanation, expected)
+		}
+	})
+
+	t.Run("session assignment", func(t *testing.T) {
+		mod := WithOperator("mod", 9, LeftAssociative, func(a, b float64) (float64, error) {
+			return math.Mod(a, b), nil
+		})
+		if _, err := NewSession(mod).Eval("mod = 3"); err == nil {
+			t.Error("Session.Eval() expected error assigning to an operator, got nil")
+		}
+	})
+}
+
+// TestWithOperatorInvalid tests that invalid registrations panic
+func TestWithOperatorInvalid(t *testing.T) {
+	fn := func(a, b float64) (float64, error) { return 0, nil }
+
+	tests := []struct {
+		name       string
+		symbol     string
+		precedence int
+	}{
+		{name: "empty symbol", symbol: "", precedence: 9},
+		{name: "built-in operator", symbol: "+", precedence: 9},
+		{name: "keyword operator", symbol: "xor", precedence: 9},
+		{name: "parenthesis", symbol: "(", precedence: 9},
+		{name: "prefix operator", symbol: "~", precedence: 9},
+		{name: "mixed letters and punctuation", symbol: "1x", precedence: 9},
+		{name: "contains a space", symbol: "/ /", precedence: 9},
+		{name: "zero precedence", symbol: "//", precedence: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithOperator(%q, %d) expected panic", tt.symbol, tt.precedence)
+				}
+			}()
+			WithOperator(tt.symbol, tt.precedence, LeftAssociative, fn)
+		})
+	}
+}
+
+// TestWithPrecedenceTable tests parsing with a replaced precedence table
+func TestWithPrecedenceTable(t *testing.T) {
+	// Addition binding tighter than multiplication, as in a DSL
+	additive := DefaultPrecedence()
+	additive["+"] = Precedence{Level: 10, Assoc: LeftAssociative}
+
+	// Bitwise operators binding tighter than comparisons, as in Python
+	python := DefaultPrecedence()
+	python["&"] = Precedence{Level: 7, Assoc: LeftAssociative}
+	python["|"] = Precedence{Level: 7, Assoc: LeftAssociative}
+	python["xor"] = Precedence{Level: 7, Assoc: LeftAssociative}
+	python["<<"] = Precedence{Level: 8, Assoc: LeftAssociative}
+	python[">>"] = Precedence{Level: 8, Assoc: LeftAssociative}
+	python["+"] = Precedence{Level: 9, Assoc: LeftAssociative}
+	python["-"] = Precedence{Level: 9, Assoc: LeftAssociative}
+	python["*"] = Precedence{Level: 10, Assoc: LeftAssociative}
+	python["/"] = Precedence{Level: 10, Assoc: LeftAssociative}
+	python["%"] = Precedence{Level: 10, Assoc: LeftAssociative}
+
+	// Subtraction grouping right to left
+	right := DefaultPrecedence()
+	right["+"] = Precedence{Level: 8, Assoc: RightAssociative}
+	right["-"] = Precedence{Level: 8, Assoc: RightAssociative}
+
+	tests := []struct {
+		name       string
+		expression string
+		table      map[string]Precedence
+		expected   float64
+	}{
+		{name: "default table", expression: "2 * 3 + 4", table: DefaultPrecedence(), expected: 10},
+		{name: "addition binds tighter", expression: "2 * 3 + 4", table: additive, expected: 14},
+		{name: "prefix minus at the same level", expression: "-2 + 3", table: additive, expected: 1},
+		{name: "bitwise before comparison", expression: "2 & 3 == 2", table: python, expected: 1},
+		{name: "default bitwise after comparison", expression: "2 & 3 == 2", table: DefaultPrecedence(), expected: 0},
+		{name: "right-associative subtraction", expression: "8 - 4 - 2", table: right, expected: 6},
+		{name: "explicit grouping still wins", expression: "(2 * 3) + 4", table: additive, expected: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithPrecedenceTable(tt.table))
+			if err != nil {
+				t.Fatalf("eval() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("eval() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("table is copied", func(t *testing.T) {
+		table := DefaultPrecedence()
+		opt := WithPrecedenceTable(table)
+		table["+"] = Precedence{Level: 10, Assoc: LeftAssociative}
+		if result, err := eval("2 * 3 + 4", opt); err != nil || result != 10 {
+			t.Errorf("eval() = %v, %v, expected 10", result, err)
+		}
+	})
+}
+
+// TestWithPrecedenceTableInvalid tests that inconsistent tables panic
+func TestWithPrecedenceTableInvalid(t *testing.T) {
+	missing := DefaultPrecedence()
+	delete(missing, "%")
+
+	unknown := DefaultPrecedence()
+	unknown["**"] = Precedence{Level: 11, Assoc: RightAssociative}
+
+	zero := DefaultPrecedence()
+	zero["||"] = Precedence{Level: 0, Assoc: LeftAssociative}
+
+	mixed := DefaultPrecedence()
+	mixed["-"] = Precedence{Level: 8, Assoc: RightAssociative}
+
+	tests := []struct {
+		name  string
+		table map[string]Precedence
+	}{
+		{name: "missing operator", table: missing},
+		{name: "unknown operator", table: unknown},
+		{name: "zero level", table: zero},
+		{name: "mixed associativity", table: mixed},
+		{name: "empty table", table: map[string]Precedence{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("WithPrecedenceTable() expected panic")
+				}
+			}()
+			WithPrecedenceTable(tt.table)
+		})
+	}
+}
+
+// TestOperators tests listing the supported operators
+func TestOperators(t *testing.T) {
+	t.Run("built-in", func(t *testing.T) {
+		infos := Operators()
+		if len(infos) != 23 {
+			t.Fatalf("Operators() returned %d operators, expected 23", len(infos))
+		}
+
+		expected := []OperatorInfo{
+			{Symbol: "^", Precedence: 11, Associativity: RightAssociative, Arity: 2},
+			{Symbol: "!", Precedence: 10, Associativity: RightAssociative, Arity: 1},
+			{Symbol: "-", Precedence: 10, Associativity: RightAssociative, Arity: 1},
+			{Symbol: "~", Precedence: 10, Associativity: RightAssociative, Arity: 1},
+			{Symbol: "%", Precedence: 9, Associativity: LeftAssociative, Arity: 2},
+		}
+		if !reflect.DeepEqual(infos[:len(expected)], expected) {
+			t.Errorf("Operators()[:%d] = %v, expected %v", len(expected), infos[:len(expected)], expected)
+		}
+		if last := infos[len(infos)-1]; last != (OperatorInfo{Symbol: "?:", Precedence: 0, Associativity: RightAssociative, Arity: 3}) {
+			t.Errorf("Operators() ends with %v, expected the conditional", last)
+		}
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		table := DefaultPrecedence()
+		table["+"] = Precedence{Level: 10, Assoc: LeftAssociative}
+		infos := Operators(
+			WithPrecedenceTable(table),
+			WithOperator("//", 9, LeftAssociative, func(a, b float64) (float64, error) { return math.Floor(a / b), nil }),
+		)
+
+		find := func(symbol string, arity int) (OperatorInfo, bool) {
+			for _, info := range infos {
+				if info.Symbol == symbol && info.Arity == arity {
+					return info, true
+				}
+			}
+			return OperatorInfo{}, false
+		}
+		if info, ok := find("+", 2); !ok || info.Precedence != 10 {
+			t.Errorf("Operators() lists %v for '+', expected precedence 10", info)
+		}
+		if info, ok := find("//", 2); !ok || info.Precedence != 9 || info.Associativity != LeftAssociative {
+			t.Errorf("Operators() lists %v for '//', expected precedence 9, left-associative", info)
+		}
+	})
+}
+
+func TestAssociativityString(t *testing.T) {
+	if got := RightAssociative.String(); got != "right" {
+		t.Errorf("RightAssociative.String() = %q, expected %q", got, "right")
+	}
+	if got := LeftAssociative.String(); got != "left" {
+		t.Errorf("LeftAssociative.String() = %q, expected %q", got, "left")
+	}
+}
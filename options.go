@@ -0,0 +1,245 @@
+package shuntingyard
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Option configures Scan, Parse, and Evaluate. Each stage ignores options that
+// don't apply to it, so the same options can be passed to every stage.
+type Option func(*config)
+
+// config holds the settings assembled from a list of Options.
+type config struct {
+	redactErrors  bool
+	recoverErrors bool
+	literals      []LiteralParser
+	maxDepth      int
+	maxTokens     int
+	maxOperators  int
+	maxSteps      int
+	noFolding     bool
+	bitwise       []bitwiseSymbol
+	functions     map[string]function
+	degrees       bool
+	constants     map[string]float64
+	numbers       NumberFormat
+
+	// precedence replaces the bindings of the built-in binary operators
+	// (see WithPrecedenceTable)
+	precedence map[string]binding
+
+	// operators holds the operators registered with WithOperator, and
+	// operatorSymbols those of them Scan matches, longest first
+	operators       map[string]customOperator
+	operatorSymbols []string
+
+	// source is the expression being parsed, when known, for locating
+	// errors by line and column
+	source string
+
+	// nonFinite is the policy for NaN and infinite results, and
+	// nonFiniteDefault the value that replaces them under nonFiniteDefault
+	nonFinite        nonFinitePolicy
+	nonFiniteDefault float64
+
+	// truncateDivision makes EvaluateInt truncate inexact quotients
+	truncateDivision bool
+
+	// rounding applies roundMode at roundScale places to results, and to
+	// intermediate ones with roundIntermediates
+	rounding           bool
+	roundScale         int
+	roundMode          RoundingMode
+	roundIntermediates bool
+
+	// vars holds the variable values of EvaluateWithVars
+	vars     map[string]float64
+	resolver VariableResolver
+	missing  func(name string) (float64, error)
+
+	// ctx cancels the evaluations of EvaluateContext
+	ctx context.Context
+}
+
+// newConfig applies opts over the default configuration.
+func newConfig(opts []Option) config {
+	if len(opts) == 0 {
+		return config{}
+	}
+
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// WithRedactedErrors keeps operand values and raw tokens out of error messages,
+// identifying the offending token or character by its position instead. Use it
+// when expressions may contain sensitive figures and errors end up in logs.
+func WithRedactedErrors() Option {
+	return func(c *config) {
+		c.redactErrors = true
+	}
+}
+
+// WithErrorRecovery makes Parse carry on past the errors it can recover from,
+// such as an unmatched parenthesis, a missing operand or operator, or an
+// invalid token, and report them all at once, for editors that show every
+// problem in one pass. Parse repairs the input as it goes, assuming a missing
+// operand or operator and skipping unmatched tokens, so that one mistake
+// rarely causes another. The errors are joined with errors.Join in the order
+// Parse finds them, each as Parse would report it alone, and no postfix is
+// returned.
+// Exceeding WithMaxDepth still stops parsing at once.
+func WithErrorRecovery() Option {
+	return func(c *config) {
+		c.recoverErrors = true
+	}
+}
+
+// LiteralParser recognizes a domain-specific numeric literal, such as "1.5k",
+// "£1,200" or "2'6\"", at the start of s. It returns the literal's value and its
+// length in bytes, or ok == false if s doesn't start with such a literal.
+type LiteralParser func(s string) (value float64, n int, ok bool)
+
+// WithLiteralParser registers a custom literal format with Scan. Scan consults
+// the registered parsers, in order, only when it meets a character it would
+// otherwise reject; each parser is handed the input from the start of the
+// current token (so for "1.5k" it sees "1.5k") and must consume at least the
+// rejected character. Characters that belong to the grammar, such as the
+// operators, are never offered to literal parsers. A recognized literal
+// becomes a regular number token, so Parse and Evaluate need no further
+// configuration.
+func WithLiteralParser(parser LiteralParser) Option {
+	return func(c *config) {
+		c.literals = append(c.literals, parser)
+	}
+}
+
+// parseLiteral tries the registered literal parsers against s, accepting only
+// a match that extends at least minLen bytes into s.
+func (c config) parseLiteral(s string, minLen int) (float64, int, bool) {
+	for _, parser := range c.literals {
+		value, n, ok := parser(s)
+		if ok && n >= minLen && n <= len(s) {
+			return value, n, true
+		}
+	}
+	return 0, 0, false
+}
+
+// WithMaxDepth makes Parse reject expressions whose parentheses nest deeper
+// than depth. Parsing uses explicit stacks rather than recursion, so any depth
+// is safe to parse; the cap exists to bound the work spent on untrusted input.
+// The error wraps a *LimitError. A depth of zero or less means no limit, which
+// is the default.
+func WithMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.maxDepth = depth
+	}
+}
+
+// BitwiseSymbols names the symbols Scan recognizes for the bitwise operators.
+// An empty field keeps the default symbol for that operator.
+type BitwiseSymbols struct {
+	And string // default "&"
+	Or  string // default "|"
+	Xor string // default "xor"
+	Not string // default "~"
+}
+
+// bitwiseSymbol maps a source symbol to the token Scan emits for it.
+type bitwiseSymbol struct {
+	symbol, token string
+}
+
+// defaultBitwise holds the default bitwise symbols, each emitted as itself.
+var defaultBitwise = []bitwiseSymbol{
+	{symbol: "&", token: "&"},
+	{symbol: "|", token: "|"},
+	{symbol: "xor", token: "xor"},
+	{symbol: "~", token: "~"},
+}
+
+// WithBitwiseSymbols changes the symbols Scan recognizes for the bitwise
+// operators, for inputs written in a notation that uses them differently.
+// Scan translates a configured symbol to the operator's default symbol, so
+// Parse and Evaluate need no further configuration. Configured symbols take
+// priority over the built-in operators: WithBitwiseSymbols(BitwiseSymbols{Xor: "^"})
+// reads "15 ^ 1" as an XOR, at the cost of exponentiation.
+// A default symbol that is reassigned no longer introduces its operator.
+func WithBitwiseSymbols(symbols BitwiseSymbols) Option {
+	return func(c *config) {
+		c.bitwise = []bitwiseSymbol{
+			{symbol: cmp.Or(symbols.And, "&"), token: "&"},
+			{symbol: cmp.Or(symbols.Or, "|"), token: "|"},
+			{symbol: cmp.Or(symbols.Xor, "xor"), token: "xor"},
+			{symbol: cmp.Or(symbols.Not, "~"), token: "~"},
+		}
+		// Try longer symbols first so that one symbol can't cut another short
+		slices.SortStableFunc(c.bitwise, func(a, b bitwiseSymbol) int {
+			return len(b.symbol) - len(a.symbol)
+		})
+	}
+}
+
+// matchBitwise reports which bitwise operator token s starts with and the
+// length of its symbol, or n == 0 if none. A single-character symbol gives way
+// to a built-in two-character operator it begins, such as '&' to "&&", and a
+// keyword gives way to a longer name it begins, such as "xor" to "xor2".
+func (c config) matchBitwise(s string) (token string, n int) {
+	symbols := c.bitwise
+	if symbols == nil {
+		symbols = defaultBitwise
+	}
+	for _, sym := range symbols {
+		if !strings.HasPrefix(s, sym.symbol) {
+			continue
+		}
+		if len(sym.symbol) == 1 && len(s) >= 2 && isBinaryOperator(s[:2]) {
+			continue
+		}
+		// A keyword symbol such as "xor" must not cut a longer name short
+		end := len(sym.symbol)
+		if isIdentByte(sym.symbol[end-1]) && end < len(s) && isIdentByte(s[end]) {
+			continue
+		}
+		return sym.token, len(sym.symbol)
+	}
+	return "", 0
+}
+
+// invalidNumber reports token at the given index as an invalid number.
+func (c config) invalidNumber(token string, index int) error {
+	if c.redactErrors {
+		return fmt.Errorf("invalid number at token %d", index)
+	}
+	return fmt.Errorf("invalid number: %s", token)
+}
+
+// literalError is the error of an arithmetic for a number it can't represent
+// exactly, such as "0.1" as a float64 for EvaluateRat: problem says why.
+type literalError struct {
+	token   string
+	problem string
+}
+
+func (e *literalError) Error() string {
+	return fmt.Sprintf("'%s' %s", e.token, e.problem)
+}
+
+// redactLiteral replaces the number in a literalError from the token at the
+// given index with the index, when errors are redacted.
+func (c config) redactLiteral(err error, index int) error {
+	var literal *literalError
+	if c.redactErrors && errors.As(err, &literal) {
+		return fmt.Errorf("number at token %d %s", index, literal.problem)
+	}
+	return err
+}
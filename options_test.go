@@ -0,0 +1,328 @@
+package shuntingyard
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRedactedErrors tests that WithRedactedErrors keeps raw tokens out of error messages
+func TestRedactedErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(opts ...Option) error
+		sensitive string
+		redacted  string
+	}{
+		{
+			name: "scan invalid character",
+			run: func(opts ...Option) error {
+				_, err := Scan("52000 + $", opts...)
+				return err
+			},
+			sensitive: "$",
+			redacted:  "invalid character at position 8",
+		},
+		{
+			name: "parse invalid number",
+			run: func(opts ...Option) error {
+				_, err := Parse([]string{"2", "+", "52000.1.5"}, opts...)
+				return err
+			},
+			sensitive: "52000.1.5",
+			redacted:  "invalid number at token 2",
+		},
+		{
+			name: "parse missing operator",
+			run: func(opts ...Option) error {
+				_, err := Parse([]string{"52000", "61000"}, opts...)
+				return err
+			},
+			sensitive: "61000",
+			redacted:  "expected operator or end of expression after number at token 0, found number at token 1",
+		},
+		{
+			name: "evaluate invalid number",
+			run: func(opts ...Option) error {
+				_, err := Evaluate([]string{"2", "52000.1.5", "+"}, opts...)
+				return err
+			},
+			sensitive: "52000.1.5",
+			redacted:  "invalid number at token 1",
+		},
+		{
+			name: "evaluate float32 invalid number",
+			run: func(opts ...Option) error {
+				_, err := EvaluateFloat32([]string{"52000.1.5"}, opts...)
+				return err
+			},
+			sensitive: "52000.1.5",
+			redacted:  "invalid number at token 0",
+		},
+		{
+			name: "evaluate result invalid number",
+			run: func(opts ...Option) error {
+				_, err := EvaluateResult([]string{"52000.1.5"}, opts...)
+				return err
+			},
+			sensitive: "52000.1.5",
+			redacted:  "invalid number at token 0",
+		},
+		{
+			name: "pipeline",
+			run: func(opts ...Option) error {
+				_, err := eval("52000 61000", opts...)
+				return err
+			},
+			sensitive: "61000",
+			redacted:  "expected operator or end of expression after number at token 0, found number at token 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without the option the token is part of the message
+			err := tt.run()
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.sensitive) {
+				t.Errorf("error %q does not mention %q", err, tt.sensitive)
+			}
+
+			err = tt.run(WithRedactedErrors())
+			if err == nil {
+				t.Fatalf("expected redacted error, got nil")
+			}
+			if strings.Contains(err.Error(), tt.sensitive) {
+				t.Errorf("redacted error %q leaks %q", err, tt.sensitive)
+			}
+			if err.Error() != tt.redacted {
+				t.Errorf("redacted error = %q, expected %q", err, tt.redacted)
+			}
+		})
+	}
+}
+
+// TestRedactedShiftCount tests that a shift count too large for 64 bits is
+// left out of the error on every float path, redacted or not
+func TestRedactedShiftCount(t *testing.T) {
+	const expression = "1 << 98765"
+	postfix := []string{"1", "98765", "<<"}
+
+	tests := []struct {
+		name string
+		run  func(opts ...Option) error
+	}{
+		{name: "Evaluate", run: func(opts ...Option) error {
+			_, err := Evaluate(postfix, opts...)
+			return err
+		}},
+		{name: "EvaluateFloat32", run: func(opts ...Option) error {
+			_, err := EvaluateFloat32(postfix, opts...)
+			return err
+		}},
+		{name: "EvaluateResult", run: func(opts ...Option) error {
+			_, err := EvaluateResult(postfix, opts...)
+			return err
+		}},
+		{name: "pipeline", run: func(opts ...Option) error {
+			_, err := eval(expression, opts...)
+			return err
+		}},
+		{name: "Compile", run: func(opts ...Option) error {
+			expr, err := Compile(expression, append(opts, WithoutConstantFolding())...)
+			if err != nil {
+				return err
+			}
+			_, err = expr.Eval(nil)
+			return err
+		}},
+		{name: "CompileFunc", run: func(opts ...Option) error {
+			fn, err := CompileFunc(expression, append(opts, WithoutConstantFolding())...)
+			if err != nil {
+				return err
+			}
+			_, err = fn(nil)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opts := range [][]Option{nil, {WithRedactedErrors()}} {
+				err := tt.run(opts...)
+				if err == nil || !strings.Contains(err.Error(), "shift count exceeds 63") {
+					t.Fatalf("error = %v, expected shift count exceeds 63", err)
+				}
+				if strings.Contains(err.Error(), "98765") {
+					t.Errorf("error %q leaks the shift count", err)
+				}
+			}
+		})
+	}
+}
+
+// thousandsLiteral parses literals such as "1.5k" as multiples of a thousand
+func thousandsLiteral(s string) (float64, int, bool) {
+	end := strings.IndexByte(s, 'k')
+	if end <= 0 {
+		return 0, 0, false
+	}
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return value * 1000, end + 1, true
+}
+
+// poundLiteral parses literals such as "£1,200" with thousands separators
+func poundLiteral(s string) (float64, int, bool) {
+	rest, ok := strings.CutPrefix(s, "£")
+	if !ok {
+		return 0, 0, false
+	}
+	n := len(s) - len(rest)
+	var digits strings.Builder
+	for n < len(s) && (s[n] >= '0' && s[n] <= '9' || s[n] == ',' || s[n] == '.') {
+		if s[n] != ',' {
+			digits.WriteByte(s[n])
+		}
+		n++
+	}
+	value, err := strconv.ParseFloat(digits.String(), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return value, n, true
+}
+
+// feetInchesLiteral parses literals such as 2'6" as a number of feet
+func feetInchesLiteral(s string) (float64, int, bool) {
+	feetEnd := strings.IndexByte(s, '\'')
+	if feetEnd <= 0 {
+		return 0, 0, false
+	}
+	inchesEnd := strings.IndexByte(s[feetEnd:], '"')
+	if inchesEnd < 0 {
+		return 0, 0, false
+	}
+	inchesEnd += feetEnd
+	feet, err := strconv.ParseFloat(s[:feetEnd], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	inches, err := strconv.ParseFloat(s[feetEnd+1:inchesEnd], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return feet + inches/12, inchesEnd + 1, true
+}
+
+// TestLiteralParser tests custom literal formats through the full pipeline
+func TestLiteralParser(t *testing.T) {
+	opts := []Option{
+		WithLiteralParser(thousandsLiteral),
+		WithLiteralParser(poundLiteral),
+		WithLiteralParser(feetInchesLiteral),
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "thousands suffix", expression: "2 * 1.5k", expected: 3000},
+		{name: "thousands suffix without spaces", expression: "12k/4", expected: 3000},
+		{name: "currency with thousands", expression: "£1,200 + £300.50", expected: 1500.5},
+		{name: "feet and inches", expression: "2'6\" * 2", expected: 5},
+		{name: "mixed with plain numbers", expression: "(£1,000 - 100) / 1k", expected: 0.9},
+
+		// Error cases
+		{name: "unrecognized character", expression: "2 + $5", wantErr: true},
+		{name: "incomplete literal", expression: "5' + 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestLiteralParserSpans tests that a custom literal's span covers its source text
+func TestLiteralParserSpans(t *testing.T) {
+	expression := "3 * £1,200"
+
+	tokens, spans, err := ScanSpans(expression, WithLiteralParser(poundLiteral))
+	if err != nil {
+		t.Fatalf("ScanSpans() unexpected error: %v", err)
+	}
+
+	if len(tokens) != 3 || tokens[2] != "1200" {
+		t.Fatalf("ScanSpans() tokens = %v, expected [3 * 1200]", tokens)
+	}
+	if source := expression[spans[2].Start:spans[2].End]; source != "£1,200" {
+		t.Errorf("ScanSpans() literal span covers %q, expected %q", source, "£1,200")
+	}
+}
+
+// TestBitwiseSymbols tests reassigning the bitwise operator symbols
+func TestBitwiseSymbols(t *testing.T) {
+	tests := []struct {
+		name       string
+		symbols    BitwiseSymbols
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "defaults", expression: "12 xor 10 & ~0", expected: 6},
+		{name: "caret as XOR", symbols: BitwiseSymbols{Xor: "^"}, expression: "12 ^ 10", expected: 6},
+		{name: "keyword operators", symbols: BitwiseSymbols{And: "and", Or: "or", Not: "not"}, expression: "not 0 and 12 or 1", expected: 13},
+		{name: "logical operators still doubled", symbols: BitwiseSymbols{And: "and"}, expression: "1 && 2", expected: 1},
+		{name: "longer symbol sharing a prefix", symbols: BitwiseSymbols{And: "b", Or: "bor"}, expression: "12 bor 3 b 6", expected: 14},
+
+		// Error cases
+		{name: "reassigned default symbol", symbols: BitwiseSymbols{Xor: "^"}, expression: "12 xor 10", wantErr: true},
+		{name: "reassigned single ampersand", symbols: BitwiseSymbols{And: "and"}, expression: "12 & 10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithBitwiseSymbols(tt.symbols))
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PartialEvaluate folds every subexpression of a postfix expression that
+// doesn't depend on an unknown variable, returning a simplified postfix
+// expression in the remaining unknowns. Templates can so be precomputed once
+// and evaluated per row with EvaluateWithVars:
+//
+//	PartialEvaluate([]string{"2", "3", "*", "x", "+"}) // ["6", "x", "+"]
+//
+// Variables known through opts, such as constants or a WithVariableResolver,
+// are folded in; only variables that are undefined remain. A conditional with
+// a known condition is replaced by the branch it chooses, even if that branch
+// has unknowns. Functions whose arguments are all known are called once, here.
+//
+// A known subexpression that fails, such as a division by zero, is left as
+// it is where evaluation may skip it, however deeply it is nested in a branch
+// of a conditional or the right operand of a "&&" or "||" an unknown decides:
+// ["x", "2", "1", "0", "/", "?:"] is returned unchanged. Returns an error for
+// invalid postfix input, or for a known subexpression that fails wherever
+// evaluation reaches it, as a *TokenError when a token is at fault.
+func PartialEvaluate(postfixTokens []string, opts ...Option) ([]string, error) {
+	cfg := newConfig(opts)
+
+	if len(postfixTokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	// Each entry is a subexpression in postfix form, with the index in
+	// postfixTokens of each of its tokens; known ones are a single number
+	// token. err is set, and the tokens left unfolded, when evaluating the
+	// subexpression fails whatever values the unknowns take
+	type entry struct {
+		tokens []string
+		origin []int
+		known  bool
+		err    error
+	}
+	stack := make([]entry, 0, len(postfixTokens)/2+1)
+
+	for i, token := range postfixTokens {
+		operands := 0
+		switch {
+		case cfg.isBinaryOperator(token):
+			operands = 2
+		case isUnaryOperator(token):
+			operands = 1
+		case token == ternary:
+			operands = 3
+		case cfg.isCall(token):
+			_, argc, err := cfg.checkCall(token, len(stack))
+			if err != nil {
+				return nil, &TokenError{Index: i, Err: err}
+			}
+			operands = argc
+
+		default:
+			// A number, or a variable that is known or stays symbolic
+			if _, err := strconv.ParseFloat(token, 64); err == nil {
+				stack = append(stack, entry{tokens: []string{token}, origin: []int{i}, known: true})
+				continue
+			}
+			value, err := cfg.variable(token, i)
+			if errors.Is(err, ErrUndefinedVariable) {
+				stack = append(stack, entry{tokens: []string{token}, origin: []int{i}})
+				continue
+			}
+			if err != nil {
+				return nil, &TokenError{Index: i, Err: err}
+			}
+			stack = append(stack, entry{tokens: []string{formatValue(value)}, origin: []int{i}, known: true})
+			continue
+		}
+
+		if len(stack) < operands {
+			return nil, &TokenError{Index: i, Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)}
+		}
+		args := stack[len(stack)-operands:]
+
+		// A known condition settles a conditional whatever its branches hold,
+		// and an unknown one may skip either branch, as the left operand of
+		// "&&" or "||" may skip the right one
+		guarded := 0
+		switch {
+		case token == ternary && args[0].err == nil && args[0].known:
+			condition, _ := strconv.ParseFloat(args[0].tokens[0], 64)
+			chosen := args[2]
+			if condition != 0 {
+				chosen = args[1]
+			}
+			stack = append(stack[:len(stack)-operands], chosen)
+			continue
+		case token == ternary && !args[0].known:
+			guarded = 2
+		case token == "&&" || token == "||":
+			guarded = 1
+		}
+
+		result := entry{known: true}
+		for j, arg := range args {
+			// Every other operand is evaluated with the operation, which so
+			// fails as well; whether that matters is up to the operations
+			// enclosing it, as it may itself be skipped
+			if arg.err != nil && j < len(args)-guarded && result.err == nil {
+				result.err = arg.err
+			}
+			result.known = result.known && arg.known
+			result.tokens = append(result.tokens, arg.tokens...)
+			result.origin = append(result.origin, arg.origin...)
+		}
+		result.tokens = append(result.tokens, token)
+		result.origin = append(result.origin, i)
+
+		if result.known && result.err == nil {
+			// Evaluate the operation alone; its operands are exact number
+			// tokens, or a failing right operand a "&&" or "||" may skip
+			value, err := evaluate(result.tokens, cfg)
+			var tokenErr *TokenError
+			switch {
+			case err == nil:
+				result.tokens, result.origin = []string{formatValue(value)}, []int{i}
+			case errors.As(err, &tokenErr):
+				result.err = &TokenError{Index: result.origin[tokenErr.Index], Err: tokenErr.Err}
+			default:
+				result.err = err
+			}
+		}
+		stack = append(stack[:len(stack)-operands], result)
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("invalid expression: too many operands")
+	}
+
+	if err := stack[0].err; err != nil {
+		return nil, err
+	}
+	return stack[0].tokens, nil
+}
@@ -0,0 +1,117 @@
+package shuntingyard
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestPartialEvaluate tests folding known subexpressions of postfix expressions
+func TestPartialEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   []string
+		wantErr    bool
+	}{
+		{name: "fold before unknown", expression: "2 * 3 + x", expected: []string{"6", "x", "+"}},
+		{name: "fully known", expression: "(1 + 2) * 4", expected: []string{"12"}},
+		{name: "nothing to fold", expression: "x * y", expected: []string{"x", "y", "*"}},
+		{name: "fold around unknowns", expression: "x * (10 / 4) - y ^ (1 + 1)", expected: []string{"x", "2.5", "*", "y", "2", "^", "-"}},
+		{name: "unknown blocks folding", expression: "2 + x + 3", expected: []string{"2", "x", "+", "3", "+"}},
+		{name: "constant folded", expression: "2 * pi * r", expected: []string{"6.283185307179586", "r", "*"}},
+		{name: "registered constant folded", expression: "g * t", opts: []Option{WithConstant("g", 9.81)}, expected: []string{"9.81", "t", "*"}},
+		{name: "negative result", expression: "x + -(3 * 2)", expected: []string{"x", "-6", "+"}},
+		{name: "function with known arguments", expression: "max(2, 5) * x", expected: []string{"5", "x", "*"}},
+		{name: "function with unknown argument", expression: "max(2 + 1, x)", expected: []string{"3", "x", "max/2"}},
+		{name: "known condition chooses branch", expression: "1 > 2 ? x : y * 2", expected: []string{"y", "2", "*"}},
+		{name: "unknown condition", expression: "x ? 1 + 1 : 0", expected: []string{"x", "2", "0", "?:"}},
+		{name: "branch not taken would fail", expression: "1 ? 2 : 1 / 0", expected: []string{"2"}},
+		{name: "failing branch of unknown condition", expression: "x ? 2 : 1 / 0", expected: []string{"x", "2", "1", "0", "/", "?:"}},
+		{name: "skipped operand would fail", expression: "0 && 1 / 0", expected: []string{"0"}},
+		{name: "failing operand unknown may skip", expression: "x || 1 / 0", expected: []string{"x", "1", "0", "/", "||"}},
+		{name: "nested failure in branch", expression: "x ? 1 : 1 / 0 + 1", expected: []string{"x", "1", "1", "0", "/", "1", "+", "?:"}},
+		{name: "nested failure in skippable operand", expression: "x || sqrt(1 / 0)", expected: []string{"x", "1", "0", "/", "sqrt/1", "||"}},
+		{name: "nested failure skipped by known operand", expression: "0 && sqrt(1 / 0) > 1", expected: []string{"0"}},
+
+		// Error cases
+		{name: "known division by zero", expression: "x + 1 / 0", wantErr: true},
+		{name: "taken branch fails", expression: "1 ? 1 / 0 : x", wantErr: true},
+		{name: "evaluated operand fails", expression: "1 && 1 / 0", wantErr: true},
+		{name: "failing condition", expression: "1 / 0 ? x : 2", wantErr: true},
+		{name: "nested failure reaches root", expression: "x * (1 / 0 + 1)", wantErr: true},
+		{name: "nested failure in taken branch", expression: "1 ? sqrt(1 / 0) + x : 2", wantErr: true},
+		{name: "nested failure in evaluated operand", expression: "(x ? 1 : 2) + sqrt(1 / 0)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			simplified, err := PartialEvaluate(postfix, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("PartialEvaluate() expected error, got %q", simplified)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("PartialEvaluate() unexpected error: %v", err)
+				return
+			}
+
+			if !slices.Equal(simplified, tt.expected) {
+				t.Errorf("PartialEvaluate(%q) = %q, expected %q", tt.expression, simplified, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPartialEvaluateMatchesEvaluate tests that simplified output evaluates like the original
+func TestPartialEvaluateMatchesEvaluate(t *testing.T) {
+	postfix := []string{"0.1", "3", "*", "x", "*", "2", "3", "^", "y", "/", "+"}
+
+	simplified, err := PartialEvaluate(postfix)
+	if err != nil {
+		t.Fatalf("PartialEvaluate() unexpected error: %v", err)
+	}
+
+	for _, vars := range []map[string]float64{{"x": 1, "y": 2}, {"x": -3.5, "y": 0.25}} {
+		expected, err := EvaluateWithVars(postfix, vars)
+		if err != nil {
+			t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+		}
+		result, err := EvaluateWithVars(simplified, vars)
+		if err != nil {
+			t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+		}
+		if result != expected {
+			t.Errorf("simplified = %v, original = %v for %v", result, expected, vars)
+		}
+	}
+}
+
+// TestPartialEvaluateInvalid tests errors for invalid postfix input
+func TestPartialEvaluateInvalid(t *testing.T) {
+	_, err := PartialEvaluate([]string{"x", "1", "0", "/", "+"})
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Index != 3 {
+		t.Errorf("PartialEvaluate() error = %v, expected *TokenError at index 3", err)
+	}
+
+	for _, input := range [][]string{{}, {"x", "+"}, {"x", "y"}} {
+		if _, err := PartialEvaluate(input); err == nil {
+			t.Errorf("PartialEvaluate(%q) expected error, got nil", input)
+		}
+	}
+}
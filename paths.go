@@ -0,0 +1,76 @@
+package shuntingyard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MapResolver returns a VariableResolver that looks variables up in nested
+// maps, such as JSON decoded into a map[string]any. A dotted path like
+// "order.total" descends through the maps one name at a time:
+//
+//	var data map[string]any
+//	json.Unmarshal([]byte(`{"order": {"total": 120}}`), &data)
+//	expr, err := Compile("order.total * 1.21", WithVariableResolver(MapResolver(data)))
+//	...
+//	result, err := expr.Eval(nil)
+//
+// Numbers of any Go numeric type resolve to their value, json.Number too, and
+// booleans to 1 or 0. A path that isn't in data is undefined; a path that runs
+// into a value other than a map, or a value that isn't a number or a boolean,
+// fails the evaluation.
+func MapResolver(data map[string]any) VariableResolver {
+	return VariableResolverFunc(func(path string) (float64, error) {
+		current := data
+		for {
+			name, rest, nested := strings.Cut(path, ".")
+			value, ok := current[name]
+			if !ok {
+				return 0, ErrUndefinedVariable
+			}
+			if !nested {
+				return toNumber(value)
+			}
+			if current, ok = value.(map[string]any); !ok {
+				return 0, fmt.Errorf("'%s' holds %T, not a map", name, value)
+			}
+			path = rest
+		}
+	})
+}
+
+// toNumber converts a value found by MapResolver to a number.
+func toNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case bool:
+		return fromBool(v), nil
+	}
+	return 0, fmt.Errorf("value of type %T is not a number", value)
+}
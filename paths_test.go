@@ -0,0 +1,89 @@
+package shuntingyard
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestMapResolver tests resolving dotted paths against nested maps through the full pipeline
+func TestMapResolver(t *testing.T) {
+	var data map[string]any
+	err := json.Unmarshal([]byte(`{
+		"order": {"total": 120, "items": 3, "express": true, "customer": {"age": 42}},
+		"user": {"name": "ada", "age": 36},
+		"limit": 100
+	}`), &data)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+	}
+	data["counts"] = map[string]any{"int": 7, "uint8": uint8(8), "number": json.Number("2.5")}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    error
+	}{
+		{name: "dotted path", expression: "order.total * 2", expected: 240},
+		{name: "deeply nested path", expression: "order.customer.age", expected: 42},
+		{name: "top-level name", expression: "order.total > limit", expected: 1},
+		{name: "boolean field", expression: "order.express ? 10 : 0", expected: 10},
+		{name: "paths in a rule", expression: "user.age >= 18 && order.items > 2", expected: 1},
+		{name: "Go numeric types", expression: "counts.int + counts.uint8 + counts.number", expected: 17.5},
+		{name: "path as argument", expression: "max(order.items, user.age)", expected: 36},
+		{name: "missing path falls back to constant", expression: "round(pi)", expected: 3},
+
+		// Error cases
+		{name: "missing field", expression: "order.discount", wantErr: ErrUndefinedVariable},
+		{name: "missing parent", expression: "invoice.total", wantErr: ErrUndefinedVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithVariableResolver(MapResolver(data)))
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("eval() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMapResolverErrorMessages tests the errors for paths that resolve to the wrong kind of value
+func TestMapResolverErrorMessages(t *testing.T) {
+	data := map[string]any{"user": map[string]any{"name": "ada"}, "limit": 100}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: "user.name + 1", expected: "variable 'user.name': value of type string is not a number"},
+		{expression: "limit.max", expected: "variable 'limit.max': 'limit' holds int, not a map"},
+		{expression: "user", expected: "variable 'user': value of type map[string]interface {} is not a number"},
+		{expression: "user.age", expected: "undefined variable 'user.age'"},
+	}
+
+	for _, tt := range tests {
+		_, err := eval(tt.expression, WithVariableResolver(MapResolver(data)))
+		if err == nil {
+			t.Errorf("eval(%q) expected error, got nil", tt.expression)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("eval(%q) error = %q, expected %q", tt.expression, err.Error(), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// lineColumn returns the 1-based line and column of the byte offset pos in
+// expression. Columns count characters, as editors do, rather than bytes.
+func lineColumn(expression string, pos int) (line, column int) {
+	before := expression[:min(pos, len(expression))]
+	line = strings.Count(before, "\n") + 1
+	column = utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return line, column
+}
+
+// at describes where the byte offset pos is in expression for an error
+// message: as "line:column" when the expression spans several lines, such as
+// a formula wrapped for readability, and as "position pos" otherwise.
+func at(expression string, pos int) string {
+	if !strings.Contains(expression, "\n") {
+		return fmt.Sprintf("position %d", pos)
+	}
+	line, column := lineColumn(expression, pos)
+	return fmt.Sprintf("%d:%d", line, column)
+}
+
+// invalidChar returns the error for the invalid character ch at the byte
+// offset pos in expression.
+func (c config) invalidChar(expression string, ch rune, pos int) *InvalidCharError {
+	line, column := lineColumn(expression, pos)
+	return &InvalidCharError{
+		Rune:      ch,
+		Pos:       pos,
+		Line:      line,
+		Column:    column,
+		multiline: strings.Contains(expression, "\n"),
+		redacted:  c.redactErrors,
+	}
+}
+
+// location describes where token i is for an error message: where it starts
+// in the source when spans are known, otherwise its index in the token slice.
+func (c config) location(spans []Span, i int) string {
+	if spans != nil {
+		return at(c.source, spans[i].Start)
+	}
+	return fmt.Sprintf("token %d", i)
+}
+
+// Caret renders the line of expression that err points into with a caret
+// under the offending character or token, for showing an error in a terminal
+// or log:
+//
+//	2 * (3 + 4))
+//	           ^
+//
+// err may be any error returned for expression, wrapped or not, by Scan,
+// Parse, Evaluate or the functions that take an expression. An error that
+// only gives the index of a token, as Parse does without spans, is located by
+// scanning and parsing expression again with opts, which should be those the
+// error was produced with. Caret returns "" if err doesn't point at a token.
+func Caret(expression string, err error, opts ...Option) string {
+	pos, ok := errorPos(expression, err, opts)
+	if !ok {
+		return ""
+	}
+
+	start := strings.LastIndexByte(expression[:pos], '\n') + 1
+	end := strings.IndexByte(expression[start:], '\n')
+	if end < 0 {
+		end = len(expression)
+	} else {
+		end += start
+	}
+
+	// Tabs are kept so the caret lines up whatever the tab width
+	var b strings.Builder
+	b.WriteString(expression[start:end])
+	b.WriteByte('\n')
+	for _, r := range expression[start:pos] {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteByte('^')
+	return b.String()
+}
+
+// errorPos returns the byte offset in expression that err points at.
+func errorPos(expression string, err error, opts []Option) (int, bool) {
+	var charErr *InvalidCharError
+	var commentErr *commentError
+	var parseErr *ParseError
+	var tokenErr *TokenError
+
+	pos := -1
+	switch {
+	case errors.As(err, &charErr):
+		pos = charErr.Pos
+	case errors.As(err, &commentErr):
+		pos = commentErr.pos
+	case errors.As(err, &parseErr) && parseErr.Pos >= 0:
+		pos = parseErr.Pos
+	case errors.As(err, &parseErr):
+		tokens, spans, err := ScanSpans(expression, opts...)
+		if err != nil || parseErr.Index > len(tokens) {
+			return 0, false
+		}
+		if parseErr.Index == len(tokens) {
+			pos = spans[len(spans)-1].End
+		} else {
+			pos = spans[parseErr.Index].Start
+		}
+	case errors.As(err, &tokenErr):
+		tokens, spans, err := ScanSpans(expression, opts...)
+		if err != nil {
+			return 0, false
+		}
+		_, postfixSpans, err := ParseSpans(tokens, spans, opts...)
+		if err != nil || tokenErr.Index >= len(postfixSpans) {
+			return 0, false
+		}
+		pos = postfixSpans[tokenErr.Index].Start
+	}
+	return pos, 0 <= pos && pos <= len(expression)
+}
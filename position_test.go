@@ -0,0 +1,189 @@
+package shuntingyard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMultiLineErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func() error
+		expected string
+	}{
+		{
+			name:     "single line keeps the byte position",
+			run:      func() error { _, err := Scan("1 + $"); return err },
+			expected: "invalid character '$' at position 4",
+		},
+		{
+			name:     "invalid character",
+			run:      func() error { _, err := Scan("1 +\n  2 $ 3"); return err },
+			expected: "invalid character '$' at 2:5",
+		},
+		{
+			name:     "columns count characters",
+			run:      func() error { _, err := Scan("1\n× 2 × $"); return err },
+			expected: "invalid character '$' at 2:7",
+		},
+		{
+			name:     "unterminated comment",
+			run:      func() error { _, err := Scan("1 +\n\t/* open"); return err },
+			expected: "unterminated comment at 2:2",
+		},
+		{
+			name:     "parse error",
+			run:      func() error { _, err := Variables("(price\n  * quantity\n"); return err },
+			expected: "mismatched parentheses: unmatched '(' at 1:1",
+		},
+		{
+			name:     "evaluated expression",
+			run:      func() error { _, err := EvalInt("2 *\n  (3 + 4))"); return err },
+			expected: "mismatched parentheses: unmatched ')' at 2:10",
+		},
+		{
+			name:     "session assignment",
+			run:      func() error { _, err := NewSession().Eval("\n2 = 3"); return err },
+			expected: "cannot assign to a number at 2:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("error = %v, expected %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInvalidCharErrorLineColumn(t *testing.T) {
+	expression := strings.Repeat("1 +\n", 300) + "  2 $ 3"
+
+	_, err := Scan(expression)
+	var scanErr *InvalidCharError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("Scan() error = %v, expected *InvalidCharError", err)
+	}
+	if scanErr.Line != 301 || scanErr.Column != 5 {
+		t.Errorf("Scan() error at %d:%d, expected 301:5", scanErr.Line, scanErr.Column)
+	}
+
+	// A Scanner reports the same position however the input is split
+	_, err = scanAll(NewScanner(iotest.OneByteReader(strings.NewReader(expression))))
+	var streamErr *InvalidCharError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Next() error = %v, expected *InvalidCharError", err)
+	}
+	if *streamErr != *scanErr {
+		t.Errorf("Next() error = %+v, expected %+v", *streamErr, *scanErr)
+	}
+
+	_, err = scanAll(NewScanner(iotest.HalfReader(strings.NewReader(expression[:len(expression)-4] + "/* 3"))))
+	if err == nil || err.Error() != "unterminated comment at 301:4" {
+		t.Errorf("Next() error = %v, expected unterminated comment at 301:4", err)
+	}
+}
+
+func TestCaret(t *testing.T) {
+	parseErr := func(expression string) error {
+		tokens, err := Scan(expression)
+		if err != nil {
+			return err
+		}
+		_, err = Parse(tokens)
+		return err
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		run        func(expression string) error
+		expected   string
+	}{
+		{
+			name:       "unmatched parenthesis",
+			expression: "2 * (3 + 4))",
+			run:        func(e string) error { _, err := EvalInt(e); return err },
+			expected:   "2 * (3 + 4))\n           ^",
+		},
+		{
+			name:       "parse error located by token index",
+			expression: "max(1, 2) +   * 3",
+			run:        parseErr,
+			expected:   "max(1, 2) +   * 3\n              ^",
+		},
+		{
+			name:       "end of expression",
+			expression: "1 +",
+			run:        parseErr,
+			expected:   "1 +\n   ^",
+		},
+		{
+			name:       "invalid character",
+			expression: "6 × $",
+			run:        func(e string) error { _, err := Scan(e); return err },
+			expected:   "6 × $\n    ^",
+		},
+		{
+			name:       "unterminated comment",
+			expression: "1 /* total",
+			run:        func(e string) error { _, err := Scan(e); return err },
+			expected:   "1 /* total\n  ^",
+		},
+		{
+			name:       "evaluation error",
+			expression: "1 + 2 / (3 - 3)",
+			run:        func(e string) error { _, err := eval(e); return err },
+			expected:   "1 + 2 / (3 - 3)\n      ^",
+		},
+		{
+			name:       "line of a multi-line expression",
+			expression: "price *\n\t(quantity +) ",
+			run:        func(e string) error { _, err := Variables(e); return err },
+			expected:   "\t(quantity +) \n\t           ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(tt.expression)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if got := Caret(tt.expression, err); got != tt.expected {
+				t.Errorf("Caret() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("error without a position", func(t *testing.T) {
+		if got := Caret("1 +", errors.New("boom")); got != "" {
+			t.Errorf("Caret() = %q, expected \"\"", got)
+		}
+	})
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	tokens, spans, err := ScanSpans("(1 + 2")
+	if err != nil {
+		t.Fatalf("ScanSpans() unexpected error: %v", err)
+	}
+
+	_, _, err = ParseSpans(tokens, spans)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("ParseSpans() error = %v, expected *ParseError", err)
+	}
+	if parseErr.Index != 0 || parseErr.Pos != 0 {
+		t.Errorf("ParseSpans() error at token %d, position %d, expected 0, 0", parseErr.Index, parseErr.Pos)
+	}
+
+	_, err = Parse(tokens)
+	if !errors.As(err, &parseErr) || parseErr.Pos != -1 {
+		t.Errorf("Parse() error = %+v, expected *ParseError without a position", err)
+	}
+}
@@ -0,0 +1,116 @@
+package shuntingyard
+
+import "fmt"
+
+// ToPrefix converts postfix tokens, as returned by Parse, to prefix (Polish)
+// notation, which writes each operator before its operands:
+//
+//	ToPrefix([]string{"2", "3", "4", "*", "+"}) // ["+", "2", "*", "3", "4"]
+//
+// The tokens are those of the postfix expression, "neg" for unary minus, "?:"
+// for the conditional and "max/2" for a call included, so that every operator
+// says how many operands follow it.
+//
+// Returns the prefix tokens, or a *TokenError for tokens that don't form an
+// expression.
+func ToPrefix(postfixTokens []string, opts ...Option) ([]string, error) {
+	prefix, _, err := reorder(postfixTokens, newConfig(opts), true)
+	return prefix, err
+}
+
+// FromPrefix converts prefix (Polish) tokens, as returned by ToPrefix, to
+// postfix tokens for Evaluate or any other evaluator.
+//
+// Returns the postfix tokens, or a *TokenError locating the prefix token of
+// an operator without enough operands.
+func FromPrefix(prefixTokens []string, opts ...Option) ([]string, error) {
+	postfix, _, err := reorder(prefixTokens, newConfig(opts), false)
+	return postfix, err
+}
+
+// EvaluatePrefix evaluates prefix (Polish) tokens, as returned by ToPrefix,
+// like Evaluate evaluates the postfix tokens of the same expression:
+//
+//	EvaluatePrefix([]string{"+", "2", "*", "3", "4"}) // 14
+//
+// It compiles them as Compile does, so registered functions should be pure.
+// Errors locate prefix tokens.
+func EvaluatePrefix(prefixTokens []string, opts ...Option) (float64, error) {
+	cfg := newConfig(opts)
+	postfix, origin, err := reorder(prefixTokens, cfg, false)
+	if err != nil {
+		return 0, err
+	}
+	program, err := compileProgram(postfix, origin, cfg)
+	if err != nil {
+		return 0, err
+	}
+	return program.run(cfg)
+}
+
+// reorder converts postfix tokens to prefix ones, or with toPrefix false
+// prefix tokens to postfix ones, which are the same tokens with each operator
+// moved from after its operands to before them, or back. origin[i] is the
+// index in tokens of the token i of the result.
+func reorder(tokens []string, cfg config, toPrefix bool) (result []string, origin []int, err error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("empty expression")
+	}
+
+	// Each entry is a subexpression, with the index in tokens of each of its
+	// tokens
+	type entry struct {
+		tokens []string
+		origin []int
+	}
+	stack := make([]entry, 0, len(tokens)/2+1)
+
+	for k := range tokens {
+		// Prefix tokens are read from the end, where the operands of an
+		// operator are complete before it is reached
+		i := k
+		if !toPrefix {
+			i = len(tokens) - 1 - k
+		}
+		token := tokens[i]
+
+		argc := 0
+		switch {
+		case cfg.isBinaryOperator(token):
+			argc = 2
+		case isUnaryOperator(token):
+			argc = 1
+		case token == ternary:
+			argc = 3
+		case cfg.isCall(token):
+			_, argc, _ = splitCall(token)
+		}
+		if len(stack) < argc {
+			return nil, nil, &TokenError{Index: i, Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)}
+		}
+		operands := stack[len(stack)-argc:]
+
+		var e entry
+		if toPrefix {
+			e = entry{tokens: []string{token}, origin: []int{i}}
+			for _, operand := range operands {
+				e.tokens = append(e.tokens, operand.tokens...)
+				e.origin = append(e.origin, operand.origin...)
+			}
+		} else {
+			// Read backwards, the first operand is on top of the stack
+			for j := len(operands) - 1; j >= 0; j-- {
+				e.tokens = append(e.tokens, operands[j].tokens...)
+				e.origin = append(e.origin, operands[j].origin...)
+			}
+			e.tokens = append(e.tokens, token)
+			e.origin = append(e.origin, i)
+		}
+		stack = append(stack[:len(stack)-argc], e)
+	}
+
+	if len(stack) != 1 {
+		return nil, nil, fmt.Errorf("invalid expression: too many operands")
+	}
+	return stack[0].tokens, stack[0].origin, nil
+}
@@ -0,0 +1,109 @@
+package shuntingyard
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestToPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{name: "single number", input: []string{"42"}, expected: []string{"42"}},
+		{name: "precedence", input: []string{"2", "3", "4", "*", "+"}, expected: []string{"+", "2", "*", "3", "4"}},
+		{name: "grouping", input: []string{"2", "3", "+", "4", "*"}, expected: []string{"*", "+", "2", "3", "4"}},
+		{name: "operand order kept", input: []string{"8", "4", "2", "-", "-"}, expected: []string{"-", "8", "-", "4", "2"}},
+		{name: "unary minus", input: []string{"x", "neg", "2", "^"}, expected: []string{"^", "neg", "x", "2"}},
+		{name: "call", input: []string{"1", "x", "2", "*", "max/2"}, expected: []string{"max/2", "1", "*", "x", "2"}},
+		{name: "conditional", input: []string{"a", "b", "c", "?:"}, expected: []string{"?:", "a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToPrefix(tt.input)
+			if err != nil {
+				t.Fatalf("ToPrefix() unexpected error: %v", err)
+			}
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("ToPrefix() = %v, expected %v", result, tt.expected)
+			}
+			postfix, err := FromPrefix(result)
+			if err != nil {
+				t.Fatalf("FromPrefix() unexpected error: %v", err)
+			}
+			if !slices.Equal(postfix, tt.input) {
+				t.Errorf("FromPrefix() = %v, expected %v", postfix, tt.input)
+			}
+		})
+	}
+}
+
+func TestPrefixErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		convert func([]string, ...Option) ([]string, error)
+		input   []string
+		index   int // -1 for an error without a token
+	}{
+		{name: "empty postfix", convert: ToPrefix, input: []string{}, index: -1},
+		{name: "postfix missing operand", convert: ToPrefix, input: []string{"1", "+"}, index: 1},
+		{name: "postfix too many operands", convert: ToPrefix, input: []string{"1", "2"}, index: -1},
+		{name: "empty prefix", convert: FromPrefix, input: []string{}, index: -1},
+		{name: "prefix missing operand", convert: FromPrefix, input: []string{"*", "+", "1", "2"}, index: 0},
+		{name: "prefix too many operands", convert: FromPrefix, input: []string{"+", "1", "2", "3"}, index: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.convert(tt.input)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			var tokenErr *TokenError
+			if errors.As(err, &tokenErr) != (tt.index >= 0) || tt.index >= 0 && tokenErr.Index != tt.index {
+				t.Errorf("error = %v, expected index %d", err, tt.index)
+			}
+		})
+	}
+}
+
+func TestEvaluatePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		opts     []Option
+		expected float64
+		wantErr  string
+		index    int
+	}{
+		{name: "precedence", input: []string{"+", "2", "*", "3", "4"}, expected: 14},
+		{name: "operand order", input: []string{"-", "8", "-", "4", "2"}, expected: 6},
+		{name: "call and unary minus", input: []string{"max/2", "neg", "1", "^", "2", "3"}, expected: 8},
+		{name: "conditional", input: []string{"?:", "<", "1", "2", "10", "20"}, expected: 10},
+		{name: "constant", input: []string{"*", "2", "pi"}, expected: 2 * 3.141592653589793},
+		{name: "division by zero", input: []string{"+", "1", "/", "2", "0"}, wantErr: "division by zero", index: 2},
+		{name: "undefined variable", input: []string{"*", "2", "x"}, wantErr: "undefined variable 'x'", index: 2},
+		{name: "redacted invalid number", input: []string{"+", "1", "1e"}, opts: []Option{WithRedactedErrors()}, wantErr: "invalid number at token 2", index: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvaluatePrefix(tt.input, tt.opts...)
+			if tt.wantErr != "" {
+				var tokenErr *TokenError
+				if !errors.As(err, &tokenErr) || err.Error() != tt.wantErr || tokenErr.Index != tt.index {
+					t.Fatalf("EvaluatePrefix() error = %v, expected %q at token %d", err, tt.wantErr, tt.index)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluatePrefix() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("EvaluatePrefix() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// maxExactShift bounds the shift counts EvaluateRat applies.
+const maxExactShift = 1 << 16
+
+// errResultTooLarge is the error for a power or left shift whose exact result
+// could be longer than maxExactBits, which bounds chains of them that each
+// stay within maxExactExponent or maxExactShift.
+var errResultTooLarge = errors.New("result too large")
+
+// EvaluateRat computes the result of a postfix (RPN) expression in exact
+// rational arithmetic, so that "0.1 + 0.2 == 0.3" holds and division never
+// loses precision. Literals are read as the decimals they are written as, and
+// variables as the shortest decimal form of their float64 values. Render the
+// result as a decimal with a chosen number of places with its FloatString
+// method, as in r.FloatString(2).
+//
+// Bitwise operators and shifts work on integers of any size. Exponents must be
+// integers of at most maxExactExponent in magnitude, and a power or left shift
+// whose result would be longer than maxExactBits fails as too large. Only the functions with
+// exact rational results are available: abs, min, max, sum, floor, ceil,
+// trunc, round, if, coalesce and clamp, unless shadowed by WithFunction.
+//
+// Returns the exact result or an error for invalid expressions, division or
+// modulo by zero, and anything without an exact rational value, such as
+// "2 ^ 0.5", "inf", or pi. Failures caused by a specific token are reported as
+// a *TokenError holding its index.
+func EvaluateRat(postfixTokens []string, opts ...Option) (*big.Rat, error) {
+	cfg := newConfig(opts)
+	return evaluateWith(postfixTokens, Arithmetic[*big.Rat](ratArithmetic{cfg: &cfg}), cfg)
+}
+
+// ratArithmetic implements EvaluateRat's exact rational arithmetic.
+type ratArithmetic struct {
+	cfg *config
+}
+
+func (ratArithmetic) ParseLiteral(token string) (*big.Rat, error) {
+	if _, err := strconv.ParseFloat(token, 64); err != nil {
+		return nil, ErrInvalidNumber
+	}
+	value, ok := new(big.Rat).SetString(token)
+	if !ok {
+		return nil, &literalError{token: token, problem: "has no exact rational value"}
+	}
+	return value, nil
+}
+
+func (ratArithmetic) Add(a, b *big.Rat) (*big.Rat, error) { return ratBinary("+", a, b) }
+func (ratArithmetic) Sub(a, b *big.Rat) (*big.Rat, error) { return ratBinary("-", a, b) }
+func (ratArithmetic) Mul(a, b *big.Rat) (*big.Rat, error) { return ratBinary("*", a, b) }
+func (ratArithmetic) Div(a, b *big.Rat) (*big.Rat, error) { return ratBinary("/", a, b) }
+
+func (ratArithmetic) Binary(operator string, a, b *big.Rat) (*big.Rat, error) {
+	return ratBinary(operator, a, b)
+}
+
+func (ratArithmetic) Unary(operator string, a *big.Rat) (*big.Rat, error) {
+	return ratUnary(operator, a)
+}
+
+func (ratArithmetic) Truth(a *big.Rat) bool { return a.Sign() != 0 }
+
+// Variable reads a value as the shortest decimal form of its float64, except
+// for the irrational built-in constants.
+func (r ratArithmetic) Variable(name string, value float64) (*big.Rat, error) {
+	if r.cfg.builtinConstant(name) {
+		return nil, fmt.Errorf("constant '%s' has no exact rational value", name)
+	}
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	if !ok {
+		return nil, fmt.Errorf("variable '%s' has no exact rational value", name)
+	}
+	return exact, nil
+}
+
+func (ratArithmetic) Round(a *big.Rat, scale int, mode RoundingMode) (*big.Rat, error) {
+	return ratRoundMode(a, int64(scale), mode), nil
+}
+
+func (r ratArithmetic) invoke(fn function, token string, args []*big.Rat) (*big.Rat, error) {
+	return r.cfg.exactCall(fn, token, args, "rational")
+}
+
+// exactCall computes a call of fn to one of ratFunctions, for an evaluation
+// whose kind of exact result names the function missing from them.
+func (c config) exactCall(fn function, token string, args []*big.Rat, kind string) (*big.Rat, error) {
+	name, _, _ := splitCall(token)
+	exact, ok := ratFunctions[name]
+	if _, registered := c.functions[name]; !ok || registered || fn.operator {
+		return nil, fmt.Errorf("%s has no exact %s result", fn.describe(token), kind)
+	}
+	result, err := exact(args)
+	if err != nil {
+		return nil, fmt.Errorf("function '%s': %w", name, err)
+	}
+	return result, nil
+}
+
+// ratBinary applies a binary operator to two rationals.
+func ratBinary(operator string, a, b *big.Rat) (*big.Rat, error) {
+	switch operator {
+	case "+":
+		return new(big.Rat).Add(a, b), nil
+	case "-":
+		return new(big.Rat).Sub(a, b), nil
+	case "*":
+		return new(big.Rat).Mul(a, b), nil
+	case "/":
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return new(big.Rat).Quo(a, b), nil
+	case "%":
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("modulo by zero")
+		}
+		return ratMod(a, b), nil
+	case "^":
+		if a.Sign() == 0 && b.Sign() < 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if b.IsInt() && b.Num().IsInt64() {
+			e := b.Num().Int64()
+			if e >= -maxExactExponent && e <= maxExactExponent && ratBitLen(a)*int(max(e, -e)) > maxExactBits {
+				return nil, errResultTooLarge
+			}
+		}
+		result := ratPow(a, b)
+		if result == nil {
+			return nil, fmt.Errorf("exponent must be an integer between -%d and %d", maxExactExponent, maxExactExponent)
+		}
+		return result, nil
+	case "<", ">", "<=", ">=", "==", "!=":
+		return ratCompare(operator, a, b), nil
+	case "&&", "||":
+		return ratLogic(operator, a, b), nil
+	}
+
+	// Bitwise operators and shifts
+	if !a.IsInt() || !b.IsInt() {
+		return nil, fmt.Errorf("bitwise operator '%s' requires integer operands", operator)
+	}
+	x, y := a.Num(), b.Num()
+	switch operator {
+	case "&":
+		return new(big.Rat).SetInt(new(big.Int).And(x, y)), nil
+	case "|":
+		return new(big.Rat).SetInt(new(big.Int).Or(x, y)), nil
+	case "xor":
+		return new(big.Rat).SetInt(new(big.Int).Xor(x, y)), nil
+	}
+	if y.Sign() < 0 {
+		return nil, fmt.Errorf("negative shift count")
+	}
+	if y.Cmp(big.NewInt(maxExactShift)) > 0 {
+		return nil, fmt.Errorf("shift count exceeds %d", maxExactShift)
+	}
+	if operator == "<<" {
+		if x.BitLen()+int(y.Int64()) > maxExactBits {
+			return nil, errResultTooLarge
+		}
+		return new(big.Rat).SetInt(new(big.Int).Lsh(x, uint(y.Uint64()))), nil
+	}
+	return new(big.Rat).SetInt(new(big.Int).Rsh(x, uint(y.Uint64()))), nil
+}
+
+// ratUnary applies a unary operator, in its postfix form, to a rational.
+func ratUnary(operator string, a *big.Rat) (*big.Rat, error) {
+	switch operator {
+	case negate:
+		return new(big.Rat).Neg(a), nil
+	case "!":
+		return new(big.Rat).SetFloat64(fromBool(a.Sign() == 0)), nil
+	}
+	if !a.IsInt() {
+		return nil, fmt.Errorf("bitwise operator '%s' requires integer operands", operator)
+	}
+	return new(big.Rat).SetInt(new(big.Int).Not(a.Num())), nil
+}
+
+// ratFunctions are the built-in functions EvaluateRat computes exactly. Their
+// argument counts are checked against the function table beforehand.
+var ratFunctions = map[string]func(args []*big.Rat) (*big.Rat, error){
+	"abs": func(args []*big.Rat) (*big.Rat, error) { return new(big.Rat).Abs(args[0]), nil },
+	"min": func(args []*big.Rat) (*big.Rat, error) {
+		return ratExtreme(args, -1), nil
+	},
+	"max": func(args []*big.Rat) (*big.Rat, error) {
+		return ratExtreme(args, 1), nil
+	},
+	"sum": func(args []*big.Rat) (*big.Rat, error) {
+		total := new(big.Rat)
+		for _, arg := range args {
+			total.Add(total, arg)
+		}
+		return total, nil
+	},
+	"floor": func(args []*big.Rat) (*big.Rat, error) { return ratFloor(args[0]), nil },
+	"ceil": func(args []*big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Neg(ratFloor(new(big.Rat).Neg(args[0]))), nil
+	},
+	"trunc": func(args []*big.Rat) (*big.Rat, error) {
+		return new(big.Rat).SetInt(new(big.Int).Quo(args[0].Num(), args[0].Denom())), nil
+	},
+	"round": func(args []*big.Rat) (*big.Rat, error) {
+		if len(args) == 1 {
+			return ratRound(args[0], 0), nil
+		}
+		if !args[1].IsInt() {
+			return nil, errors.New("digits must be an integer")
+		}
+		digits := args[1].Num()
+		if digits.CmpAbs(big.NewInt(maxRoundDigits)) > 0 {
+			return nil, fmt.Errorf("digits must be between -%d and %d", maxRoundDigits, maxRoundDigits)
+		}
+		return ratRound(args[0], digits.Int64()), nil
+	},
+	"if": func(args []*big.Rat) (*big.Rat, error) {
+		if args[0].Sign() != 0 {
+			return args[1], nil
+		}
+		return args[2], nil
+	},
+	// Rationals are never missing, so coalesce always yields its first argument
+	"coalesce": func(args []*big.Rat) (*big.Rat, error) { return args[0], nil },
+	"clamp": func(args []*big.Rat) (*big.Rat, error) {
+		x, lo, hi := args[0], args[1], args[2]
+		if lo.Cmp(hi) > 0 {
+			return nil, errors.New("lower bound exceeds upper bound")
+		}
+		return ratExtreme([]*big.Rat{lo, ratExtreme([]*big.Rat{x, hi}, -1)}, 1), nil
+	},
+}
+
+// ratExtreme returns the largest of args for sign 1 and the smallest for -1.
+func ratExtreme(args []*big.Rat, sign int) *big.Rat {
+	result := args[0]
+	for _, arg := range args[1:] {
+		if arg.Cmp(result) == sign {
+			result = arg
+		}
+	}
+	return result
+}
+
+// ratFloor returns the largest integer not greater than x.
+func ratFloor(x *big.Rat) *big.Rat {
+	// Euclidean division by the positive denominator rounds toward -∞
+	return new(big.Rat).SetInt(new(big.Int).Div(x.Num(), x.Denom()))
+}
@@ -0,0 +1,99 @@
+package shuntingyard
+
+import "testing"
+
+// TestEvaluateRat tests exact rational evaluation through the full pipeline
+func TestEvaluateRat(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   string // RatString
+		wantErr    bool
+	}{
+		{name: "decimal sum is exact", expression: "0.1 + 0.2", expected: "3/10"},
+		{name: "exact equality", expression: "0.1 + 0.2 == 0.3", expected: "1"},
+		{name: "division keeps precision", expression: "1 / 3 * 3", expected: "1"},
+		{name: "repeating decimal", expression: "2 / 3", expected: "2/3"},
+		{name: "integer power", expression: "(2 / 3) ^ 3", expected: "8/27"},
+		{name: "negative power", expression: "2 ^ -3", expected: "1/8"},
+		{name: "modulo", expression: "7.5 % 2", expected: "3/2"},
+		{name: "modulo keeps sign of dividend", expression: "-7 % 3", expected: "-1"},
+		{name: "comparison of close values", expression: "1 / 3 < 0.3333333333333333", expected: "0"},
+		{name: "logical operators", expression: "!(0.1 + 0.2 - 0.3) && 1", expected: "1"},
+		{name: "conditional", expression: "0.1 * 3 == 0.3 ? 1 / 7 : 0", expected: "1/7"},
+		{name: "bitwise beyond 64 bits", expression: "(1 << 70) | 1", expected: "1180591620717411303425"},
+		{name: "bitwise NOT", expression: "~5 & 255", expected: "250"},
+		{name: "arithmetic right shift", expression: "-9 >> 1", expected: "-5"},
+		{name: "large integers", expression: "99999999999999999999 + 1", expected: "100000000000000000000"},
+		{name: "functions", expression: "max(1 / 3, 0.3) + abs(-1 / 6)", expected: "1/2"},
+		{name: "rounding", expression: "round(2 / 3, 2) + floor(-1.5) + ceil(1.2) + trunc(-1.7)", expected: "-33/100"},
+		{name: "round half away from zero", expression: "round(1.005, 2) + round(-2.5)", expected: "-199/100"},
+		{name: "clamp", expression: "clamp(5 / 2, 0, 2)", expected: "2"},
+		{name: "variable", expression: "x / 3", opts: []Option{WithConstant("x", 0.1)}, expected: "1/30"},
+
+		// Error cases
+		{name: "division by zero", expression: "1 / (0.1 + 0.2 - 0.3)", wantErr: true},
+		{name: "fractional exponent", expression: "2 ^ 0.5", wantErr: true},
+		{name: "zero to negative power", expression: "0 ^ -1", wantErr: true},
+		{name: "infinite literal", expression: "inf + 1", wantErr: true},
+		{name: "irrational constant", expression: "pi * 2", wantErr: true},
+		{name: "inexact function", expression: "sqrt(4)", wantErr: true},
+		{name: "bitwise on fraction", expression: "0.5 | 1", wantErr: true},
+		{name: "huge shift", expression: "1 << 100000", wantErr: true},
+		{name: "chained powers", expression: "((3 ^ 1024) ^ 1024) ^ 1024 > 1", wantErr: true},
+		{name: "chained shifts", expression: "1 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536 << 65536", wantErr: true},
+		{name: "shift of a power", expression: "((3 ^ 1024) ^ 645) << 65536", wantErr: true},
+		{name: "undefined variable", expression: "y + 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			result, err := EvaluateRat(postfix, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateRat() expected error, got %s", result.RatString())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateRat() unexpected error: %v", err)
+				return
+			}
+
+			if result.RatString() != tt.expected {
+				t.Errorf("EvaluateRat(%q) = %s, expected %s", tt.expression, result.RatString(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluateRatDecimal tests rendering exact results as decimals with a chosen scale
+func TestEvaluateRatDecimal(t *testing.T) {
+	result, err := EvaluateRat([]string{"2", "3", "/"})
+	if err != nil {
+		t.Fatalf("EvaluateRat() unexpected error: %v", err)
+	}
+	if s := result.FloatString(4); s != "0.6667" {
+		t.Errorf("FloatString(4) = %q, expected %q", s, "0.6667")
+	}
+}
+
+// TestEvaluateRatRegisteredFunction tests that registered functions are unavailable in exact arithmetic
+func TestEvaluateRatRegisteredFunction(t *testing.T) {
+	opt := WithFunction("abs", 1, func(args ...float64) (float64, error) { return args[0], nil })
+	if _, err := EvaluateRat([]string{"1", "abs/1"}, opt); err == nil {
+		t.Error("EvaluateRat() expected error for registered function, got nil")
+	}
+}
@@ -0,0 +1,229 @@
+package shuntingyard
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// Result is the outcome of an evaluation that tracks exactness alongside the
+// float64 value. Value is always set and is identical to what Evaluate returns.
+// Exact holds the exact rational result when every literal could be represented
+// exactly and every operation stayed exact; it is nil otherwise.
+type Result struct {
+	Value float64
+	Exact *big.Rat
+}
+
+// String renders the result as an exact fraction (e.g., "1/3") when one is
+// available, falling back to the shortest float64 representation otherwise.
+func (r Result) String() string {
+	if r.Exact != nil {
+		return r.Exact.RatString()
+	}
+	return strconv.FormatFloat(r.Value, 'g', -1, 64)
+}
+
+// resultOperand is a stack entry for EvaluateResult. exact is nil once the
+// value has left exact rational arithmetic.
+type resultOperand struct {
+	value float64
+	exact *big.Rat
+}
+
+// EvaluateResult computes the result of a postfix (RPN) expression like Evaluate,
+// additionally carrying an exact rational alongside the float64 value so callers
+// can display "1/3" instead of 0.3333333333333333 when the computation stayed exact.
+//
+// Returns the Result or an error for invalid expressions, division by zero, modulo by zero,
+// or non-integer bitwise operands.
+func EvaluateResult(postfixTokens []string, opts ...Option) (Result, error) {
+	cfg := newConfig(opts)
+	result, err := evaluateWith(postfixTokens, Arithmetic[resultOperand](resultArithmetic{cfg: &cfg}), cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Value: result.value, Exact: result.exact}, nil
+}
+
+// resultArithmetic implements EvaluateResult's arithmetic, computing each
+// value as Evaluate does and the exact result alongside it.
+type resultArithmetic struct {
+	cfg *config
+}
+
+func (resultArithmetic) ParseLiteral(token string) (resultOperand, error) {
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return resultOperand{}, ErrInvalidNumber
+	}
+	// Literals such as "inf" have no rational form
+	exact, ok := new(big.Rat).SetString(token)
+	if !ok {
+		exact = nil
+	}
+	return resultOperand{value: value, exact: exact}, nil
+}
+
+func (r resultArithmetic) Add(a, b resultOperand) (resultOperand, error) { return r.Binary("+", a, b) }
+func (r resultArithmetic) Sub(a, b resultOperand) (resultOperand, error) { return r.Binary("-", a, b) }
+func (r resultArithmetic) Mul(a, b resultOperand) (resultOperand, error) { return r.Binary("*", a, b) }
+func (r resultArithmetic) Div(a, b resultOperand) (resultOperand, error) { return r.Binary("/", a, b) }
+
+func (resultArithmetic) Binary(operator string, a, b resultOperand) (resultOperand, error) {
+	value, err := applyBinary(operator, a.value, b.value)
+	if err != nil || a.exact == nil || b.exact == nil {
+		return resultOperand{value: value}, err
+	}
+
+	result := resultOperand{value: value}
+	switch operator {
+	case "+":
+		result.exact = new(big.Rat).Add(a.exact, b.exact)
+	case "-":
+		result.exact = new(big.Rat).Sub(a.exact, b.exact)
+	case "*":
+		result.exact = new(big.Rat).Mul(a.exact, b.exact)
+	case "/", "%":
+		// A literal too small for float64 can be zero as a float while still
+		// being non-zero as a rational, or vice versa
+		if b.exact.Sign() == 0 {
+			break
+		}
+		if operator == "/" {
+			result.exact = new(big.Rat).Quo(a.exact, b.exact)
+		} else {
+			result.exact = ratMod(a.exact, b.exact)
+		}
+	case "^":
+		result.exact = ratPow(a.exact, b.exact)
+	case "<", ">", "<=", ">=", "==", "!=":
+		// The value compares the floats, exactly as Evaluate does, while the
+		// exact result compares the rationals
+		result.exact = ratCompare(operator, a.exact, b.exact)
+	case "&&", "||":
+		result.exact = ratLogic(operator, a.exact, b.exact)
+	default:
+		// Bitwise results are integers, which float64 holds exactly
+		result.exact = new(big.Rat).SetFloat64(value)
+	}
+	return result, nil
+}
+
+func (resultArithmetic) Unary(operator string, a resultOperand) (resultOperand, error) {
+	value, err := applyUnary(operator, a.value)
+	if err != nil || a.exact == nil {
+		return resultOperand{value: value}, err
+	}
+
+	result := resultOperand{value: value}
+	switch operator {
+	case negate:
+		result.exact = new(big.Rat).Neg(a.exact)
+	case "!":
+		truth, _ := applyUnary(operator, float64(a.exact.Sign()))
+		result.exact = new(big.Rat).SetFloat64(truth)
+	default:
+		result.exact = new(big.Rat).SetFloat64(value)
+	}
+	return result, nil
+}
+
+// Truth chooses a branch by the float condition, as Evaluate chooses it; the
+// branch brings its exactness along.
+func (resultArithmetic) Truth(a resultOperand) bool { return a.value != 0 }
+
+// Variable makes a variable exact in the decimal form it would be written in,
+// like a literal; the built-in constants are irrational.
+func (r resultArithmetic) Variable(name string, value float64) (resultOperand, error) {
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	if !ok || r.cfg.builtinConstant(name) {
+		exact = nil
+	}
+	return resultOperand{value: value, exact: exact}, nil
+}
+
+// Round rounds the value as Evaluate does and the exact result alongside it.
+func (resultArithmetic) Round(a resultOperand, scale int, mode RoundingMode) (resultOperand, error) {
+	result := resultOperand{value: roundFloat(a.value, scale, mode, 64)}
+	if a.exact != nil {
+		result.exact = ratRoundMode(a.exact, int64(scale), mode)
+	}
+	return result, nil
+}
+
+// invoke calls a function on the values; functions leave exact arithmetic.
+func (resultArithmetic) invoke(fn function, token string, args []resultOperand) (resultOperand, error) {
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		values[i] = arg.value
+	}
+	value, err := fn.invoke(token, values)
+	return resultOperand{value: value}, err
+}
+
+// ratMod returns the exact remainder of x / y with the sign of x, matching
+// math.Mod: x - y*trunc(x/y).
+func ratMod(x, y *big.Rat) *big.Rat {
+	quotient := new(big.Rat).Quo(x, y)
+	truncated := new(big.Int).Quo(quotient.Num(), quotient.Denom())
+
+	product := new(big.Rat).Mul(y, new(big.Rat).SetInt(truncated))
+	return product.Sub(x, product)
+}
+
+// ratCompare applies a comparison operator to two rationals, returning 1 when
+// it holds and 0 otherwise.
+func ratCompare(operator string, x, y *big.Rat) *big.Rat {
+	// Comparing the sign of x - y against zero reuses the float64 comparison
+	cmp, _ := applyBinary(operator, float64(x.Cmp(y)), 0)
+	return new(big.Rat).SetFloat64(cmp)
+}
+
+// ratLogic applies a logical operator to two rationals, returning 1 when it
+// holds and 0 otherwise. Only whether each operand is zero matters.
+func ratLogic(operator string, x, y *big.Rat) *big.Rat {
+	truth, _ := applyBinary(operator, float64(x.Sign()), float64(y.Sign()))
+	return new(big.Rat).SetFloat64(truth)
+}
+
+// maxExactExponent bounds the integer exponents EvaluateResult raises exactly.
+const maxExactExponent = 1024
+
+// maxExactBits bounds the bit length of the numerator and denominator of an
+// exact power. The exponent bound alone doesn't bound the result, as chained
+// powers such as "((10 ^ 1024) ^ 1024) ^ 1024" multiply their exponents.
+const maxExactBits = 1 << 20
+
+// ratPow returns x raised to the power n exactly, or nil when n is not an
+// integer within ±maxExactExponent, x is zero with a negative exponent, or the
+// result could be longer than maxExactBits.
+func ratPow(x, n *big.Rat) *big.Rat {
+	if !n.IsInt() || !n.Num().IsInt64() {
+		return nil
+	}
+	e := n.Num().Int64()
+	if e > maxExactExponent || e < -maxExactExponent {
+		return nil
+	}
+	if ratBitLen(x)*int(max(e, -e)) > maxExactBits {
+		return nil
+	}
+
+	if e < 0 {
+		if x.Sign() == 0 {
+			return nil
+		}
+		x = new(big.Rat).Inv(x)
+		e = -e
+	}
+
+	num := new(big.Int).Exp(x.Num(), big.NewInt(e), nil)
+	den := new(big.Int).Exp(x.Denom(), big.NewInt(e), nil)
+	return new(big.Rat).SetFrac(num, den)
+}
+
+// ratBitLen returns the bit length of the longer of the numerator and
+// denominator of x.
+func ratBitLen(x *big.Rat) int {
+	return max(x.Num().BitLen(), x.Denom().BitLen())
+}
@@ -0,0 +1,269 @@
+package shuntingyard
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestEvaluateResult tests evaluation with exact rational tracking
+func TestEvaluateResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		expected  float64
+		exact     string // expected RatString, empty when the result is inexact
+		formatted string
+		wantErr   bool
+	}{
+		{
+			name:      "repeating decimal stays exact",
+			input:     []string{"1", "3", "/"},
+			expected:  0.3333333333333333,
+			exact:     "1/3",
+			formatted: "1/3",
+		},
+		{
+			name:      "integer result",
+			input:     []string{"2", "3", "4", "*", "+"},
+			expected:  14,
+			exact:     "14",
+			formatted: "14",
+		},
+		{
+			name:      "decimal literals are exact",
+			input:     []string{"0.1", "0.2", "+"},
+			expected:  0.30000000000000004,
+			exact:     "3/10",
+			formatted: "3/10",
+		},
+		{
+			name:      "fractions cancel",
+			input:     []string{"1", "3", "/", "3", "*"},
+			expected:  1,
+			exact:     "1",
+			formatted: "1",
+		},
+		{
+			name:      "integer power stays exact",
+			input:     []string{"2", "3", "/", "3", "^"},
+			expected:  0.2962962962962962,
+			exact:     "8/27",
+			formatted: "8/27",
+		},
+		{
+			name:      "negative integer power stays exact",
+			input:     []string{"2", "0", "3", "-", "^"},
+			expected:  0.125,
+			exact:     "1/8",
+			formatted: "1/8",
+		},
+		{
+			name:      "fractional power is inexact",
+			input:     []string{"2", "0.5", "^"},
+			formatted: "1.4142135623730951",
+		},
+		{
+			name:      "modulo stays exact",
+			input:     []string{"7", "3", "/", "0.5", "%"},
+			expected:  0.3333333333333333,
+			exact:     "1/3",
+			formatted: "1/3",
+		},
+		{
+			name:      "modulo keeps sign of dividend",
+			input:     []string{"0", "7", "-", "3", "%"},
+			expected:  -1,
+			exact:     "-1",
+			formatted: "-1",
+		},
+		{
+			name:      "negation stays exact",
+			input:     []string{"1", "3", "/", "neg"},
+			expected:  -0.3333333333333333,
+			exact:     "-1/3",
+			formatted: "-1/3",
+		},
+		{
+			name:      "comparison",
+			input:     []string{"1", "3", "/", "0.3", ">"},
+			expected:  1,
+			exact:     "1",
+			formatted: "1",
+		},
+		{
+			name:      "exact equality where floats differ",
+			input:     []string{"0.1", "0.2", "+", "0.3", "=="},
+			expected:  0,
+			exact:     "1",
+			formatted: "1",
+		},
+		{
+			name:      "logical operators",
+			input:     []string{"1", "3", "/", "!", "2", "||"},
+			expected:  1,
+			exact:     "1",
+			formatted: "1",
+		},
+		{
+			name:      "conditional keeps exactness of chosen branch",
+			input:     []string{"1", "1", "3", "/", "2", "0.5", "^", "?:"},
+			expected:  0.3333333333333333,
+			exact:     "1/3",
+			formatted: "1/3",
+		},
+		{
+			name:      "bitwise operators",
+			input:     []string{"12", "10", "xor", "~"},
+			expected:  -7,
+			exact:     "-7",
+			formatted: "-7",
+		},
+		{
+			name:      "shift",
+			input:     []string{"3", "4", "<<"},
+			expected:  48,
+			exact:     "48",
+			formatted: "48",
+		},
+		{
+			name:      "function is inexact",
+			input:     []string{"16", "sqrt/1"},
+			formatted: "4",
+		},
+		{
+			name:      "infinite literal is inexact",
+			input:     []string{"inf", "2", "+"},
+			formatted: "+Inf",
+		},
+		{
+			name:    "division by zero",
+			input:   []string{"10", "0", "/"},
+			wantErr: true,
+		},
+		{
+			name:    "modulo by zero",
+			input:   []string{"10", "0", "%"},
+			wantErr: true,
+		},
+		{
+			name:    "bitwise operator on fraction",
+			input:   []string{"1", "3", "/", "1", "&"},
+			wantErr: true,
+		},
+		{
+			name:    "negation without operand",
+			input:   []string{"neg"},
+			wantErr: true,
+		},
+		{
+			name:    "insufficient operands",
+			input:   []string{"2", "+"},
+			wantErr: true,
+		},
+		{
+			name:    "too many operands",
+			input:   []string{"2", "3", "4", "+"},
+			wantErr: true,
+		},
+		{
+			name:    "empty expression",
+			input:   []string{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			input:   []string{"abc", "2", "+"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvaluateResult(tt.input)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateResult() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateResult() unexpected error: %v", err)
+				return
+			}
+
+			if tt.exact == "" {
+				if result.Exact != nil {
+					t.Errorf("EvaluateResult() exact = %s, expected nil", result.Exact.RatString())
+				}
+			} else {
+				if result.Exact == nil {
+					t.Errorf("EvaluateResult() exact = nil, expected %s", tt.exact)
+				} else if result.Exact.RatString() != tt.exact {
+					t.Errorf("EvaluateResult() exact = %s, expected %s", result.Exact.RatString(), tt.exact)
+				}
+				if !almostEqual(result.Value, tt.expected, 0.0000001) {
+					t.Errorf("EvaluateResult() value = %v, expected %v", result.Value, tt.expected)
+				}
+			}
+
+			if result.String() != tt.formatted {
+				t.Errorf("Result.String() = %q, expected %q", result.String(), tt.formatted)
+			}
+		})
+	}
+}
+
+// TestEvaluateResultChainedPowers tests that powers too large to compute
+// exactly fall back to the float value rather than growing without bound
+func TestEvaluateResultChainedPowers(t *testing.T) {
+	postfix := []string{"10", "1024", "^", "1024", "^", "1024", "^"}
+
+	done := make(chan struct{})
+	var result Result
+	var err error
+	go func() {
+		defer close(done)
+		result, err = EvaluateResult(postfix)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("EvaluateResult() did not return")
+	}
+	if err != nil {
+		t.Fatalf("EvaluateResult() unexpected error: %v", err)
+	}
+	if result.Exact != nil {
+		t.Errorf("EvaluateResult() exact = %d bits, expected nil", ratBitLen(result.Exact))
+	}
+	if !math.IsInf(result.Value, 1) {
+		t.Errorf("EvaluateResult() value = %v, expected +Inf", result.Value)
+	}
+}
+
+// TestEvaluateResultMatchesEvaluate tests that the float value is identical to Evaluate
+func TestEvaluateResultMatchesEvaluate(t *testing.T) {
+	postfix := []string{"10.5", "2", "/", "3.5", "+"}
+
+	expected, err := Evaluate(postfix)
+	if err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+
+	result, err := EvaluateResult(postfix)
+	if err != nil {
+		t.Fatalf("EvaluateResult() unexpected error: %v", err)
+	}
+
+	if result.Value != expected {
+		t.Errorf("EvaluateResult() value = %v, Evaluate() = %v", result.Value, expected)
+	}
+	if result.Exact.Cmp(big.NewRat(35, 4)) != 0 {
+		t.Errorf("EvaluateResult() exact = %s, expected 35/4", result.Exact.RatString())
+	}
+}
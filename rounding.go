@@ -0,0 +1,116 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// maxRoundDigits bounds the digits argument of round; beyond it every float64
+// is already rounded (or rounds to zero), whatever the digits.
+const maxRoundDigits = 400
+
+// round rounds x half away from zero, to a whole number or, with a second
+// argument, to that many decimal places; negative digits round to tens,
+// hundreds, and so on. Rounding to decimal places works on the shortest
+// decimal form of x, so round(1.005, 2) is 1.01 as written rather than the
+// 1.00 that the nearest binary value, 1.00499999999999989..., would give.
+func round(args ...float64) (float64, error) {
+	x := args[0]
+	if len(args) == 1 {
+		return math.Round(x), nil
+	}
+
+	digits := args[1]
+	if digits != math.Trunc(digits) {
+		return 0, errors.New("digits must be an integer")
+	}
+	if digits == 0 || math.IsInf(x, 0) || math.IsNaN(x) {
+		return math.Round(x), nil
+	}
+	digits = max(-maxRoundDigits, min(digits, maxRoundDigits))
+
+	return roundFloat(x, int(digits), RoundHalfUp, 64), nil
+}
+
+// RoundingMode selects how WithRounding rounds a value that lies between two
+// representable results.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds halves away from zero, as round and the ROUND of
+	// spreadsheets do: 2.5 becomes 3 and -2.5 becomes -3.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds halves to the even neighbor, as banks and IEEE 754
+	// do, so that rounding errors don't accumulate in one direction: 2.5
+	// becomes 2 and 3.5 becomes 4.
+	RoundHalfEven
+	// RoundTowardZero drops the digits beyond the scale: 2.7 becomes 2 and
+	// -2.7 becomes -2.
+	RoundTowardZero
+)
+
+// WithRounding rounds the result of an evaluation to scale decimal places, or
+// to tens, hundreds, and so on for a negative scale, using mode. As for round,
+// a float64 is rounded on its shortest decimal form, so that 1.005 rounds to
+// 1.01 with RoundHalfUp. EvaluateDecimal gives the result exactly scale
+// places. See WithIntermediateRounding to round every step as well.
+func WithRounding(scale int, mode RoundingMode) Option {
+	return func(c *config) {
+		c.rounding = true
+		c.roundScale = max(-maxRoundDigits, min(scale, maxRoundDigits))
+		c.roundMode = mode
+	}
+}
+
+// WithIntermediateRounding makes WithRounding also round the result of every
+// operation and function call, as a ledger that rounds each line item does,
+// rather than only the final result.
+func WithIntermediateRounding() Option {
+	return func(c *config) {
+		c.roundIntermediates = true
+	}
+}
+
+// roundFloat rounds x to digits decimal places on its shortest decimal form,
+// which holds bitSize bits. Infinities and NaN are left as they are.
+func roundFloat(x float64, digits int, mode RoundingMode, bitSize int) float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return x
+	}
+	value, _ := new(big.Rat).SetString(strconv.FormatFloat(x, 'g', -1, bitSize))
+	result, _ := ratRoundMode(value, int64(digits), mode).Float64()
+	return math.Copysign(result, x)
+}
+
+// ratRound rounds x half away from zero to the given number of decimal
+// places, or to tens, hundreds, and so on for negative digits.
+func ratRound(x *big.Rat, digits int64) *big.Rat {
+	return ratRoundMode(x, digits, RoundHalfUp)
+}
+
+// ratRoundMode rounds x to the given number of decimal places using mode.
+func ratRoundMode(x *big.Rat, digits int64, mode RoundingMode) *big.Rat {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(max(digits, -digits)), nil))
+	value := new(big.Rat).Set(x)
+	if digits > 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	// Truncate the scaled value, then step away from zero if the dropped
+	// fraction rounds up
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	half := remainder.Lsh(remainder.Abs(remainder), 1).Cmp(value.Denom())
+	if mode == RoundHalfUp && half >= 0 || mode == RoundHalfEven && (half > 0 || half == 0 && quotient.Bit(0) == 1) {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	value.SetInt(quotient)
+
+	if digits > 0 {
+		return value.Quo(value, scale)
+	}
+	return value.Mul(value, scale)
+}
@@ -0,0 +1,218 @@
+package shuntingyard
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+// TestRounding tests the rounding functions through the full pipeline
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "round half away from zero", expression: "round(2.5)", expected: 3},
+		{name: "round negative half away from zero", expression: "round(-2.5)", expected: -3},
+		{name: "round down", expression: "round(2.4)", expected: 2},
+		{name: "round to two places", expression: "round(3.14159, 2)", expected: 3.14},
+		{name: "round as written", expression: "round(1.005, 2)", expected: 1.01},
+		{name: "round negative as written", expression: "round(-2.675, 2)", expected: -2.68},
+		{name: "round to zero places", expression: "round(7.5, 0)", expected: 8},
+		{name: "round to hundreds", expression: "round(1250, -2)", expected: 1300},
+		{name: "round to more places than given", expression: "round(0.1, 5)", expected: 0.1},
+		{name: "round to many places", expression: "round(0.1, 1000)", expected: 0.1},
+		{name: "round to many negative places", expression: "round(12345, -1000)", expected: 0},
+		{name: "invoice line", expression: "round(19.99 * 3 * 1.21, 2)", expected: 72.56},
+		{name: "floor", expression: "floor(2.7)", expected: 2},
+		{name: "floor negative", expression: "floor(-2.2)", expected: -3},
+		{name: "ceil", expression: "ceil(2.2)", expected: 3},
+		{name: "ceil negative", expression: "ceil(-2.7)", expected: -2},
+		{name: "trunc", expression: "trunc(2.7)", expected: 2},
+		{name: "trunc negative", expression: "trunc(-2.7)", expected: -2},
+
+		// Error cases
+		{name: "fractional digits", expression: "round(2.5, 0.5)", wantErr: true},
+		{name: "round without arguments", expression: "round()", wantErr: true},
+		{name: "round with too many arguments", expression: "round(1, 2, 3)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			// Rounded values must come out exactly
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRoundingSpecialValues tests that rounding leaves infinities, NaN and signed zeros alone
+func TestRoundingSpecialValues(t *testing.T) {
+	for _, x := range []float64{math.Inf(1), math.Inf(-1)} {
+		if result, _ := round(x, 2); result != x {
+			t.Errorf("round(%v, 2) = %v, expected %v", x, result, x)
+		}
+	}
+	if result, _ := round(math.NaN(), 2); !math.IsNaN(result) {
+		t.Errorf("round(NaN, 2) = %v, expected NaN", result)
+	}
+	if result, _ := round(-0.001, 2); result != 0 || !math.Signbit(result) {
+		t.Errorf("round(-0.001, 2) = %v, expected -0", result)
+	}
+}
+
+// TestWithRounding tests rounding results through the options, across evaluators
+func TestWithRounding(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		eval       func(postfix []string, opts ...Option) (string, error)
+		expected   string
+		wantErr    bool
+	}{
+		{name: "half up", expression: "2.345 * 1", opts: []Option{WithRounding(2, RoundHalfUp)}, expected: "2.35"},
+		{name: "half even down", expression: "2.345 * 1", opts: []Option{WithRounding(2, RoundHalfEven)}, expected: "2.34"},
+		{name: "half even up", expression: "2.355 * 1", opts: []Option{WithRounding(2, RoundHalfEven)}, expected: "2.36"},
+		{name: "half even above half", expression: "2.3451", opts: []Option{WithRounding(2, RoundHalfEven)}, expected: "2.35"},
+		{name: "toward zero", expression: "-2.349", opts: []Option{WithRounding(2, RoundTowardZero)}, expected: "-2.34"},
+		{name: "negative half up", expression: "-2.5", opts: []Option{WithRounding(0, RoundHalfUp)}, expected: "-3"},
+		{name: "negative half even", expression: "-2.5", opts: []Option{WithRounding(0, RoundHalfEven)}, expected: "-2"},
+		{name: "tens", expression: "1234", opts: []Option{WithRounding(-1, RoundHalfUp)}, expected: "1230"},
+		{name: "binary artifact", expression: "0.1 + 0.2", opts: []Option{WithRounding(2, RoundHalfUp)}, expected: "0.3"},
+		{name: "final result only", expression: "1.005 * 1 + 1.005 * 1", opts: []Option{WithRounding(2, RoundHalfUp)}, expected: "2.01"},
+		{
+			name:       "every line item",
+			expression: "1.005 * 1 + 1.005 * 1",
+			opts:       []Option{WithRounding(2, RoundHalfUp), WithIntermediateRounding()},
+			expected:   "2.02",
+		},
+		{
+			name:       "intermediates without rounding",
+			expression: "1.005 * 1 + 1.005 * 1",
+			opts:       []Option{WithIntermediateRounding()},
+			expected:   "2.01",
+		},
+		{
+			name:       "decimal scale",
+			expression: "19.99 * 1.21 + 1",
+			opts:       []Option{WithRounding(2, RoundHalfEven)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateDecimal(postfix, opts...)
+				return result.String(), err
+			},
+			expected: "25.19",
+		},
+		{
+			name:       "decimal padded to scale",
+			expression: "3",
+			opts:       []Option{WithRounding(2, RoundHalfUp)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateDecimal(postfix, opts...)
+				return result.String(), err
+			},
+			expected: "3.00",
+		},
+		{
+			name:       "rational",
+			expression: "2 / 3",
+			opts:       []Option{WithRounding(3, RoundTowardZero)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateRat(postfix, opts...)
+				return result.RatString(), err
+			},
+			expected: "333/500",
+		},
+		{
+			name:       "integer to hundreds",
+			expression: "1250 + 0",
+			opts:       []Option{WithRounding(-2, RoundHalfEven)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateInt(postfix, opts...)
+				return strconv.FormatInt(result, 10), err
+			},
+			expected: "1200",
+		},
+		{
+			name:       "integer rounding overflow",
+			expression: "127",
+			opts:       []Option{WithRounding(-1, RoundHalfUp)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateAs[int8](postfix, opts...)
+				return strconv.Itoa(int(result)), err
+			},
+			wantErr: true,
+		},
+		{
+			name:       "unsupported by custom arithmetic",
+			expression: "1 + 2",
+			opts:       []Option{WithRounding(2, RoundHalfUp)},
+			eval: func(postfix []string, opts ...Option) (string, error) {
+				result, err := EvaluateWith(postfix, modularArithmetic{}, opts...)
+				return strconv.Itoa(result), err
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			eval := tt.eval
+			if eval == nil {
+				eval = func(postfix []string, opts ...Option) (string, error) {
+					result, err := Evaluate(postfix, opts...)
+					return strconv.FormatFloat(result, 'g', -1, 64), err
+				}
+			}
+			result, err := eval(postfix, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("%s = %s, expected %s", tt.expression, result, tt.expected)
+			}
+		})
+	}
+
+	// The shortcut for trivial expressions honours rounding too
+	result, err := EvalInt("7 / 2", WithRounding(0, RoundHalfEven))
+	if err != nil || result != 4 {
+		t.Errorf("EvalInt() = %d, %v, expected 4", result, err)
+	}
+}
@@ -0,0 +1,206 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// scannerChunkSize is the number of bytes a Scanner reads at a time.
+const scannerChunkSize = 4096
+
+// scannerLookahead is how far from the end of the buffered input a Scanner
+// reports a Scan error without reading on. Closer to the end, more input may
+// complete the text in error, as "2e5" completes "2e" and "&&" completes "&".
+const scannerLookahead = 64
+
+// Scanner tokenizes an expression read from an io.Reader incrementally, for
+// expressions too large to hold in memory or arriving through a pipe. Only the
+// input not yet tokenized is buffered.
+type Scanner struct {
+	r   io.Reader
+	cfg config
+
+	// buf holds the input from offset on that hasn't been tokenized yet;
+	// offset is on the given 0-based line, after column characters of it
+	buf    []byte
+	offset int
+	line   int
+	column int
+	eof    bool
+
+	// tokens counts the tokens tokenized so far, which WithMaxTokens limits
+	// over the whole input
+	tokens  int
+	pending []Token
+	err     error
+}
+
+// NewScanner returns a Scanner reading an expression from r. opts configure
+// the tokenization as they do for Scan; WithMaxTokens limits the tokens of the
+// whole input, Next failing with a *LimitError after the last one allowed.
+func NewScanner(r io.Reader, opts ...Option) *Scanner {
+	return &Scanner{r: r, cfg: newConfig(opts)}
+}
+
+// Next returns the next token, classified and located as by ScanTokens, with
+// Pos the byte offset in the whole input. After the last token it returns
+// io.EOF; an input without any token is ErrEmptyExpression, as for Scan.
+//
+// Returns the Scan error, with the position counted from the start of the
+// input, or the error reading from r. Once Next fails it keeps returning the
+// same error.
+func (s *Scanner) Next() (Token, error) {
+	for len(s.pending) == 0 && s.err == nil {
+		s.err = s.fill()
+	}
+	if len(s.pending) == 0 {
+		return Token{}, s.err
+	}
+	token := s.pending[0]
+	s.pending = s.pending[1:]
+	return token, nil
+}
+
+// fill tokenizes the buffered input, reading more from r as long as the
+// tokens at its end may continue past it.
+func (s *Scanner) fill() error {
+	if !s.eof {
+		s.buf = slices.Grow(s.buf, scannerChunkSize)
+		n, err := s.r.Read(s.buf[len(s.buf):cap(s.buf)])
+		s.buf = s.buf[:len(s.buf)+n]
+		if errors.Is(err, io.EOF) {
+			s.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Spaces and comments before the next token are dropped as they are
+	// read, rather than scanned again with every read
+	input := string(s.buf)
+	if n := s.blankPrefix(input); n > 0 {
+		s.advance(input, n)
+		input = input[n:]
+	}
+
+	// The token limit holds for the whole input, so it is checked below
+	// rather than for each buffer
+	cfg := s.cfg
+	cfg.maxTokens = 0
+	values, spans, err := scan(input, cfg, true)
+	if err != nil {
+		var charErr *InvalidCharError
+		var commentErr *commentError
+		switch {
+		case errors.Is(err, ErrEmptyExpression) && s.eof && s.tokens > 0:
+			return io.EOF
+		case errors.As(err, &charErr) && (s.eof || charErr.Pos+scannerLookahead < len(input)):
+			located := *charErr
+			located.Line, located.Column = s.lineColumn(input, charErr.Pos)
+			located.Pos += s.offset
+			located.multiline = located.multiline || s.line > 0
+			return &located
+		case errors.As(err, &commentErr) && s.eof:
+			located := commentError{pos: s.offset + commentErr.pos, where: fmt.Sprintf("position %d", s.offset+commentErr.pos)}
+			if s.line > 0 || strings.Contains(input, "\n") {
+				line, column := s.lineColumn(input, commentErr.pos)
+				located.where = fmt.Sprintf("%d:%d", line, column)
+			}
+			return &located
+		case s.eof:
+			return err
+		}
+		return nil
+	}
+
+	// The last two tokens may continue in the input not read yet, as a
+	// number continues with more digits or joins an uncertainty after it;
+	// they are scanned again with it
+	keep := len(values)
+	if !s.eof {
+		keep -= 2
+		if keep <= 0 {
+			return nil
+		}
+	}
+	for i, value := range values[:keep] {
+		if s.cfg.maxTokens > 0 && s.tokens == s.cfg.maxTokens {
+			return &LimitError{Limit: "token count", Max: s.cfg.maxTokens}
+		}
+		s.pending = append(s.pending, Token{Kind: s.cfg.tokenKind(value), Value: value, Pos: s.offset + spans[i].Start})
+		s.tokens++
+	}
+
+	consumed := len(input)
+	if keep < len(values) {
+		consumed = spans[keep].Start
+	}
+	s.advance(input, consumed)
+	return nil
+}
+
+// advance drops the first consumed bytes of the buffered input, counting the
+// lines and columns they span.
+func (s *Scanner) advance(input string, consumed int) {
+	if newline := strings.LastIndexByte(input[:consumed], '\n'); newline >= 0 {
+		s.line += strings.Count(input[:consumed], "\n")
+		s.column = utf8.RuneCountInString(input[newline+1 : consumed])
+	} else {
+		s.column += utf8.RuneCountInString(input[:consumed])
+	}
+	s.buf = s.buf[:copy(s.buf, s.buf[consumed:])]
+	s.offset += consumed
+}
+
+// blankPrefix returns the length of the spaces and comments input starts
+// with, short of a comment that more input may complete or change, such as a
+// "/" that may begin one or a line comment whose line hasn't ended.
+func (s *Scanner) blankPrefix(input string) int {
+	i := 0
+	for i < len(input) {
+		rest := input[i:]
+		// Registered symbols may begin like a comment, as "//" does
+		if _, n := s.cfg.matchBitwise(rest); n > 0 {
+			return i
+		}
+		if _, n := s.cfg.matchOperator(rest); n > 0 {
+			return i
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return i
+			}
+			i += 2 + end + len("*/")
+		case r == '#' || strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				return i
+			}
+			i += end
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// lineColumn returns the 1-based line and column in the whole input of the
+// byte offset pos in the buffered input.
+func (s *Scanner) lineColumn(input string, pos int) (line, column int) {
+	line, column = lineColumn(input, pos)
+	if line == 1 {
+		column += s.column
+	}
+	return line + s.line, column
+}
@@ -0,0 +1,157 @@
+package shuntingyard
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// scanAll collects the tokens a Scanner returns up to io.EOF.
+func scanAll(s *Scanner) ([]Token, error) {
+	var tokens []Token
+	for {
+		token, err := s.Next()
+		if errors.Is(err, io.EOF) {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+	}{
+		{name: "arithmetic", expression: "2 * (x + 1.5) - 10 / 4"},
+		{name: "multi-character operators", expression: "a <= b && c != d || e >> 2"},
+		{name: "numbers across reads", expression: "123456 + 6.02e23 - 0xFF + order.total"},
+		{name: "uncertainty", expression: "9.81   ±   0.02 * 2"},
+		{name: "typographic operators", expression: "6 × 2 − 1"},
+		{name: "function call", expression: "max(1, sqrt(4), pi)"},
+		{name: "localized", expression: "1 234,56 + max(1; 2)", opts: []Option{WithNumberFormat(NumberFormat{Decimal: ',', Thousands: ' '})}},
+		{name: "comments", expression: "1 /* first\nterm */ + 2 # second term\n* 3 // last"},
+		{name: "trailing spaces", expression: "  1 + 2   "},
+		{name: "long input", expression: strings.Repeat("12.5 * x + ", 1000) + "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := ScanTokens(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("ScanTokens() unexpected error: %v", err)
+			}
+
+			readers := map[string]io.Reader{
+				"whole":      strings.NewReader(tt.expression),
+				"one byte":   iotest.OneByteReader(strings.NewReader(tt.expression)),
+				"half reads": iotest.HalfReader(strings.NewReader(tt.expression)),
+			}
+			for name, r := range readers {
+				tokens, err := scanAll(NewScanner(r, tt.opts...))
+				if err != nil {
+					t.Fatalf("%s: Next() unexpected error: %v", name, err)
+				}
+				if !slices.Equal(tokens, expected) {
+					t.Errorf("%s: Next() = %v, expected %v", name, tokens, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestScannerErrors(t *testing.T) {
+	t.Run("invalid character", func(t *testing.T) {
+		expression := strings.Repeat("1 + ", 2000) + "$"
+		s := NewScanner(iotest.HalfReader(strings.NewReader(expression)))
+		tokens, err := scanAll(s)
+		var charErr *InvalidCharError
+		if !errors.As(err, &charErr) {
+			t.Fatalf("Next() error = %v, expected *InvalidCharError", err)
+		}
+		if charErr.Pos != len(expression)-1 {
+			t.Errorf("InvalidCharError.Pos = %d, expected %d", charErr.Pos, len(expression)-1)
+		}
+		if len(tokens) == 0 {
+			t.Errorf("Next() returned no tokens before the error")
+		}
+		if _, again := s.Next(); again != err {
+			t.Errorf("Next() after error = %v, expected %v", again, err)
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		_, err := NewScanner(strings.NewReader("   ")).Next()
+		if !errors.Is(err, ErrEmptyExpression) {
+			t.Errorf("Next() error = %v, expected ErrEmptyExpression", err)
+		}
+	})
+
+	t.Run("token limit over the stream", func(t *testing.T) {
+		// Each read holds fewer tokens than the limit, which the whole
+		// input exceeds
+		expression := strings.Repeat("1 + ", 2000) + "1"
+		tokens, err := scanAll(NewScanner(iotest.HalfReader(strings.NewReader(expression)), WithMaxTokens(100)))
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("Next() error = %v, expected *LimitError", err)
+		}
+		if len(tokens) != 100 {
+			t.Errorf("Next() returned %d tokens before the error, expected 100", len(tokens))
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		_, err := NewScanner(iotest.ErrReader(readErr)).Next()
+		if !errors.Is(err, readErr) {
+			t.Errorf("Next() error = %v, expected %v", err, readErr)
+		}
+	})
+}
+
+// TestScannerBlankPrefix tests that spaces and comments before the first token
+// are dropped as they are read rather than buffered and scanned again
+func TestScannerBlankPrefix(t *testing.T) {
+	for name, prefix := range map[string]string{
+		"spaces":         strings.Repeat(" ", 1<<20),
+		"line comments":  strings.Repeat("# note\n", 1<<17),
+		"block comments": strings.Repeat("/* note */\n", 1<<17),
+	} {
+		t.Run(name, func(t *testing.T) {
+			expression := prefix + "1 + x"
+			s := NewScanner(strings.NewReader(expression))
+
+			var tokens []Token
+			for {
+				token, err := s.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next() unexpected error: %v", err)
+				}
+				tokens = append(tokens, token)
+				if cap(s.buf) > 4*scannerChunkSize {
+					t.Fatalf("Scanner buffered %d bytes", cap(s.buf))
+				}
+			}
+
+			expected := []Token{
+				{Kind: TokenNumber, Value: "1", Pos: len(prefix)},
+				{Kind: TokenOperator, Value: "+", Pos: len(prefix) + 2},
+				{Kind: TokenName, Value: "x", Pos: len(prefix) + 4},
+			}
+			if !slices.Equal(tokens, expected) {
+				t.Errorf("Next() = %v, expected %v", tokens, expected)
+			}
+		})
+	}
+}
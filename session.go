@@ -0,0 +1,98 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+)
+
+// Session evaluates a sequence of expressions that share variables, as in a
+// calculator session or a multi-step formula sheet. An expression of the form
+// "name = expression" assigns its result to name, and later expressions can
+// use it:
+//
+//	s := NewSession()
+//	s.Eval("subtotal = 3 * 19.99")
+//	s.Eval("tax = subtotal * 0.21")
+//	total, err := s.Eval("subtotal + tax")
+//
+// A Session is safe for concurrent use; each Eval sees the assignments of the
+// Evals that completed before it.
+type Session struct {
+	cfg config
+
+	mu   sync.Mutex
+	vars map[string]float64
+}
+
+// NewSession creates a Session without variables. opts apply to every
+// expression it evaluates.
+func NewSession(opts ...Option) *Session {
+	return &Session{cfg: newConfig(opts), vars: make(map[string]float64)}
+}
+
+// Eval evaluates an infix expression against the session's variables and
+// returns its result. For an assignment it also stores the result under the
+// assigned name, replacing any earlier value; a variable may shadow a
+// constant but not a function. A failing expression assigns nothing.
+func (s *Session) Eval(expression string) (float64, error) {
+	cfg := s.cfg
+	cfg.source = expression
+
+	tokens, spans, err := scan(expression, cfg, true)
+	if err != nil {
+		return 0, err
+	}
+
+	name := ""
+	if len(tokens) >= 2 && tokens[1] == "=" {
+		name = tokens[0]
+		if err := s.checkAssignable(name, at(expression, spans[0].Start)); err != nil {
+			return 0, err
+		}
+		if len(tokens) == 2 {
+			return 0, fmt.Errorf("expected expression after '=' at %s", at(expression, spans[1].Start))
+		}
+		tokens, spans = tokens[2:], spans[2:]
+	}
+
+	postfix, _, err := parse(tokens, spans, cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg.vars = s.vars
+	result, err := evaluate(postfix, cfg)
+	if err != nil {
+		return 0, err
+	}
+	if name != "" {
+		s.vars[name] = result
+	}
+	return result, nil
+}
+
+// checkAssignable reports an error if name, found where, can't be assigned to.
+func (s *Session) checkAssignable(name, where string) error {
+	if _, err := strconv.ParseFloat(name, 64); err == nil || !isVariableName(name) || s.cfg.isGrammarToken(name) {
+		if s.cfg.isGrammarToken(name) {
+			return fmt.Errorf("cannot assign to '%s' at %s", name, where)
+		}
+		return fmt.Errorf("cannot assign to a number at %s", where)
+	}
+	if s.cfg.isFunction(name) {
+		return fmt.Errorf("cannot assign to function '%s' at %s", name, where)
+	}
+	return nil
+}
+
+// Vars returns a copy of the session's variables.
+func (s *Session) Vars() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.vars)
+}
@@ -0,0 +1,131 @@
+package shuntingyard
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestSession tests assignments shared across the expressions of a session
+func TestSession(t *testing.T) {
+	steps := []struct {
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{expression: "subtotal = 3 * 20", expected: 60},
+		{expression: "tax = subtotal * 0.25", expected: 15},
+		{expression: "subtotal + tax", expected: 75},
+		{expression: "subtotal = subtotal - 10", expected: 50},
+		{expression: "subtotal + tax", expected: 65},
+		{expression: "e = 2", expected: 2},
+		{expression: "e * 3", expected: 6},
+		{expression: "x=1+1", expected: 2},
+
+		// Error cases
+		{expression: "y = 1 / 0", wantErr: true},
+		{expression: "y", wantErr: true},
+		{expression: "max = 3", wantErr: true},
+		{expression: "2 = 3", wantErr: true},
+		{expression: "inf = 3", wantErr: true},
+		{expression: "z =", wantErr: true},
+		{expression: "z = w = 1", wantErr: true},
+		{expression: "(z) = 1", wantErr: true},
+	}
+
+	s := NewSession()
+	for _, step := range steps {
+		result, err := s.Eval(step.expression)
+
+		if step.wantErr {
+			if err == nil {
+				t.Errorf("Eval(%q) expected error, got %v", step.expression, result)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", step.expression, err)
+			continue
+		}
+
+		if !almostEqual(result, step.expected, 0.0000001) {
+			t.Errorf("Eval(%q) = %v, expected %v", step.expression, result, step.expected)
+		}
+	}
+
+	vars := s.Vars()
+	if len(vars) != 4 || vars["subtotal"] != 50 || vars["tax"] != 15 || vars["e"] != 2 || vars["x"] != 2 {
+		t.Errorf("Vars() = %v, expected subtotal, tax, e and x", vars)
+	}
+}
+
+// TestSessionErrorMessages tests the errors for invalid assignments
+func TestSessionErrorMessages(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: "max = 3", expected: "cannot assign to function 'max' at position 0"},
+		{expression: "2 = 3", expected: "cannot assign to a number at position 0"},
+		{expression: "( = 3", expected: "cannot assign to '(' at position 0"},
+		{expression: "z =", expected: "expected expression after '=' at position 2"},
+		{expression: "z = w = 1", expected: "unexpected '=' at position 6; assignment is only valid as 'name = expression' in a Session"},
+	}
+
+	s := NewSession()
+	for _, tt := range tests {
+		_, err := s.Eval(tt.expression)
+		if err == nil {
+			t.Errorf("Eval(%q) expected error, got nil", tt.expression)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("Eval(%q) error = %q, expected %q", tt.expression, err.Error(), tt.expected)
+		}
+	}
+}
+
+// TestSessionOptions tests that session options apply to every expression
+func TestSessionOptions(t *testing.T) {
+	s := NewSession(WithConstant("g", 9.81), WithDegrees())
+
+	if _, err := s.Eval("h = 0.5 * g * 2 ^ 2"); err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+	result, err := s.Eval("round(h * sin(90), 2)")
+	if err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+	if result != 19.62 {
+		t.Errorf("Eval() = %v, expected 19.62", result)
+	}
+
+	if _, err := s.Eval("missing + 1"); !errors.Is(err, ErrUndefinedVariable) {
+		t.Errorf("Eval() error = %v, expected ErrUndefinedVariable", err)
+	}
+}
+
+// TestSessionConcurrent tests concurrent assignments in one session
+func TestSessionConcurrent(t *testing.T) {
+	s := NewSession()
+	if _, err := s.Eval("n = 0"); err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Eval("n = n + 1"); err != nil {
+				t.Errorf("Eval() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := s.Vars()["n"]; n != 50 {
+		t.Errorf("n = %v, expected 50", n)
+	}
+}
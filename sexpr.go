@@ -0,0 +1,54 @@
+package shuntingyard
+
+import "strings"
+
+// ToSExpr converts postfix tokens, as returned by Parse, to an S-expression,
+// which spells out the grouping Parse chose, for debugging precedence and
+// diffing parse results:
+//
+//	ToSExpr([]string{"2", "3", "4", "*", "+"}) // "(+ 2 (* 3 4))"
+//
+// Each operator and call is a list of its symbol or function name followed by
+// its operands: unary minus is "(- x)", the conditional "(?: c a b)" and a
+// call "(max 1 2)". Numbers and variables are written as they are.
+//
+// Returns the S-expression, or a *TokenError for tokens that don't form an
+// expression.
+func ToSExpr(postfixTokens []string, opts ...Option) (string, error) {
+	root, err := buildAST(postfixTokens, newConfig(opts))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	writeSExpr(&b, root)
+	return b.String(), nil
+}
+
+// writeSExpr writes the S-expression of n to b.
+func writeSExpr(b *strings.Builder, n Node) {
+	var head string
+	var operands []Node
+	switch n := n.(type) {
+	case *NumberNode:
+		b.WriteString(n.Value)
+		return
+	case *VariableNode:
+		b.WriteString(n.Name)
+		return
+	case *UnaryNode:
+		head, operands = n.Operator, []Node{n.Operand}
+	case *BinaryNode:
+		head, operands = n.Operator, []Node{n.Left, n.Right}
+	case *ConditionalNode:
+		head, operands = ternary, []Node{n.Condition, n.Then, n.Else}
+	case *CallNode:
+		head, operands = n.Name, n.Args
+	}
+
+	b.WriteString("(" + head)
+	for _, operand := range operands {
+		b.WriteByte(' ')
+		writeSExpr(b, operand)
+	}
+	b.WriteByte(')')
+}
@@ -0,0 +1,42 @@
+package shuntingyard
+
+import "testing"
+
+func TestToSExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "single number", input: "42", expected: "42"},
+		{name: "precedence", input: "2 + 3 * 4", expected: "(+ 2 (* 3 4))"},
+		{name: "parentheses", input: "(2 + 3) * 4", expected: "(* (+ 2 3) 4)"},
+		{name: "left associative", input: "8 - 4 - 2", expected: "(- (- 8 4) 2)"},
+		{name: "right associative", input: "2 ^ 3 ^ 2", expected: "(^ 2 (^ 3 2))"},
+		{name: "unary minus binds looser than power", input: "-x ^ 2", expected: "(- (^ x 2))"},
+		{name: "prefix operators", input: "!(a && ~b)", expected: "(! (&& a (~ b)))"},
+		{name: "call", input: "max(1, x * 2, pi)", expected: "(max 1 (* x 2) pi)"},
+		{name: "conditional", input: "a > 0 ? a : -a", expected: "(?: (> a 0) a (- a))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postfix := mustParse(t, tt.input, nil)
+			result, err := ToSExpr(postfix)
+			if err != nil {
+				t.Fatalf("ToSExpr() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ToSExpr() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToSExprErrors(t *testing.T) {
+	for _, input := range [][]string{{}, {"1", "+"}, {"1", "2"}} {
+		if _, err := ToSExpr(input); err == nil {
+			t.Errorf("ToSExpr(%v) expected error, got nil", input)
+		}
+	}
+}
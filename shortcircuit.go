@@ -0,0 +1,100 @@
+package shuntingyard
+
+// branchKind is the part a postfix token plays in short-circuit evaluation,
+// as the root of an operand whose value decides what is evaluated next.
+type branchKind uint8
+
+const (
+	noBranch branchKind = iota
+	// branchCondition is the condition of a conditional: when it is false,
+	// evaluation continues at target, the start of the else branch
+	branchCondition
+	// branchThen ends the then branch of a conditional, which continues at
+	// target, the "?:" itself, past the else branch
+	branchThen
+	// branchAnd and branchOr are the left operands of "&&" and "||": when
+	// they decide the result, evaluation continues past target, the
+	// operator, without its right operand
+	branchAnd
+	branchOr
+)
+
+// branch is the short-circuit role of a postfix token.
+type branch struct {
+	kind   branchKind
+	target int
+}
+
+// branches returns the short-circuit role of every postfix token, so that
+// evaluation can skip the branch of a conditional not taken and the right
+// operand of a "&&" or "||" whose left operand decides it. It returns nil for
+// expressions without such operators, and for malformed ones, which the
+// evaluation then rejects as it runs through every token.
+func (c config) branches(postfix []string) []branch {
+	found := false
+	for _, token := range postfix {
+		if token == ternary || token == "&&" || token == "||" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	branches := make([]branch, len(postfix))
+	_, ok := c.operands(postfix, func(i int, operands []int, start []int) {
+		switch postfix[i] {
+		case ternary:
+			branches[operands[0]] = branch{kind: branchCondition, target: start[operands[2]]}
+			branches[operands[1]] = branch{kind: branchThen, target: i}
+		case "&&":
+			branches[operands[0]] = branch{kind: branchAnd, target: i}
+		case "||":
+			branches[operands[0]] = branch{kind: branchOr, target: i}
+		}
+	})
+	if !ok {
+		return nil
+	}
+	return branches
+}
+
+// operands works out the tree of a postfix expression, calling visit for
+// every token with the indices of the last tokens of its operands, and
+// returning the index of the first token of every operand: start[i]..i are
+// the tokens of the operand ending at i. It reports false for malformed
+// expressions.
+func (c config) operands(postfix []string, visit func(i int, operands []int, start []int)) (start []int, ok bool) {
+	// roots holds the index of the last token of each operand on the
+	// evaluation stack
+	roots := make([]int, 0, len(postfix)/2+1)
+	start = make([]int, len(postfix))
+	for i, token := range postfix {
+		argc := 0
+		switch {
+		case c.isBinaryOperator(token):
+			argc = 2
+		case isUnaryOperator(token):
+			argc = 1
+		case token == ternary:
+			argc = 3
+		case c.isCall(token):
+			_, argc, _ = splitCall(token)
+		}
+		if len(roots) < argc {
+			return nil, false
+		}
+		operands := roots[len(roots)-argc:]
+
+		start[i] = i
+		if argc > 0 {
+			start[i] = start[operands[0]]
+		}
+		if visit != nil {
+			visit(i, operands, start)
+		}
+		roots = append(roots[:len(roots)-argc], i)
+	}
+	return start, len(roots) == 1
+}
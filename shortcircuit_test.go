@@ -0,0 +1,121 @@
+package shuntingyard
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestShortCircuit(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		calls      int
+	}{
+		{name: "then branch skips else", expression: "1 ? 2 : tick(3)", expected: 2},
+		{name: "else branch skips then", expression: "0 ? tick(1) : 3", expected: 3},
+		{name: "false and skips right operand", expression: "0 && tick(1)", expected: 0},
+		{name: "true or skips right operand", expression: "x || tick(1)", expected: 1},
+		{name: "true and evaluates right operand", expression: "x && tick(x)", expected: 1, calls: 1},
+		{name: "false or evaluates right operand", expression: "0 || tick(x - 3)", expected: 0, calls: 1},
+		{name: "nested conditionals", expression: "x > 0 ? (x > 5 ? tick(1) : 2) : tick(3)", expected: 2},
+		{name: "chained conditionals", expression: "x < 0 ? tick(1) : x < 5 ? x * 2 : tick(2)", expected: 6},
+		{name: "short circuit inside arithmetic", expression: "(0 && tick(1)) + (x || tick(1)) * 10", expected: 10},
+		{name: "skipped branch would fail", expression: "x > 0 ? x : 1 / 0", expected: 3},
+		{name: "skipped operand would fail", expression: "x == 0 && sqrt(-1 / 0) > 0", expected: 0},
+		{name: "taken branch calls", expression: "x ? tick(x) + tick(x - 2) : 0", expected: 4, calls: 2},
+		{name: "chained or skips every right operand", expression: "x || tick(1) || tick(2)", expected: 1},
+		{name: "chained and skips every right operand", expression: "x == 0 && tick(1) && tick(2)", expected: 0},
+		{name: "decided and as condition", expression: "x == 0 && tick(1) ? tick(2) : 3", expected: 3},
+		{name: "decided or as condition", expression: "x || tick(1) ? 2 : tick(3)", expected: 2},
+		{name: "decided and as left operand of or", expression: "x == 0 && tick(1) || x", expected: 1},
+	}
+
+	vars := map[string]float64{"x": 3}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tick := WithFunction("tick", 1, func(args ...float64) (float64, error) {
+				calls++
+				return args[0], nil
+			})
+			expr, err := Compile(tt.expression, tick)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			fn, err := CompileFunc(tt.expression, tick)
+			if err != nil {
+				t.Fatalf("CompileFunc() unexpected error: %v", err)
+			}
+
+			evaluators := []struct {
+				name string
+				eval func() (float64, error)
+			}{
+				{name: "EvaluateWithVars", eval: func() (float64, error) { return EvaluateWithVars(expr.postfix, vars, tick) }},
+				{name: "Eval", eval: func() (float64, error) { return expr.Eval(vars) }},
+				{name: "CompileFunc", eval: func() (float64, error) { return fn(vars) }},
+			}
+			for _, e := range evaluators {
+				calls = 0
+				result, err := e.eval()
+				if err != nil {
+					t.Fatalf("%s() unexpected error: %v", e.name, err)
+				}
+				if result != tt.expected || calls != tt.calls {
+					t.Errorf("%s() = %v with %d calls, expected %v with %d", e.name, result, calls, tt.expected, tt.calls)
+				}
+			}
+		})
+	}
+}
+
+func TestShortCircuitArithmetics(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{name: "integer and", expression: "0 && 1 / 0", expected: "0"},
+		{name: "integer conditional", expression: "2 > 1 ? 7 : 1 % 0", expected: "7"},
+		{name: "chained or", expression: "1 || 1 / 0 || 1 / 0", expected: "1"},
+		{name: "and as condition", expression: "0 && 1 ? 2 : 3", expected: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			integer, err := EvaluateInt(postfix)
+			if err != nil || strconv.FormatInt(integer, 10) != tt.expected {
+				t.Errorf("EvaluateInt() = %v, %v, expected %s", integer, err, tt.expected)
+			}
+			rat, err := EvaluateRat(postfix)
+			if err != nil || rat.RatString() != tt.expected {
+				t.Errorf("EvaluateRat() = %v, %v, expected %s", rat, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShortCircuitSteps(t *testing.T) {
+	tokens, err := Scan("0 && (1 + 2 + 3 + 4)")
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	postfix, err := Parse(tokens)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	// The skipped operand takes no steps
+	if result, err := Evaluate(postfix, WithMaxSteps(1)); err != nil || result != 0 {
+		t.Errorf("Evaluate() = %v, %v, expected 0", result, err)
+	}
+}
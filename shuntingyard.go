@@ -3,199 +3,1207 @@
 package shuntingyard
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Scan tokenizes a mathematical expression string into individual tokens.
-// It supports floating-point numbers, operators (+, -, *, /), and parentheses.
-// Expressions can have spaces or be continuous (e.g., "1 + 2" or "1+2").
+// It supports floating-point numbers, arithmetic operators (+, -, *, /, %, ^),
+// comparison operators (<, >, <=, >=, ==, !=), logical operators (&&, ||, !),
+// bitwise operators (&, |, xor, ~; see WithBitwiseSymbols), shifts (<<, >>), the conditional
+// operator (? :), parentheses, names of functions such as "sqrt" and of
+// variables, the comma separating function arguments, and the '=' of an
+// assignment (see Session). Names are
+// ASCII letters, digits, and underscores, not starting with a digit. A
+// variable may be a dotted path of names, such as "order.total".
+// Expressions can have spaces or be continuous (e.g., "1 + 2" or "1+2"), and
+// may be wrapped across lines; errors in an expression of several lines give
+// their position as line:column, as in "invalid character '$' at 2:5".
+// Numbers may have an exponent, as in "6.02e23" or "2.5E-3", and integers may
+// be written in hexadecimal, binary or octal, as in "0xFF", "0b1010" or
+// "0o755"; those become the decimal token of their value, such as "255".
+// A number followed by '±' and another number, such as "9.81 ± 0.02", is one
+// literal carrying an uncertainty (see EvaluateUncertain).
+// Operators are matched longest first, so "a<=b" is "a", "<=", "b". "**" is
+// read as '^', and the typographic symbols '×', '·', '÷' and '−' (U+2212
+// MINUS SIGN) as '*', '*', '/' and '-'.
+// Signs are scanned as ordinary '+' and '-' tokens; Parse tells unary from
+// binary use by the token before them.
+// Comments, from "#" or "//" to the end of the line and between "/*" and
+// "*/", are skipped like spaces.
+// Tokens are substrings of expression, so scanning allocates only the slice.
+// Custom literal formats can be added with WithLiteralParser.
 //
-// Returns a slice of tokens or an error if invalid characters are encountered.
-func Scan(expression string) ([]string, error) {
+// Returns a slice of tokens, ErrEmptyExpression if the expression is empty or
+// whitespace only, an error wrapping ErrUnterminatedComment for a "/*" comment
+// without its "*/", or an *InvalidCharError if invalid characters are
+// encountered.
+func Scan(expression string, opts ...Option) ([]string, error) {
+	tokens, _, err := scan(expression, newConfig(opts), false)
+	return tokens, err
+}
+
+// scan implements Scan, additionally recording the source span of every token
+// when withSpans is set.
+func scan(expression string, cfg config, withSpans bool) ([]string, []Span, error) {
 	if expression == "" {
-		return nil, fmt.Errorf("empty expression")
+		return nil, nil, ErrEmptyExpression
 	}
 
-	var tokens []string
-	var currentNumber strings.Builder
+	// Spaced expressions hold about one token per two bytes; denser input
+	// grows the slice as needed
+	tokens := make([]string, 0, len(expression)/2+1)
+	var spans []Span
+	if withSpans {
+		spans = make([]Span, 0, cap(tokens))
+	}
+
+	// Tokens are substrings of the expression rather than freshly built
+	// strings, so scanning doesn't allocate per token
+	// lastStart and lastEnd locate the last token emitted from the
+	// expression, which an uncertainty may extend
+	lastStart, lastEnd := -1, -1
+	emit := func(start, end int) {
+		tokens = append(tokens, expression[start:end])
+		if withSpans {
+			spans = append(spans, Span{Start: start, End: end})
+		}
+		lastStart, lastEnd = start, end
+	}
+
+	// numberStart is the byte offset of the number being accumulated, or -1
+	// between numbers
+	numberStart := -1
+
+	// skip is the offset scanning resumes at after a custom literal
+	skip := 0
 
 	for i, ch := range expression {
+		if cfg.maxTokens > 0 && len(tokens) > cfg.maxTokens {
+			return nil, nil, &LimitError{Limit: "token count", Max: cfg.maxTokens}
+		}
+		if i < skip {
+			continue
+		}
+
+		// Bitwise symbols are configurable and may replace a built-in
+		// operator, so they are matched first
+		if token, n := cfg.matchBitwise(expression[i:]); n > 0 {
+			if numberStart >= 0 {
+				emit(numberStart, i)
+				numberStart = -1
+			}
+			tokens = append(tokens, token)
+			if withSpans {
+				spans = append(spans, Span{Start: i, End: i + n})
+			}
+			lastStart = -1
+			skip = i + n
+			continue
+		}
+
+		// Numbers in a configured format are read whole and translated to
+		// the default notation
+		if cfg.numbers.localized() && numberStart < 0 && prefixedIntegerLength(expression[i:]) == 0 {
+			if token, n := cfg.numbers.scan(expression[i:]); n > 0 {
+				tokens = append(tokens, token)
+				if withSpans {
+					spans = append(spans, Span{Start: i, End: i + n})
+				}
+				lastStart, lastEnd = i, i+n
+				skip = i + n
+				// A separator that didn't fit the format, as in "1.5" for
+				// "1.234,56", is a typo rather than a new token
+				if rest := expression[skip:]; strings.HasPrefix(rest, string(cfg.numbers.Decimal)) ||
+					cfg.numbers.Thousands != ' ' && cfg.numbers.thousandsAt(rest) > 0 {
+					r, _ := utf8.DecodeRuneInString(rest)
+					return nil, nil, cfg.invalidChar(expression, r, skip)
+				}
+				continue
+			}
+		}
+
+		// Registered operators come before the built-in ones and comments,
+		// which they may begin with, as "**" and "//" do
+		if _, n := cfg.matchOperator(expression[i:]); n > 0 {
+			if numberStart >= 0 {
+				emit(numberStart, i)
+				numberStart = -1
+			}
+			emit(i, i+n)
+			skip = i + n
+			continue
+		}
+
+		symbol, token := matchSymbol(expression[i:])
 		switch {
-		case unicode.IsDigit(ch) || ch == '.':
+		case ch == '0' && numberStart < 0 && prefixedIntegerLength(expression[i:]) > 0:
+			// A hexadecimal, binary or octal integer becomes the decimal
+			// token of its value, so every evaluator reads it
+			end := i + prefixedIntegerLength(expression[i:])
+			if end < len(expression) && isIdentByte(expression[end]) {
+				return nil, nil, cfg.invalidChar(expression, rune(expression[end]), end)
+			}
+			value, _ := new(big.Int).SetString(expression[i:end], 0)
+			tokens = append(tokens, value.String())
+			if withSpans {
+				spans = append(spans, Span{Start: i, End: end})
+			}
+			lastStart, lastEnd = i, end
+			skip = end
+
+		case (unicode.IsDigit(ch) || ch == '.') && !cfg.numbers.localized():
 			// Build multi-digit numbers and decimals
-			currentNumber.WriteRune(ch)
+			if numberStart < 0 {
+				numberStart = i
+			}
+
+		case ch == '#' || ch == '/' && i+1 < len(expression) && (expression[i+1] == '/' || expression[i+1] == '*'):
+			// Comments separate tokens like spaces; "#" and "//" run to the
+			// end of the line and "/*" to the next "*/"
+			if numberStart >= 0 {
+				emit(numberStart, i)
+				numberStart = -1
+			}
+			if ch == '/' && expression[i+1] == '*' {
+				end := strings.Index(expression[i+2:], "*/")
+				if end < 0 {
+					return nil, nil, &commentError{pos: i, where: at(expression, i)}
+				}
+				skip = i + 2 + end + len("*/")
+			} else if end := strings.IndexByte(expression[i:], '\n'); end >= 0 {
+				skip = i + end
+			} else {
+				skip = len(expression)
+			}
+
+		case ch == ';' && cfg.numbers.commaSeparated():
+			// Semicolons separate arguments where commas belong to numbers
+			tokens = append(tokens, ",")
+			if withSpans {
+				spans = append(spans, Span{Start: i, End: i + 1})
+			}
+			lastStart = -1
 
-		case ch == '+' || ch == '-' || ch == '*' || ch == '/' || ch == '(' || ch == ')':
+		case symbol != "" && (token != "," || !cfg.numbers.commaSeparated()):
 			// Flush any accumulated number before adding operator/parenthesis
-			if currentNumber.Len() > 0 {
-				tokens = append(tokens, currentNumber.String())
-				currentNumber.Reset()
+			if numberStart >= 0 {
+				emit(numberStart, i)
+				numberStart = -1
 			}
-			tokens = append(tokens, string(ch))
+			if token == symbol {
+				emit(i, i+len(symbol))
+			} else {
+				// Alternative spellings, such as "**" or a typographic "×",
+				// are translated to their ASCII tokens
+				tokens = append(tokens, token)
+				if withSpans {
+					spans = append(spans, Span{Start: i, End: i + len(symbol)})
+				}
+				lastStart = -1
+			}
+			skip = i + len(symbol)
+
+		case ch == '&' || ch == '|' || ch == ',':
+			// Single '&' and '|' are the bitwise symbols, which have been
+			// reassigned if they got here, and commas belong to numbers
+			return nil, nil, cfg.invalidChar(expression, ch, i)
+
+		case ch == '±':
+			// An uncertainty joins the number before it and the one after it,
+			// spaced or not, into one literal such as "9.81±0.02"
+			start, number := numberStart, ""
+			if start >= 0 {
+				number = expression[start:i]
+			} else if lastStart >= 0 && isNumberLiteral(tokens[len(tokens)-1]) && strings.TrimSpace(expression[lastEnd:i]) == "" {
+				start, number = lastStart, tokens[len(tokens)-1]+expression[lastEnd:i]
+				tokens = tokens[:len(tokens)-1]
+				if withSpans {
+					spans = spans[:len(spans)-1]
+				}
+			}
+			end := i + len("±")
+			for end < len(expression) && unicode.IsSpace(rune(expression[end])) {
+				end++
+			}
+			digits := end
+			var deviation string
+			if cfg.numbers.localized() {
+				token, n := cfg.numbers.scan(expression[end:])
+				deviation, end = token, end+n
+			} else {
+				for end < len(expression) && (unicode.IsDigit(rune(expression[end])) || expression[end] == '.') {
+					end++
+				}
+				if end > digits {
+					end += exponentLength(expression[end:])
+				}
+				deviation = expression[digits:end]
+			}
+			if start < 0 || end == digits {
+				return nil, nil, cfg.invalidChar(expression, ch, i)
+			}
+			// The literal is the source text unless either number was
+			// translated, as a hexadecimal or localized one is
+			token := expression[start:end]
+			if number != expression[start:i] || deviation != expression[digits:end] {
+				token = number + expression[i:digits] + deviation
+			}
+			tokens = append(tokens, token)
+			if withSpans {
+				spans = append(spans, Span{Start: start, End: end})
+			}
+			lastStart, lastEnd = start, end
+			numberStart = -1
+			skip = end
 
 		case unicode.IsSpace(ch):
 			// Spaces separate tokens, flush any accumulated number
-			if currentNumber.Len() > 0 {
-				tokens = append(tokens, currentNumber.String())
-				currentNumber.Reset()
+			if numberStart >= 0 {
+				emit(numberStart, i)
+				numberStart = -1
 			}
 
+		case numberStart >= 0 && (ch == 'e' || ch == 'E') && exponentLength(expression[i:]) > 0:
+			// An exponent, such as the "e-3" of "2.5e-3", ends the number
+			end := i + exponentLength(expression[i:])
+			emit(numberStart, end)
+			numberStart = -1
+			skip = end
+
+		case numberStart < 0 && isIdentStart(ch):
+			// A name runs to the first character that can't continue it; a
+			// dot followed by another name continues it as a path
+			end := i + 1
+			for end < len(expression) {
+				if isIdentByte(expression[end]) {
+					end++
+				} else if expression[end] == '.' && end+1 < len(expression) && isIdentStart(rune(expression[end+1])) {
+					end += 2
+				} else {
+					break
+				}
+			}
+			// A keyword operator whose symbol has been reassigned is reserved
+			// rather than a name, so it can't pass for the operator
+			if isBinaryOperator(expression[i:end]) {
+				return nil, nil, cfg.invalidChar(expression, ch, i)
+			}
+			emit(i, end)
+			skip = end
+
 		default:
-			return nil, fmt.Errorf("invalid character '%c' at position %d", ch, i)
+			// Give custom literal parsers a chance, starting from the number
+			// being accumulated if the character interrupted one
+			start := i
+			if numberStart >= 0 {
+				start = numberStart
+			}
+			value, n, ok := cfg.parseLiteral(expression[start:], i-start+1)
+			if !ok {
+				return nil, nil, cfg.invalidChar(expression, ch, i)
+			}
+
+			// The literal becomes a canonical number token
+			tokens = append(tokens, strconv.FormatFloat(value, 'g', -1, 64))
+			if withSpans {
+				spans = append(spans, Span{Start: start, End: start + n})
+			}
+			lastStart = -1
+			numberStart = -1
+			skip = start + n
 		}
 	}
 
 	// Don't forget the last number
-	if currentNumber.Len() > 0 {
-		tokens = append(tokens, currentNumber.String())
+	if numberStart >= 0 {
+		emit(numberStart, len(expression))
 	}
 
 	if len(tokens) == 0 {
-		return nil, fmt.Errorf("no valid tokens found")
+		return nil, nil, ErrEmptyExpression
+	}
+	if cfg.maxTokens > 0 && len(tokens) > cfg.maxTokens {
+		return nil, nil, &LimitError{Limit: "token count", Max: cfg.maxTokens}
+	}
+
+	return tokens, spans, nil
+}
+
+// symbolTokens maps the operator and punctuation symbols Scan matches to the
+// tokens it emits for them: the binary operators, '!' and '=' alone, the
+// parentheses, comma and conditional, and alternative spellings of
+// operators, such as "**" and a typographic "×". The single bitwise symbols
+// are matched by matchBitwise, since they can be reassigned.
+var symbolTokens = map[string]string{
+	"(": "(",
+	")": ")",
+	",": ",",
+	"?": "?",
+	":": ":",
+	"!": "!",
+	"=": "=",
+
+	"**": "^",
+	"×":  "*",
+	"·":  "*",
+	"÷":  "/",
+	"−":  "-",
+}
+
+// scanSymbols indexes the symbols of symbolTokens, with the binary operators
+// spelled in punctuation, by their first byte, each list longest first so that
+// Scan reads "<=" rather than "<" followed by "=".
+var scanSymbols = indexSymbols()
+
+// symbolToken pairs a symbol Scan matches with the token it emits.
+type symbolToken struct {
+	symbol, token string
+}
+
+func indexSymbols() *[256][]symbolToken {
+	var index [256][]symbolToken
+	add := func(symbol, token string) {
+		index[symbol[0]] = append(index[symbol[0]], symbolToken{symbol, token})
+	}
+	for symbol, token := range symbolTokens {
+		add(symbol, token)
+	}
+	for symbol := range binaryOperators {
+		if !isIdentStart(rune(symbol[0])) && symbol != "&" && symbol != "|" {
+			add(symbol, symbol)
+		}
+	}
+	for _, symbols := range index {
+		slices.SortFunc(symbols, func(a, b symbolToken) int {
+			return len(b.symbol) - len(a.symbol)
+		})
+	}
+	return &index
+}
+
+// matchSymbol returns the longest symbol Scan matches that s starts with and
+// the token it emits for it, or "" if none.
+func matchSymbol(s string) (symbol, token string) {
+	for _, sym := range scanSymbols[s[0]] {
+		if strings.HasPrefix(s, sym.symbol) {
+			return sym.symbol, sym.token
+		}
+	}
+	return "", ""
+}
+
+// binaryOperators holds the binding of each binary operator. Only
+// exponentiation groups right to left, so that "2 ^ 3 ^ 2" means
+// "2 ^ (3 ^ 2)".
+var binaryOperators = map[string]binding{
+	"||":  {1, LeftAssociative},
+	"&&":  {2, LeftAssociative},
+	"|":   {3, LeftAssociative},
+	"xor": {4, LeftAssociative},
+	"&":   {5, LeftAssociative},
+	"<":   {6, LeftAssociative},
+	">":   {6, LeftAssociative},
+	"<=":  {6, LeftAssociative},
+	">=":  {6, LeftAssociative},
+	"==":  {6, LeftAssociative},
+	"!=":  {6, LeftAssociative},
+	"<<":  {7, LeftAssociative},
+	">>":  {7, LeftAssociative},
+	"+":   {8, LeftAssociative},
+	"-":   {8, LeftAssociative},
+	"*":   {9, LeftAssociative},
+	"/":   {9, LeftAssociative},
+	"%":   {9, LeftAssociative},
+	"^":   {11, RightAssociative},
+}
+
+// negate is the postfix token Parse emits for unary minus, keeping it distinct
+// from binary subtraction.
+const negate = "neg"
+
+// unaryOperators maps each prefix operator to the postfix token Parse emits
+// for it. Unary plus is absent because Parse drops it.
+var unaryOperators = map[string]string{
+	"-": negate,
+	"!": "!",
+	"~": "~",
+}
+
+// ternary is the postfix token Parse emits for a conditional "c ? a : b",
+// which takes its three operands in the order c, a, b.
+const ternary = "?:"
+
+// ternaryBinding ranks the conditional below every binary operator, so
+// "x > 0 ? x : 0 - x" needs no parentheses. It groups right to left.
+var ternaryBinding = binding{0, RightAssociative}
+
+// unaryBinding ranks the prefix operators above multiplication but below
+// exponentiation, so "-2 ^ 2" means "-(2 ^ 2)" while "2 * -3" negates only the 3.
+var unaryBinding = binding{10, RightAssociative}
+
+// isBinaryOperator reports whether token is one of the binary operators.
+func isBinaryOperator(token string) bool {
+	_, ok := binaryOperators[token]
+	return ok
+}
+
+// isGrammarToken reports whether an infix token belongs to the grammar rather
+// than being an operand: an operator, a parenthesis, or part of a conditional.
+func isGrammarToken(token string) bool {
+	return isBinaryOperator(token) || token == "!" || token == "~" || token == "(" || token == ")" || token == "?" || token == ":" || token == "," || token == "="
+}
+
+// isIdentStart reports whether ch can start a name.
+func isIdentStart(ch rune) bool {
+	return ch == '_' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+// isIdentByte reports whether b can continue a name.
+func isIdentByte(b byte) bool {
+	return isIdentStart(rune(b)) || ('0' <= b && b <= '9')
+}
+
+// prefixedIntegerLength returns the length of the hexadecimal ("0xFF"), binary
+// ("0b1010") or octal ("0o755") integer s starts with, or 0 if it doesn't
+// start with one.
+func prefixedIntegerLength(s string) int {
+	if len(s) < 3 || s[0] != '0' {
+		return 0
+	}
+	var isDigit func(b byte) bool
+	switch s[1] {
+	case 'x', 'X':
+		isDigit = func(b byte) bool { return '0' <= b && b <= '9' || 'a' <= b|0x20 && b|0x20 <= 'f' }
+	case 'b', 'B':
+		isDigit = func(b byte) bool { return b == '0' || b == '1' }
+	case 'o', 'O':
+		isDigit = func(b byte) bool { return '0' <= b && b <= '7' }
+	default:
+		return 0
+	}
+	n := 2
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	if n == 2 {
+		return 0
+	}
+	return n
+}
+
+// exponentLength returns the length of the exponent s starts with, such as
+// "e5" or "E-3", or 0 if it doesn't start with one.
+func exponentLength(s string) int {
+	if len(s) < 2 || s[0] != 'e' && s[0] != 'E' {
+		return 0
+	}
+	n := 1
+	if s[n] == '+' || s[n] == '-' {
+		n++
+	}
+	digits := n
+	for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+		n++
 	}
+	if n == digits {
+		return 0
+	}
+	return n
+}
 
-	return tokens, nil
+// isIdentifier reports whether token is a name, such as a function name.
+func isIdentifier(token string) bool {
+	if token == "" || !isIdentStart(rune(token[0])) {
+		return false
+	}
+	for i := 1; i < len(token); i++ {
+		if !isIdentByte(token[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isVariableName reports whether token can name a variable: a name or a
+// dotted path of names such as "order.total".
+func isVariableName(token string) bool {
+	// Evaluation asks this of every operand, so it takes one pass over the
+	// bytes rather than splitting the path
+	segmentStart := true
+	for i := 0; i < len(token); i++ {
+		switch b := token[i]; {
+		case segmentStart:
+			if !isIdentStart(rune(b)) {
+				return false
+			}
+			segmentStart = false
+		case b == '.':
+			segmentStart = true
+		case !isIdentByte(b):
+			return false
+		}
+	}
+	return !segmentStart
+}
+
+// isUnaryOperator reports whether a postfix token is one of the unary
+// operators, "neg", "!" or "~".
+func isUnaryOperator(token string) bool {
+	return token == negate || token == "!" || token == "~"
 }
 
 // Parse converts infix notation tokens to postfix notation (Reverse Polish Notation)
 // using the Shunting Yard algorithm. It handles operator precedence and associativity:
-// - Multiplication and division have higher precedence than addition and subtraction
-// - Operators of the same precedence are left-associative
+// - Exponentiation has the highest precedence and is right-associative
+// - Multiplication, division, and modulo have higher precedence than addition and subtraction
+// - Shifts bind looser than addition, as in C, so "1 << 2 + 1" shifts by 3
+// - Comparisons bind looser than shifts, so "1 + 1 == 2" compares the sum
+// - Bitwise AND, XOR, and OR bind looser than comparisons, in that order, as in C
+// - Logical AND binds looser than the bitwise operators, and logical OR loosest of all
+// - Other operators of the same precedence are left-associative
+//
+// A function call is emitted after its arguments as a single token carrying
+// the function name and argument count, so "max(1, 2)" becomes
+// ["1", "2", "max/2"]. Arguments are separated by commas. Functions registered
+// with WithFunction are available alongside the built-in ones. Any other name
+// is a variable, an operand that EvaluateWithVars supplies the value of.
+//
+// The conditional "c ? a : b" has the lowest precedence and groups right to
+// left, so "a ? b : c ? d : e" means "a ? b : (c ? d : e)". It is emitted as
+// the single postfix token "?:" after its three operands.
 //
-// Returns postfix tokens or an error for mismatched parentheses.
-func Parse(tokens []string) ([]string, error) {
+// A '+' or '-' where an operand is expected (at the start, after an operator,
+// or after '(') is a sign rather than a binary operator. Unary minus, logical
+// NOT ('!'), and bitwise NOT ('~') bind tighter than multiplication but looser
+// than exponentiation. Unary minus is emitted as the postfix token "neg"; unary
+// plus is a no-op and is dropped, so "-(2 + 3)" becomes ["2", "3", "+", "neg"].
+//
+// Parse also validates the structure of the infix input, so expressions with a
+// missing operand (e.g., "2 +" or "* 3"), a missing operator (e.g., "2 3"), or
+// a call with the wrong number of arguments (e.g., "pow(2)") are rejected here
+// rather than surfacing later as an Evaluate error.
+// Errors state what the grammar expected at the failure point and what was
+// found instead, locating both tokens of the malformed pair (e.g., "expected
+// number or '(' after '+' at token 2, found ')' at token 3"); empty
+// parentheses, as in "()", are reported as such.
+//
+// Returns postfix tokens or an error for mismatched parentheses, an unmatched '?'
+// or ':', a comma outside a function call, or malformed expressions.
+func Parse(tokens []string, opts ...Option) ([]string, error) {
+	output, _, err := parse(tokens, nil, newConfig(opts))
+	return output, err
+}
+
+// parse implements Parse. When spans is non-nil it holds the source span of
+// each input token, and the span of each output token is returned alongside it.
+func parse(tokens []string, spans []Span, cfg config) ([]string, []Span, error) {
 	if len(tokens) == 0 {
-		return nil, fmt.Errorf("empty token list")
+		return nil, nil, fmt.Errorf("empty token list")
+	}
+	if err := cfg.checkLimits(tokens, spans); err != nil {
+		return nil, nil, err
+	}
+
+	// Output holds every token except parentheses and the stack at most
+	// every operator; size both up front so appending never reallocates
+	output := make([]string, 0, len(tokens))
+	var outputSpans []Span
+	if spans != nil {
+		outputSpans = make([]Span, 0, len(tokens))
 	}
 
-	var output []string
-	var operatorStack []string
+	// The operator stack holds indices into tokens so that popped operators
+	// can be emitted together with their spans
+	operatorStack := make([]stackedOperator, 0, len(tokens)/2+1)
 
-	precedence := map[string]int{
-		"+": 1,
-		"-": 1,
-		"*": 2,
-		"/": 2,
+	emit := func(index int) {
+		output = append(output, tokens[index])
+		if spans != nil {
+			outputSpans = append(outputSpans, spans[index])
+		}
+	}
+	emitOperator := func(op stackedOperator) {
+		switch {
+		case op.unary:
+			output = append(output, unaryOperators[tokens[op.index]])
+		case tokens[op.index] == ":":
+			output = append(output, ternary)
+		case cfg.isFunction(tokens[op.index]):
+			output = append(output, callToken(tokens[op.index], op.args))
+		default:
+			output = append(output, tokens[op.index])
+		}
+		if spans != nil {
+			outputSpans = append(outputSpans, spans[op.index])
+		}
 	}
 
-	for _, token := range tokens {
-		switch token {
-		case "+", "-", "*", "/":
-			// Pop operators with greater or equal precedence (left-associative)
+	// popOperators pops the operators an incoming one with binding in
+	// follows: those that bind tighter, and those that bind as tightly when
+	// in groups left to right. It stops at the innermost '('
+	popOperators := func(in binding) {
+		for len(operatorStack) > 0 {
+			top := operatorStack[len(operatorStack)-1]
+			if tokens[top.index] == "(" || !top.binding(tokens, cfg).precedes(in) {
+				break
+			}
+			emitOperator(top)
+			operatorStack = operatorStack[:len(operatorStack)-1]
+		}
+	}
+
+	// errs holds the errors recovered from with WithErrorRecovery. fail
+	// records err and returns nil so that parsing carries on as though the
+	// input were fixed, or without recovery returns err to stop at
+	var errs []error
+	fail := func(err error) error {
+		if !cfg.recoverErrors {
+			return err
+		}
+		errs = append(errs, err)
+		return nil
+	}
+
+	// Track whether the next token must start an operand (a number or '(')
+	// or must follow one (an operator or ')'), and how many parentheses are
+	// open, which together determine what the grammar expects next
+	expectOperand := true
+	depth := 0
+
+	for i, token := range tokens {
+		switch {
+		case expectOperand && token == "+":
+			// Unary plus changes nothing
+
+		case expectOperand && unaryOperators[token] != "":
+			// A prefix operator has no left operand, so there is nothing to pop
+			operatorStack = append(operatorStack, stackedOperator{index: i, unary: true})
+
+		case token == "!":
+			// Logical NOT where an operator is expected
+			if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+				return nil, nil, err
+			}
+
+		case token == "=":
+			// Assignment is a statement of a Session, not part of an expression
+			if err := fail(cfg.parseError(spans, i, fmt.Errorf("unexpected '=' at %s; assignment is only valid as 'name = expression' in a Session", cfg.location(spans, i)))); err != nil {
+				return nil, nil, err
+			}
+			expectOperand = true
+
+		case cfg.isBinaryOperator(token):
+			if expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
+			}
+			expectOperand = true
+
+			popOperators(cfg.binding(token))
+			operatorStack = append(operatorStack, stackedOperator{index: i})
+
+		case token == "?":
+			if expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
+			}
+			expectOperand = true
+
+			// The condition is complete; every operator in it binds tighter.
+			// Earlier conditionals stay put because '?' groups right to left
+			popOperators(ternaryBinding)
+			operatorStack = append(operatorStack, stackedOperator{index: i})
+
+		case token == ":":
+			if expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
+			}
+			expectOperand = true
+
+			// Pop the first branch, including any conditionals nested in it,
+			// until the nearest '?'; the ':' then takes its place on the stack
+			found := false
 			for len(operatorStack) > 0 {
 				top := operatorStack[len(operatorStack)-1]
-				if top == "(" {
+				if tokens[top.index] == "(" {
 					break
 				}
-				if precedence[top] < precedence[token] {
+				operatorStack = operatorStack[:len(operatorStack)-1]
+				if tokens[top.index] == "?" {
+					found = true
 					break
 				}
-				// Pop operator to output
-				output = append(output, top)
+				emitOperator(top)
+			}
+			if !found {
+				if err := fail(cfg.errorAt(spans, i, "mismatched conditional: unmatched ':'")); err != nil {
+					return nil, nil, err
+				}
+			}
+			operatorStack = append(operatorStack, stackedOperator{index: i})
+
+		case cfg.isFunction(token):
+			if !expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
+			}
+			if i+1 == len(tokens) || tokens[i+1] != "(" {
+				// Recovery reads the argument as a plain operand
+				if err := fail(cfg.errorAt(spans, i, "expected '(' after function '%s'", token)); err != nil {
+					return nil, nil, err
+				}
+				expectOperand = true
+				continue
+			}
+			// The function waits on the stack beneath its '(' until the
+			// matching ')' completes its arguments
+			operatorStack = append(operatorStack, stackedOperator{index: i})
+			expectOperand = true
+
+		case token == ",":
+			if expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
+			}
+			expectOperand = true
+
+			// The argument is complete; pop it back to the call's '('
+			for len(operatorStack) > 0 && tokens[operatorStack[len(operatorStack)-1].index] != "(" {
+				top := operatorStack[len(operatorStack)-1]
+				if tokens[top.index] == "?" {
+					if err := fail(cfg.errorAt(spans, top.index, "mismatched conditional: unmatched '?'")); err != nil {
+						return nil, nil, err
+					}
+				} else {
+					emitOperator(top)
+				}
 				operatorStack = operatorStack[:len(operatorStack)-1]
 			}
-			operatorStack = append(operatorStack, token)
+			if len(operatorStack) == 0 || !cfg.opensCall(tokens, operatorStack[len(operatorStack)-1].index) {
+				if err := fail(cfg.errorAt(spans, i, "unexpected ',' outside function call")); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			operatorStack[len(operatorStack)-1].args++
 
-		case "(":
-			operatorStack = append(operatorStack, token)
+		case token == "(":
+			if !expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
+			}
+			expectOperand = true
+			depth++
+			if cfg.maxDepth > 0 && depth > cfg.maxDepth {
+				// The limit guards against hostile input, so parsing stops
+				// here even when recovering
+				var err error = cfg.errorAt(spans, i, "%w", &LimitError{Limit: "nesting depth", Max: cfg.maxDepth})
+				if cfg.recoverErrors {
+					err = errors.Join(append(errs, err)...)
+				}
+				return nil, nil, err
+			}
+			// A call's '(' counts the arguments seen so far, its first
+			// included unless the call turns out to be empty
+			operatorStack = append(operatorStack, stackedOperator{index: i, args: 1})
+
+		case token == ")":
+			// Only a function call may have nothing between its parentheses
+			empty := i > 0 && tokens[i-1] == "(" && cfg.opensCall(tokens, i-1)
+			if expectOperand && !empty {
+				err := cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)
+				if i > 0 && tokens[i-1] == "(" {
+					err = cfg.errorAt(spans, i-1, "empty parentheses")
+				}
+				if err := fail(err); err != nil {
+					return nil, nil, err
+				}
+			}
+			expectOperand = false
 
-		case ")":
 			// Pop until we find the matching left parenthesis
 			found := false
+			args := 0
 			for len(operatorStack) > 0 {
 				top := operatorStack[len(operatorStack)-1]
 				operatorStack = operatorStack[:len(operatorStack)-1]
 
-				if top == "(" {
+				if tokens[top.index] == "(" {
 					found = true
+					args = top.args
 					break
 				}
-				output = append(output, top)
+				if tokens[top.index] == "?" {
+					if err := fail(cfg.errorAt(spans, top.index, "mismatched conditional: unmatched '?'")); err != nil {
+						return nil, nil, err
+					}
+					continue
+				}
+				emitOperator(top)
 			}
 			if !found {
-				return nil, fmt.Errorf("mismatched parentheses: unmatched ')'")
+				if err := fail(cfg.errorAt(spans, i, "mismatched parentheses: unmatched ')'")); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			depth--
+
+			// Parentheses that close a function call complete it
+			if len(operatorStack) > 0 && cfg.isFunction(tokens[operatorStack[len(operatorStack)-1].index]) {
+				call := operatorStack[len(operatorStack)-1]
+				call.args = args
+				if empty {
+					call.args = 0
+				}
+				// The argument count is known now, so a call that Evaluate
+				// would reject fails here with its position
+				if _, _, err := cfg.checkCall(callToken(tokens[call.index], call.args), call.args); err != nil {
+					if err := fail(cfg.errorAt(spans, call.index, "%w", err)); err != nil {
+						return nil, nil, err
+					}
+				}
+				emitOperator(call)
+				operatorStack = operatorStack[:len(operatorStack)-1]
 			}
 
 		default:
-			// Must be a number, validate it
-			if _, err := strconv.ParseFloat(token, 64); err != nil {
-				return nil, fmt.Errorf("invalid number: %s", token)
+			// Must be a number or a variable, validate it
+			if _, _, err := parseUncertain(token); err != nil {
+				if isVariableName(token) && i+1 < len(tokens) && tokens[i+1] == "(" {
+					// Recovery skips the name, leaving its parenthesized
+					// arguments as the operand
+					if err := fail(cfg.errorAt(spans, i, "unknown function '%s'", token)); err != nil {
+						return nil, nil, err
+					}
+					continue
+				}
+				if !isVariableName(token) {
+					if err := fail(cfg.parseError(spans, i, cfg.invalidNumber(token, i))); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+			if !expectOperand {
+				if err := fail(cfg.unexpectedToken(tokens, spans, i, expectOperand, depth)); err != nil {
+					return nil, nil, err
+				}
 			}
-			output = append(output, token)
+			expectOperand = false
+			emit(i)
+		}
+	}
+
+	if expectOperand {
+		if err := fail(cfg.unexpectedToken(tokens, spans, len(tokens), expectOperand, depth)); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	// Pop remaining operators
 	for len(operatorStack) > 0 {
 		top := operatorStack[len(operatorStack)-1]
-		if top == "(" {
-			return nil, fmt.Errorf("mismatched parentheses: unmatched '('")
-		}
-		output = append(output, top)
 		operatorStack = operatorStack[:len(operatorStack)-1]
+		if tokens[top.index] == "(" {
+			if err := fail(cfg.errorAt(spans, top.index, "mismatched parentheses: unmatched '('")); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if tokens[top.index] == "?" {
+			if err := fail(cfg.errorAt(spans, top.index, "mismatched conditional: unmatched '?'")); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		emitOperator(top)
 	}
 
-	return output, nil
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return output, outputSpans, nil
+}
+
+// stackedOperator is an entry on the Parse operator stack: the index of an
+// operator, function, '(', '?' or ':' in the infix tokens, whether it is a
+// prefix operator, and for a '(' or function the number of arguments.
+type stackedOperator struct {
+	index int
+	unary bool
+	args  int
+}
+
+// opensCall reports whether the '(' at tokens[i] starts a function's arguments.
+func (c config) opensCall(tokens []string, i int) bool {
+	return i > 0 && c.isFunction(tokens[i-1])
+}
+
+// binding returns the binding of the stacked operator.
+func (op stackedOperator) binding(tokens []string, cfg config) binding {
+	switch {
+	case op.unary:
+		return unaryBinding
+	case tokens[op.index] == "?" || tokens[op.index] == ":":
+		return ternaryBinding
+	}
+	return cfg.binding(tokens[op.index])
+}
+
+// unexpectedToken reports that the token at index i (len(tokens) for the end of
+// input) doesn't fit the grammar, stating what was expected in the parser's
+// current state, e.g. "expected number or '(' after '+' at token 2, found ')'
+// at token 3".
+func (c config) unexpectedToken(tokens []string, spans []Span, i int, expectOperand bool, depth int) error {
+	expected := "number or '('"
+	if !expectOperand {
+		expected = "operator or end of expression"
+		if depth > 0 {
+			expected = "operator or ')'"
+		}
+	}
+
+	// Both tokens of a malformed pair, such as the numbers of "2 3" or the
+	// parentheses of "()", are located
+	after := "at start of expression"
+	if i > 0 {
+		after = "after " + c.describeToken(tokens[i-1]) + " at " + c.location(spans, i-1)
+	}
+
+	if i == len(tokens) {
+		return c.parseError(spans, i, fmt.Errorf("expected %s %s, found end of expression", expected, after))
+	}
+	return c.errorAt(spans, i, "expected %s %s, found %s", expected, after, c.describeToken(tokens[i]))
+}
+
+// errorAt returns a *ParseError for the token at index i, its message given
+// by format and args followed by the token's location.
+func (c config) errorAt(spans []Span, i int, format string, args ...any) *ParseError {
+	return c.parseError(spans, i, fmt.Errorf(format+" at %s", append(args, c.location(spans, i))...))
+}
+
+// parseError returns a *ParseError for the token at index i, len(spans) for
+// the end of input, locating it in the source when spans are known.
+func (c config) parseError(spans []Span, i int, err error) *ParseError {
+	pos := -1
+	switch {
+	case i < len(spans):
+		pos = spans[i].Start
+	case len(spans) > 0:
+		pos = spans[len(spans)-1].End
+	}
+	return &ParseError{Index: i, Pos: pos, Err: err}
+}
+
+// describeToken names a token in an error message. Numbers are shown by kind
+// only when errors are redacted.
+func (c config) describeToken(token string) string {
+	if c.isGrammarToken(token) {
+		return "'" + token + "'"
+	}
+	if isIdentifier(token) && c.isFunction(token) {
+		return "function " + token
+	}
+	if isVariableName(token) {
+		return "variable " + token
+	}
+	if c.redactErrors {
+		return "number"
+	}
+	return "number " + token
 }
 
 // Evaluate computes the result of a postfix (RPN) expression.
 // It uses a stack-based algorithm to process operators and operands.
+// Besides the binary operators it accepts the unary operators emitted by Parse,
+// "neg" for unary minus, "!" for logical NOT and "~" for bitwise NOT, the conditional "?:",
+// which yields its second operand when the first is non-zero and its third otherwise, and
+// function calls such as "max/2", which apply to the given number of operands before them.
+// Names of variables have no value here and fail the evaluation; see EvaluateWithVars.
 //
-// Returns the computed float64 result or an error for invalid expressions or division by zero.
-func Evaluate(postfixTokens []string) (float64, error) {
-	if len(postfixTokens) == 0 {
-		return 0, fmt.Errorf("empty expression")
+// Modulo follows math.Mod: the result has the sign of the dividend.
+// Comparisons yield 1 when they hold and 0 otherwise, so they combine with
+// arithmetic, as in "(a > b) * 10". Logical operators treat 0 as false and
+// any other value as true, and likewise yield 1 or 0. Bitwise operators work on
+// the 64-bit two's complement form of their operands, which must be integers.
+// Shifts likewise need integers and a non-negative count; ">>" keeps the sign.
+// Evaluation short-circuits: the branch of a conditional not taken, and the
+// right operand of a "&&" or "||" decided by its left one, are skipped, so
+// their errors and any calls in them never happen.
+//
+// Returns the computed float64 result or an error for invalid expressions, division by zero,
+// modulo by zero, or non-integer bitwise operands. Failures caused by a specific token are reported as a *TokenError holding its index.
+func Evaluate(postfixTokens []string, opts ...Option) (float64, error) {
+	return evaluate(postfixTokens, newConfig(opts))
+}
+
+// evaluate implements Evaluate and EvaluateWithVars.
+func evaluate(postfixTokens []string, cfg config) (float64, error) {
+	return evaluateWith(postfixTokens, Arithmetic[float64](floatArithmetic{}), cfg)
+}
+
+// applyBinary applies a binary arithmetic operator to its two operands.
+func applyBinary(operator string, a, b float64) (float64, error) {
+	switch operator {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return 0, fmt.Errorf("modulo by zero")
+		}
+		return math.Mod(a, b), nil
+	case "^":
+		return math.Pow(a, b), nil
+	case "<":
+		return fromBool(a < b), nil
+	case ">":
+		return fromBool(a > b), nil
+	case "<=":
+		return fromBool(a <= b), nil
+	case ">=":
+		return fromBool(a >= b), nil
+	case "==":
+		return fromBool(a == b), nil
+	case "!=":
+		return fromBool(a != b), nil
+	case "&&":
+		return fromBool(a != 0 && b != 0), nil
+	case "||":
+		return fromBool(a != 0 || b != 0), nil
+	case "&", "|", "xor", "<<", ">>":
+		x, err := toInteger(operator, a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := toInteger(operator, b)
+		if err != nil {
+			return 0, err
+		}
+		switch operator {
+		case "&":
+			return float64(x & y), nil
+		case "|":
+			return float64(x | y), nil
+		case "xor":
+			return float64(x ^ y), nil
+		}
+		if y < 0 {
+			return 0, fmt.Errorf("negative shift count")
+		}
+		if y >= 64 {
+			return 0, fmt.Errorf("shift count exceeds 63")
+		}
+		if operator == ">>" {
+			return float64(x >> y), nil
+		}
+		// Bits shifted out, into the sign or beyond it, don't fit in 64 bits
+		if x<<y>>y != x {
+			return 0, errOverflow
+		}
+		return float64(x << y), nil
 	}
+	return 0, fmt.Errorf("unknown operator '%s'", operator)
+}
 
-	var stack []float64
+// applyTernary evaluates the conditional "c ? a : b".
+func applyTernary(c, a, b float64) float64 {
+	if c != 0 {
+		return a
+	}
+	return b
+}
 
-	for _, token := range postfixTokens {
-		switch token {
-		case "+", "-", "*", "/":
-			// Need at least 2 operands
-			if len(stack) < 2 {
-				return 0, fmt.Errorf("invalid expression: insufficient operands for operator '%s'", token)
-			}
+// applyUnary applies a unary operator, in its postfix form, to its operand.
+func applyUnary(operator string, a float64) (float64, error) {
+	switch operator {
+	case "!":
+		return fromBool(a == 0), nil
+	case "~":
+		x, err := toInteger(operator, a)
+		if err != nil {
+			return 0, err
+		}
+		return float64(^x), nil
+	}
+	return -a, nil
+}
 
-			// Pop two operands (note: order matters for - and /)
-			b := stack[len(stack)-1]
-			a := stack[len(stack)-2]
-			stack = stack[:len(stack)-2]
+// toInteger converts the operand of a bitwise operator to an integer, failing
+// if it has a fractional part or doesn't fit in 64 bits.
+func toInteger(operator string, a float64) (int64, error) {
+	if a != math.Trunc(a) || a < math.MinInt64 || a >= math.MaxInt64 {
+		return 0, fmt.Errorf("bitwise operator '%s' requires integer operands", operator)
+	}
+	return int64(a), nil
+}
 
-			var result float64
-			switch token {
-			case "+":
-				result = a + b
-			case "-":
-				result = a - b
-			case "*":
-				result = a * b
-			case "/":
-				if b == 0 {
-					return 0, fmt.Errorf("division by zero")
-				}
-				result = a / b
-			}
+// fromBool converts the outcome of a comparison to its numeric form, 1 or 0.
+func fromBool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
 
-			stack = append(stack, result)
+// eval runs the full Scan, Parse, Evaluate pipeline on an infix expression.
+// Trivial expressions take a shortcut that skips Parse and Evaluate.
+func eval(expression string, opts ...Option) (float64, error) {
+	cfg := newConfig(opts)
 
-		default:
-			// Must be a number
-			num, err := strconv.ParseFloat(token, 64)
-			if err != nil {
-				return 0, fmt.Errorf("invalid number: %s", token)
-			}
-			stack = append(stack, num)
+	tokens, _, err := scan(expression, cfg, false)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rounding and a policy for non-finite results apply to every result,
+	// and limits and a context to every evaluation, so they leave no shortcut
+	if !cfg.rounding && cfg.nonFinite == nonFinitePropagate && !cfg.bounded() {
+		if result, ok := evalTrivial(tokens); ok {
+			return result, nil
 		}
 	}
 
-	// Should have exactly one value left
-	if len(stack) != 1 {
-		return 0, fmt.Errorf("invalid expression: too many operands")
+	postfix, err := parseSource(expression, tokens, cfg)
+	if err != nil {
+		return 0, err
 	}
 
-	return stack[0], nil
+	return evaluate(postfix, cfg)
+}
+
+// parseSource parses the tokens scanned without spans from expression. An
+// error in a multi-line expression gives its line and column rather than a
+// token index, from scanning again with spans.
+func parseSource(expression string, tokens []string, cfg config) ([]string, error) {
+	postfix, _, err := parse(tokens, nil, cfg)
+	if err != nil && strings.Contains(expression, "\n") {
+		cfg.source = expression
+		tokens, spans, _ := scan(expression, cfg, true)
+		_, _, err = parse(tokens, spans, cfg)
+	}
+	return postfix, err
 }
@@ -1,7 +1,10 @@
 package shuntingyard
 
 import (
+	"errors"
 	"math"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -60,11 +63,121 @@ func TestScan(t *testing.T) {
 			expected: []string{"100", "/", "2", "-", "3", "*", "4", "+", "5"},
 			wantErr:  false,
 		},
+		{
+			name:     "modulo",
+			input:    "10 % 3",
+			expected: []string{"10", "%", "3"},
+			wantErr:  false,
+		},
+		{
+			name:     "exponentiation",
+			input:    "2^3 ^ 2",
+			expected: []string{"2", "^", "3", "^", "2"},
+			wantErr:  false,
+		},
+		{
+			name:     "comparisons",
+			input:    "1<2 > 3<=4>=5 == 6!=7",
+			expected: []string{"1", "<", "2", ">", "3", "<=", "4", ">=", "5", "==", "6", "!=", "7"},
+			wantErr:  false,
+		},
 		{
 			name:    "invalid character",
 			input:   "2 + 3a",
 			wantErr: true,
 		},
+		{
+			name:     "dotted path",
+			input:    "order.total*1.5+user.age",
+			expected: []string{"order.total", "*", "1.5", "+", "user.age"},
+			wantErr:  false,
+		},
+		{
+			name:     "assignment",
+			input:    "x = 3",
+			expected: []string{"x", "=", "3"},
+			wantErr:  false,
+		},
+		{
+			name:     "logical operators",
+			input:    "!1&&0 || 1",
+			expected: []string{"!", "1", "&&", "0", "||", "1"},
+			wantErr:  false,
+		},
+		{
+			name:     "conditional",
+			input:    "1>0?2:3",
+			expected: []string{"1", ">", "0", "?", "2", ":", "3"},
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise operators",
+			input:    "5&3|1 xor~2 && 1",
+			expected: []string{"5", "&", "3", "|", "1", "xor", "~", "2", "&&", "1"},
+			wantErr:  false,
+		},
+		{
+			name:     "shifts",
+			input:    "1<<20 >> 2<3",
+			expected: []string{"1", "<<", "20", ">>", "2", "<", "3"},
+			wantErr:  false,
+		},
+		{
+			name:     "names",
+			input:    "sqrt(2)+log10 (x_1)*xorb",
+			expected: []string{"sqrt", "(", "2", ")", "+", "log10", "(", "x_1", ")", "*", "xorb"},
+			wantErr:  false,
+		},
+		{
+			name:     "scientific notation",
+			input:    "1e5+2.5E-3*6.02e+23",
+			expected: []string{"1e5", "+", "2.5E-3", "*", "6.02e+23"},
+			wantErr:  false,
+		},
+		{
+			name:     "hexadecimal, binary and octal",
+			input:    "0xFF+0b1010*0O755-0XaB",
+			expected: []string{"255", "+", "10", "*", "493", "-", "171"},
+			wantErr:  false,
+		},
+		{
+			name:     "typographic operators",
+			input:    "6×2÷3−1·4",
+			expected: []string{"6", "*", "2", "/", "3", "-", "1", "*", "4"},
+			wantErr:  false,
+		},
+		{
+			name:     "longest operator match",
+			input:    "a<=b!=c&&!d||e>>=2**3",
+			expected: []string{"a", "<=", "b", "!=", "c", "&&", "!", "d", "||", "e", ">>", "=", "2", "^", "3"},
+			wantErr:  false,
+		},
+		{
+			name:     "comments",
+			input:    "2 # rate\n* 3//per unit\n+ /* fee */4 /**/",
+			expected: []string{"2", "*", "3", "+", "4"},
+			wantErr:  false,
+		},
+		{
+			name:    "only a comment",
+			input:   "// nothing here",
+			wantErr: true,
+		},
+		{
+			name:    "digit outside base",
+			input:   "0b102",
+			wantErr: true,
+		},
+		{
+			name:    "exponent without digits",
+			input:   "2e+",
+			wantErr: true,
+		},
+		{
+			name:    "name directly after number",
+			input:   "2sqrt(4)",
+			wantErr: true,
+		},
 		{
 			name:    "empty expression",
 			input:   "",
@@ -107,6 +220,52 @@ func TestScan(t *testing.T) {
 	}
 }
 
+// TestScanErrors tests that Scan reports typed errors for each failure case
+func TestScanErrors(t *testing.T) {
+	t.Run("empty expression", func(t *testing.T) {
+		_, err := Scan("")
+		if !errors.Is(err, ErrEmptyExpression) {
+			t.Errorf("Scan() error = %v, expected ErrEmptyExpression", err)
+		}
+	})
+
+	t.Run("only spaces", func(t *testing.T) {
+		_, err := Scan("   ")
+		if !errors.Is(err, ErrEmptyExpression) {
+			t.Errorf("Scan() error = %v, expected ErrEmptyExpression", err)
+		}
+	})
+
+	t.Run("invalid character", func(t *testing.T) {
+		_, err := Scan("2 + 3a")
+		var charErr *InvalidCharError
+		if !errors.As(err, &charErr) {
+			t.Fatalf("Scan() error = %v, expected *InvalidCharError", err)
+		}
+		if charErr.Rune != 'a' || charErr.Pos != 5 {
+			t.Errorf("InvalidCharError = {%q, %d}, expected {'a', 5}", charErr.Rune, charErr.Pos)
+		}
+	})
+
+	t.Run("unterminated comment", func(t *testing.T) {
+		_, err := Scan("1 + /* 2")
+		if !errors.Is(err, ErrUnterminatedComment) {
+			t.Errorf("Scan() error = %v, expected ErrUnterminatedComment", err)
+		}
+	})
+
+	t.Run("invalid multi-byte character", func(t *testing.T) {
+		_, err := Scan("2 € 3")
+		var charErr *InvalidCharError
+		if !errors.As(err, &charErr) {
+			t.Fatalf("Scan() error = %v, expected *InvalidCharError", err)
+		}
+		if charErr.Rune != '€' || charErr.Pos != 2 {
+			t.Errorf("InvalidCharError = {%q, %d}, expected {'€', 2}", charErr.Rune, charErr.Pos)
+		}
+	})
+}
+
 // TestParse tests the conversion from infix to postfix notation
 func TestParse(t *testing.T) {
 	tests := []struct {
@@ -152,59 +311,677 @@ func TestParse(t *testing.T) {
 			wantErr:  false,
 		},
 		{
-			name:     "left associativity",
-			input:    []string{"1", "+", "2", "+", "3", "+", "4", "+", "5"},
-			expected: []string{"1", "2", "+", "3", "+", "4", "+", "5", "+"},
-			wantErr:  false,
+			name:     "left associativity",
+			input:    []string{"1", "+", "2", "+", "3", "+", "4", "+", "5"},
+			expected: []string{"1", "2", "+", "3", "+", "4", "+", "5", "+"},
+			wantErr:  false,
+		},
+		{
+			name:     "modulo shares precedence with multiplication",
+			input:    []string{"2", "+", "7", "%", "4", "*", "3"},
+			expected: []string{"2", "7", "4", "%", "3", "*", "+"},
+			wantErr:  false,
+		},
+		{
+			name:     "exponentiation is right associative",
+			input:    []string{"2", "^", "3", "^", "2"},
+			expected: []string{"2", "3", "2", "^", "^"},
+			wantErr:  false,
+		},
+		{
+			name:     "exponentiation binds tighter than multiplication",
+			input:    []string{"2", "*", "3", "^", "2"},
+			expected: []string{"2", "3", "2", "^", "*"},
+			wantErr:  false,
+		},
+		{
+			name:     "exponentiation after multiplication",
+			input:    []string{"2", "^", "3", "*", "2"},
+			expected: []string{"2", "3", "^", "2", "*"},
+			wantErr:  false,
+		},
+		{
+			name:     "parenthesized exponentiation",
+			input:    []string{"(", "2", "^", "3", ")", "^", "2"},
+			expected: []string{"2", "3", "^", "2", "^"},
+			wantErr:  false,
+		},
+		{
+			name:     "comparison binds looser than arithmetic",
+			input:    []string{"1", "+", "2", "<", "3", "*", "4"},
+			expected: []string{"1", "2", "+", "3", "4", "*", "<"},
+			wantErr:  false,
+		},
+		{
+			name:     "comparisons are left associative",
+			input:    []string{"1", "<", "2", "==", "1"},
+			expected: []string{"1", "2", "<", "1", "=="},
+			wantErr:  false,
+		},
+		{
+			name:     "logical AND binds tighter than OR",
+			input:    []string{"1", "||", "0", "&&", "0"},
+			expected: []string{"1", "0", "0", "&&", "||"},
+			wantErr:  false,
+		},
+		{
+			name:     "comparison binds tighter than logical AND",
+			input:    []string{"1", "<", "2", "&&", "3", ">", "4"},
+			expected: []string{"1", "2", "<", "3", "4", ">", "&&"},
+			wantErr:  false,
+		},
+		{
+			name:     "logical NOT binds tighter than comparison",
+			input:    []string{"!", "0", "==", "1"},
+			expected: []string{"0", "!", "1", "=="},
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise precedence follows C",
+			input:    []string{"1", "|", "2", "xor", "3", "&", "4", "==", "4"},
+			expected: []string{"1", "2", "3", "4", "4", "==", "&", "xor", "|"},
+			wantErr:  false,
+		},
+		{
+			name:     "shift binds looser than addition",
+			input:    []string{"1", "<<", "2", "+", "1", "<", "9"},
+			expected: []string{"1", "2", "1", "+", "<<", "9", "<"},
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise NOT is prefix",
+			input:    []string{"~", "5", "&", "7"},
+			expected: []string{"5", "~", "7", "&"},
+			wantErr:  false,
+		},
+		{
+			name:     "function call",
+			input:    []string{"sqrt", "(", "2", "+", "2", ")", "*", "3"},
+			expected: []string{"2", "2", "+", "sqrt/1", "3", "*"},
+			wantErr:  false,
+		},
+		{
+			name:     "nested function calls",
+			input:    []string{"abs", "(", "sin", "(", "1", ")", "-", "2", ")"},
+			expected: []string{"1", "sin/1", "2", "-", "abs/1"},
+			wantErr:  false,
+		},
+		{
+			name:     "function call with several arguments",
+			input:    []string{"max", "(", "1", ",", "2", "*", "3", ")"},
+			expected: []string{"1", "2", "3", "*", "max/2"},
+			wantErr:  false,
+		},
+		{
+			name:     "variables",
+			input:    []string{"x", "*", "2", "+", "y"},
+			expected: []string{"x", "2", "*", "y", "+"},
+			wantErr:  false,
+		},
+		{
+			name:     "variable as argument",
+			input:    []string{"max", "(", "x", ",", "-", "y", ")"},
+			expected: []string{"x", "y", "neg", "max/2"},
+			wantErr:  false,
+		},
+		{
+			name:     "call as argument",
+			input:    []string{"pow", "(", "2", ",", "max", "(", "3", ",", "4", ")", ")"},
+			expected: []string{"2", "3", "4", "max/2", "pow/2"},
+			wantErr:  false,
+		},
+		{
+			name:     "parenthesized argument",
+			input:    []string{"min", "(", "(", "1", "+", "2", ")", ",", "3", ")"},
+			expected: []string{"1", "2", "+", "3", "min/2"},
+			wantErr:  false,
+		},
+		{
+			name:     "conditional argument",
+			input:    []string{"max", "(", "1", "?", "2", ":", "3", ",", "4", ")"},
+			expected: []string{"1", "2", "3", "?:", "4", "max/2"},
+			wantErr:  false,
+		},
+		{
+			name:     "call without arguments",
+			input:    []string{"sum", "(", ")"},
+			expected: []string{"sum/0"},
+			wantErr:  false,
+		},
+		{
+			name:     "negated function call",
+			input:    []string{"-", "sqrt", "(", "4", ")", "^", "2"},
+			expected: []string{"4", "sqrt/1", "2", "^", "neg"},
+			wantErr:  false,
+		},
+		{
+			name:     "conditional",
+			input:    []string{"1", "?", "2", ":", "3"},
+			expected: []string{"1", "2", "3", "?:"},
+			wantErr:  false,
+		},
+		{
+			name:     "conditional binds loosest",
+			input:    []string{"1", "||", "0", "?", "2", "+", "3", ":", "4", "*", "5"},
+			expected: []string{"1", "0", "||", "2", "3", "+", "4", "5", "*", "?:"},
+			wantErr:  false,
+		},
+		{
+			name:     "conditional is right associative",
+			input:    []string{"1", "?", "2", ":", "3", "?", "4", ":", "5"},
+			expected: []string{"1", "2", "3", "4", "5", "?:", "?:"},
+			wantErr:  false,
+		},
+		{
+			name:     "conditional nested in first branch",
+			input:    []string{"1", "?", "2", "?", "3", ":", "4", ":", "5"},
+			expected: []string{"1", "2", "3", "4", "?:", "5", "?:"},
+			wantErr:  false,
+		},
+		{
+			name:     "parenthesized conditional",
+			input:    []string{"(", "1", "?", "2", ":", "3", ")", "*", "4"},
+			expected: []string{"1", "2", "3", "?:", "4", "*"},
+			wantErr:  false,
+		},
+		{
+			name:     "leading unary minus",
+			input:    []string{"-", "3", "+", "5"},
+			expected: []string{"3", "neg", "5", "+"},
+			wantErr:  false,
+		},
+		{
+			name:     "unary minus after operator",
+			input:    []string{"2", "*", "-", "4"},
+			expected: []string{"2", "4", "neg", "*"},
+			wantErr:  false,
+		},
+		{
+			name:     "negated group",
+			input:    []string{"-", "(", "2", "+", "3", ")"},
+			expected: []string{"2", "3", "+", "neg"},
+			wantErr:  false,
+		},
+		{
+			name:     "unary plus is dropped",
+			input:    []string{"+", "2", "-", "+", "3"},
+			expected: []string{"2", "3", "-"},
+			wantErr:  false,
+		},
+		{
+			name:     "exponentiation binds tighter than unary minus",
+			input:    []string{"-", "2", "^", "2"},
+			expected: []string{"2", "2", "^", "neg"},
+			wantErr:  false,
+		},
+		{
+			name:     "negative exponent",
+			input:    []string{"2", "^", "-", "1"},
+			expected: []string{"2", "1", "neg", "^"},
+			wantErr:  false,
+		},
+		{
+			name:     "double negation",
+			input:    []string{"-", "-", "3"},
+			expected: []string{"3", "neg", "neg"},
+			wantErr:  false,
+		},
+		{
+			name:    "mismatched parentheses - extra right",
+			input:   []string{"2", "+", "3", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "mismatched parentheses - extra left",
+			input:   []string{"(", "2", "+", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "empty token list",
+			input:   []string{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid token",
+			input:   []string{"2", "+", "1abc"},
+			wantErr: true,
+		},
+		{
+			name:    "missing right operand",
+			input:   []string{"2", "+"},
+			wantErr: true,
+		},
+		{
+			name:    "missing left operand",
+			input:   []string{"*", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "consecutive operators",
+			input:   []string{"2", "+", "*", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "consecutive numbers",
+			input:   []string{"2", "3", "+", "4"},
+			wantErr: true,
+		},
+		{
+			name:    "logical NOT after operand",
+			input:   []string{"2", "!"},
+			wantErr: true,
+		},
+		{
+			name:    "conditional without else branch",
+			input:   []string{"1", "?", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "else branch without condition",
+			input:   []string{"1", ":", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "conditional split by parentheses",
+			input:   []string{"(", "1", "?", "2", ")", ":", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "function without parentheses",
+			input:   []string{"sqrt", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown function",
+			input:   []string{"foo", "(", "2", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "comma outside call",
+			input:   []string{"1", ",", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "comma in grouping parentheses",
+			input:   []string{"max", "(", "(", "1", ",", "2", ")", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "missing argument",
+			input:   []string{"max", "(", "1", ",", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "empty grouping parentheses after call",
+			input:   []string{"max", "(", "1", ",", "(", ")", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "sign without operand",
+			input:   []string{"2", "*", "-"},
+			wantErr: true,
+		},
+		{
+			name:    "empty parentheses",
+			input:   []string{"(", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "operator before closing parenthesis",
+			input:   []string{"(", "2", "+", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "number before opening parenthesis",
+			input:   []string{"2", "(", "3", ")"},
+			wantErr: true,
+		},
+		{
+			name:    "trailing opening parenthesis",
+			input:   []string{"2", "*", "("},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.input)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Parse() unexpected error: %v", err)
+				return
+			}
+
+			if len(result) != len(tt.expected) {
+				t.Errorf("Parse() got %d tokens, expected %d", len(result), len(tt.expected))
+				t.Errorf("Got: %v, Expected: %v", result, tt.expected)
+				return
+			}
+
+			for i, token := range result {
+				if token != tt.expected[i] {
+					t.Errorf("Parse() token[%d] = %s, expected %s", i, token, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+// TestParseErrorMessages tests that parse errors state what was expected and where
+func TestParseErrorMessages(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{
+			name:       "operator at start",
+			expression: "* 3",
+			expected:   "expected number or '(' at start of expression, found '*' at token 0",
+		},
+		{
+			name:       "consecutive operators",
+			expression: "2 + * 3",
+			expected:   "expected number or '(' after '+' at token 1, found '*' at token 2",
+		},
+		{
+			name:       "closing parenthesis after operator",
+			expression: "(2 + ) * 3",
+			expected:   "expected number or '(' after '+' at token 2, found ')' at token 3",
+		},
+		{
+			name:       "empty parentheses",
+			expression: "()",
+			expected:   "empty parentheses at token 0",
+		},
+		{
+			name:       "trailing operator",
+			expression: "2 +",
+			expected:   "expected number or '(' after '+' at token 1, found end of expression",
+		},
+		{
+			name:       "consecutive numbers",
+			expression: "2 3",
+			expected:   "expected operator or end of expression after number 2 at token 0, found number 3 at token 1",
+		},
+		{
+			name:       "number inside parentheses",
+			expression: "(2 3)",
+			expected:   "expected operator or ')' after number 2 at token 1, found number 3 at token 2",
+		},
+		{
+			name:       "opening parenthesis after number",
+			expression: "2 (3)",
+			expected:   "expected operator or end of expression after number 2 at token 0, found '(' at token 1",
+		},
+		{
+			name:       "function without parentheses",
+			expression: "1 + sqrt 4",
+			expected:   "expected '(' after function 'sqrt' at token 2",
+		},
+		{
+			name:       "unknown function",
+			expression: "2 * foo(1)",
+			expected:   "unknown function 'foo' at token 2",
+		},
+		{
+			name:       "assignment outside session",
+			expression: "1 =< 2",
+			expected:   "unexpected '=' at token 1; assignment is only valid as 'name = expression' in a Session",
+		},
+		{
+			name:       "variable after number",
+			expression: "2 foo",
+			expected:   "expected operator or end of expression after number 2 at token 0, found variable foo at token 1",
+		},
+		{
+			name:       "function after number",
+			expression: "2 sqrt(4)",
+			expected:   "expected operator or end of expression after number 2 at token 0, found function sqrt at token 1",
+		},
+		{
+			name:       "comma outside call",
+			expression: "(1, 2)",
+			expected:   "unexpected ',' outside function call at token 2",
+		},
+		{
+			name:       "unmatched conditional in argument",
+			expression: "max(1 ? 2, 3)",
+			expected:   "mismatched conditional: unmatched '?' at token 3",
+		},
+		{
+			name:       "unmatched conditional",
+			expression: "1 ? 2",
+			expected:   "mismatched conditional: unmatched '?' at token 1",
+		},
+		{
+			name:       "unmatched else branch",
+			expression: "(1 : 2)",
+			expected:   "mismatched conditional: unmatched ':' at token 2",
+		},
+		{
+			name:       "two numbers in a row",
+			expression: "2 3 + 4",
+			expected:   "expected operator or end of expression after number 2 at token 0, found number 3 at token 1",
+		},
+		{
+			name:       "two operators in a row",
+			expression: "+ * 2",
+			expected:   "expected number or '(' after '+' at token 0, found '*' at token 1",
+		},
+		{
+			name:       "nested empty parentheses",
+			expression: "2 * (1 + ())",
+			expected:   "empty parentheses at token 5",
+		},
+		{
+			name:       "too few arguments",
+			expression: "2 * pow(2)",
+			expected:   "function 'pow' takes 2 arguments, got 1 at token 2",
+		},
+		{
+			name:       "too many arguments",
+			expression: "max(1, sqrt(4, 9))",
+			expected:   "function 'sqrt' takes 1 argument, got 2 at token 4",
+		},
+		{
+			name:       "empty call",
+			expression: "max()",
+			expected:   "function 'max' takes at least 1 argument, got 0 at token 0",
+		},
+		{
+			name:       "unmatched closing parenthesis",
+			expression: "2 + 3)",
+			expected:   "mismatched parentheses: unmatched ')' at token 3",
 		},
 		{
-			name:    "mismatched parentheses - extra right",
-			input:   []string{"2", "+", "3", ")"},
-			wantErr: true,
+			name:       "unmatched opening parenthesis",
+			expression: "2 * (3 + 4",
+			expected:   "mismatched parentheses: unmatched '(' at token 2",
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+
+			_, err = Parse(tokens)
+			if err == nil {
+				t.Fatalf("Parse() expected error, got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Parse() error = %q, expected %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseErrorRecovery tests that WithErrorRecovery reports every error Parse can recover from
+func TestParseErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   []string
+	}{
 		{
-			name:    "mismatched parentheses - extra left",
-			input:   []string{"(", "2", "+", "3"},
-			wantErr: true,
+			name:       "missing operand and unmatched parenthesis",
+			expression: "(2 + * 3",
+			expected: []string{
+				"expected number or '(' after '+' at token 2, found '*' at token 3",
+				"mismatched parentheses: unmatched '(' at token 0",
+			},
 		},
 		{
-			name:    "empty token list",
-			input:   []string{},
-			wantErr: true,
+			name:       "calls and invalid number",
+			expression: "f(1) + pow(2) * 1.2.3 +",
+			expected: []string{
+				"unknown function 'f' at token 0",
+				"function 'pow' takes 2 arguments, got 1 at token 5",
+				"invalid number: 1.2.3",
+				"expected number or '(' after '+' at token 11, found end of expression",
+			},
 		},
 		{
-			name:    "invalid token",
-			input:   []string{"2", "+", "abc"},
-			wantErr: true,
+			name:       "conditional and comma",
+			expression: "max(1, 2 ? 3), 4)",
+			expected: []string{
+				"mismatched conditional: unmatched '?' at token 5",
+				"unexpected ',' outside function call at token 8",
+				"mismatched parentheses: unmatched ')' at token 10",
+			},
+		},
+		{
+			name:       "function without parentheses",
+			expression: "sqrt 4 + (1",
+			expected: []string{
+				"expected '(' after function 'sqrt' at token 0",
+				"mismatched parentheses: unmatched '(' at token 3",
+			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := Parse(tt.input)
+			tokens, err := Scan(tt.expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
 
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("Parse() expected error, got nil")
-				}
-				return
+			postfix, err := Parse(tokens, WithErrorRecovery())
+			if postfix != nil {
+				t.Errorf("Parse() = %v, expected no postfix", postfix)
+			}
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok {
+				t.Fatalf("Parse() error = %v, expected joined errors", err)
+			}
+			var messages []string
+			for _, err := range joined.Unwrap() {
+				messages = append(messages, err.Error())
+			}
+			if !slices.Equal(messages, tt.expected) {
+				t.Errorf("Parse() errors = %q, expected %q", messages, tt.expected)
 			}
 
-			if err != nil {
-				t.Errorf("Parse() unexpected error: %v", err)
-				return
+			// Without recovery Parse stops at the first
+			_, err = Parse(tokens)
+			if err == nil || err.Error() != tt.expected[0] {
+				t.Errorf("Parse() without recovery error = %v, expected %q", err, tt.expected[0])
 			}
+		})
+	}
 
-			if len(result) != len(tt.expected) {
-				t.Errorf("Parse() got %d tokens, expected %d", len(result), len(tt.expected))
-				t.Errorf("Got: %v, Expected: %v", result, tt.expected)
-				return
+	t.Run("valid input", func(t *testing.T) {
+		postfix, err := Parse([]string{"2", "*", "(", "3", "+", "4", ")"}, WithErrorRecovery())
+		if err != nil {
+			t.Fatalf("Parse() unexpected error: %v", err)
+		}
+		if !slices.Equal(postfix, []string{"2", "3", "4", "+", "*"}) {
+			t.Errorf("Parse() = %v, expected [2 3 4 + *]", postfix)
+		}
+	})
+
+}
+
+// TestParseSpansErrorPosition tests that parse errors use byte offsets when spans are known
+func TestParseSpansErrorPosition(t *testing.T) {
+	tokens, spans, err := ScanSpans("(10 + 2 + ) * 3")
+	if err != nil {
+		t.Fatalf("ScanSpans() unexpected error: %v", err)
+	}
+
+	_, _, err = ParseSpans(tokens, spans)
+	expected := "expected number or '(' after '+' at position 8, found ')' at position 10"
+	if err == nil || err.Error() != expected {
+		t.Errorf("ParseSpans() error = %v, expected %q", err, expected)
+	}
+}
+
+// TestParseExtremeNesting tests that deeply nested parentheses don't exhaust the stack
+func TestParseExtremeNesting(t *testing.T) {
+	const depth = 100000
+	expression := strings.Repeat("(", depth) + "1 + 2" + strings.Repeat(")", depth) + " * 3"
+
+	tokens, err := Scan(expression)
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	postfix, err := Parse(tokens)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	result, err := Evaluate(postfix)
+	if err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+	if result != 9 {
+		t.Errorf("Evaluate() = %v, expected 9", result)
+	}
+}
+
+// TestParseMaxDepth tests the configurable nesting cap
+func TestParseMaxDepth(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		maxDepth   int
+		wantErr    bool
+	}{
+		{name: "within limit", expression: "((1 + 2)) * 3", maxDepth: 2},
+		{name: "sequential groups within limit", expression: "(1) + (2) + ((3))", maxDepth: 2},
+		{name: "no limit", expression: strings.Repeat("(", 1000) + "1" + strings.Repeat(")", 1000), maxDepth: 0},
+		{name: "exceeds limit", expression: "(((1)))", maxDepth: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
 			}
 
-			for i, token := range result {
-				if token != tt.expected[i] {
-					t.Errorf("Parse() token[%d] = %s, expected %s", i, token, tt.expected[i])
+			_, err = Parse(tokens, WithMaxDepth(tt.maxDepth))
+			if tt.wantErr {
+				expected := "nesting depth exceeds limit of 2 at token 2"
+				if err == nil || err.Error() != expected {
+					t.Errorf("Parse() error = %v, expected %q", err, expected)
 				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Parse() unexpected error: %v", err)
 			}
 		})
 	}
@@ -266,11 +1043,237 @@ func TestEvaluate(t *testing.T) {
 			expected: 3.333333333333333,
 			wantErr:  false,
 		},
+		{
+			name:     "simple exponentiation",
+			input:    []string{"2", "10", "^"},
+			expected: 1024.0,
+			wantErr:  false,
+		},
+		{
+			name:     "fractional exponent",
+			input:    []string{"16", "0.5", "^"},
+			expected: 4.0,
+			wantErr:  false,
+		},
+		{
+			name:     "simple modulo",
+			input:    []string{"10", "3", "%"},
+			expected: 1.0,
+			wantErr:  false,
+		},
+		{
+			name:     "floating point modulo",
+			input:    []string{"5.5", "2", "%"},
+			expected: 1.5,
+			wantErr:  false,
+		},
+		{
+			name:     "modulo keeps sign of dividend",
+			input:    []string{"0", "7", "-", "3", "%"},
+			expected: -1.0,
+			wantErr:  false,
+		},
 		{
 			name:    "division by zero",
 			input:   []string{"10", "0", "/"},
 			wantErr: true,
 		},
+		{
+			name:    "modulo by zero",
+			input:   []string{"10", "0", "%"},
+			wantErr: true,
+		},
+		{
+			name:     "less than holds",
+			input:    []string{"2", "3", "<"},
+			expected: 1.0,
+			wantErr:  false,
+		},
+		{
+			name:     "greater than fails",
+			input:    []string{"2", "3", ">"},
+			expected: 0.0,
+			wantErr:  false,
+		},
+		{
+			name:     "less or equal on equal operands",
+			input:    []string{"3", "3", "<="},
+			expected: 1.0,
+			wantErr:  false,
+		},
+		{
+			name:     "not equal",
+			input:    []string{"3", "3", "!="},
+			expected: 0.0,
+			wantErr:  false,
+		},
+		{
+			name:     "logical AND",
+			input:    []string{"2", "0.5", "&&"},
+			expected: 1.0,
+			wantErr:  false,
+		},
+		{
+			name:     "logical AND with false operand",
+			input:    []string{"2", "0", "&&"},
+			expected: 0.0,
+			wantErr:  false,
+		},
+		{
+			name:     "logical OR",
+			input:    []string{"0", "-3", "||"},
+			expected: 1.0,
+			wantErr:  false,
+		},
+		{
+			name:     "logical NOT",
+			input:    []string{"5", "!"},
+			expected: 0.0,
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise AND",
+			input:    []string{"12", "10", "&"},
+			expected: 8.0,
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise OR",
+			input:    []string{"12", "3", "|"},
+			expected: 15.0,
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise XOR",
+			input:    []string{"12", "10", "xor"},
+			expected: 6.0,
+			wantErr:  false,
+		},
+		{
+			name:     "bitwise NOT",
+			input:    []string{"0", "~"},
+			expected: -1.0,
+			wantErr:  false,
+		},
+		{
+			name:     "left shift",
+			input:    []string{"1", "20", "<<"},
+			expected: 1048576.0,
+			wantErr:  false,
+		},
+		{
+			name:     "right shift keeps sign",
+			input:    []string{"-16", "2", ">>"},
+			expected: -4.0,
+			wantErr:  false,
+		},
+		{
+			name:    "negative shift count",
+			input:   []string{"1", "-1", "<<"},
+			wantErr: true,
+		},
+		{
+			name:    "shift count beyond 63",
+			input:   []string{"1", "64", ">>"},
+			wantErr: true,
+		},
+		{
+			name:    "left shift into the sign bit",
+			input:   []string{"1", "63", "<<"},
+			wantErr: true,
+		},
+		{
+			name:    "left shift beyond 64 bits",
+			input:   []string{"3", "62", "<<"},
+			wantErr: true,
+		},
+		{
+			name:     "left shift of a negative value",
+			input:    []string{"-1", "63", "<<"},
+			expected: -9223372036854775808.0,
+			wantErr:  false,
+		},
+		{
+			name:    "fractional shift count",
+			input:   []string{"1", "0.5", ">>"},
+			wantErr: true,
+		},
+		{
+			name:    "bitwise operator on fraction",
+			input:   []string{"2.5", "1", "&"},
+			wantErr: true,
+		},
+		{
+			name:    "bitwise NOT on fraction",
+			input:   []string{"0.5", "~"},
+			wantErr: true,
+		},
+		{
+			name:    "bitwise operator beyond 64 bits",
+			input:   []string{"1e19", "1", "|"},
+			wantErr: true,
+		},
+		{
+			name:     "function",
+			input:    []string{"16", "sqrt/1", "1", "+"},
+			expected: 5.0,
+			wantErr:  false,
+		},
+		{
+			name:    "function without argument",
+			input:   []string{"sqrt/1"},
+			wantErr: true,
+		},
+		{
+			name:     "function with two arguments",
+			input:    []string{"2", "10", "pow/2"},
+			expected: 1024.0,
+			wantErr:  false,
+		},
+		{
+			name:    "wrong argument count",
+			input:   []string{"1", "2", "sqrt/2"},
+			wantErr: true,
+		},
+		{
+			name:     "variadic function",
+			input:    []string{"1", "5", "3", "max/3"},
+			expected: 5.0,
+			wantErr:  false,
+		},
+		{
+			name:    "argument count exceeds stack",
+			input:   []string{"1", "2", "max/3"},
+			wantErr: true,
+		},
+		{
+			name:     "conditional takes first branch",
+			input:    []string{"1", "2", "3", "?:"},
+			expected: 2.0,
+			wantErr:  false,
+		},
+		{
+			name:     "conditional takes second branch",
+			input:    []string{"0", "2", "3", "?:"},
+			expected: 3.0,
+			wantErr:  false,
+		},
+		{
+			name:    "conditional without enough operands",
+			input:   []string{"1", "2", "?:"},
+			wantErr: true,
+		},
+		{
+			name:     "negation",
+			input:    []string{"2", "neg", "3", "*"},
+			expected: -6.0,
+			wantErr:  false,
+		},
+		{
+			name:    "negation without operand",
+			input:   []string{"neg", "2"},
+			wantErr: true,
+		},
 		{
 			name:    "insufficient operands",
 			input:   []string{"2", "+"},
@@ -345,6 +1348,65 @@ func TestFullPipeline(t *testing.T) {
 		{name: "float addition", expression: "1.5 + 2.5", expected: 4.0},
 		{name: "float complex", expression: "10.5 / 2 + 3.5", expected: 8.75},
 		{name: "repeating decimal", expression: "10 / 3", expected: 3.333333333333333},
+		{name: "scientific notation", expression: "6.02e23 / 2E-3", expected: 3.01e26},
+		{name: "typographic operators", expression: "−(12 ÷ 4) × 2 · 3", expected: -18},
+		{name: "prefixed integers", expression: "0xFF & 0b1111 | 0o700", expected: 463},
+
+		// Exponentiation
+		{name: "power", expression: "2 ^ 10", expected: 1024.0},
+		{name: "right associative power", expression: "2 ^ 3 ^ 2", expected: 512.0},
+		{name: "grouped power", expression: "(2 ^ 3) ^ 2", expected: 64.0},
+		{name: "double asterisk power", expression: "2 ** 3 ** 2", expected: 512.0},
+		{name: "power before multiplication", expression: "3 * 2 ^ 2", expected: 12.0},
+		{name: "square root", expression: "2 ^ 0.5", expected: 1.4142135623730951},
+
+		// Modulo
+		{name: "modulo", expression: "10 % 3", expected: 1.0},
+		{name: "modulo before addition", expression: "1 + 10 % 4", expected: 3.0},
+		{name: "modulo left to right", expression: "20 % 6 * 2", expected: 4.0},
+
+		// Unary operators
+		{name: "leading minus", expression: "-3 + 5", expected: 2.0},
+		{name: "minus after operator", expression: "2 * -4", expected: -8.0},
+		{name: "negated group", expression: "-(2 + 3)", expected: -5.0},
+		{name: "subtract negative", expression: "2--3", expected: 5.0},
+		{name: "unary plus", expression: "+2 * +3", expected: 6.0},
+		{name: "negated power", expression: "-2 ^ 2", expected: -4.0},
+		{name: "negative exponent", expression: "2 ^ -1", expected: 0.5},
+
+		// Comparisons
+		{name: "comparison scales result", expression: "(5 > 3) * 10", expected: 10.0},
+		{name: "failed comparison", expression: "(5 < 3) * 10", expected: 0.0},
+		{name: "comparison after arithmetic", expression: "1 + 1 == 2", expected: 1.0},
+		{name: "greater or equal", expression: "2 >= 2", expected: 1.0},
+		{name: "not equal", expression: "2 != 2.5", expected: 1.0},
+		{name: "chained comparison", expression: "3 > 2 > 1", expected: 0.0},
+
+		// Logical operators
+		{name: "policy expression", expression: "(3 > 2 && 1 == 1) * 10", expected: 10.0},
+		{name: "logical OR of comparisons", expression: "1 > 2 || 2 > 1", expected: 1.0},
+		{name: "logical NOT of group", expression: "!(1 && 0)", expected: 1.0},
+		{name: "double logical NOT", expression: "!!7", expected: 1.0},
+		{name: "logical NOT before comparison", expression: "!0 == 1", expected: 1.0},
+
+		// Bitwise operators
+		{name: "register mask", expression: "(255 & ~15) | 3", expected: 243.0},
+		{name: "bitwise XOR", expression: "6 xor 3", expected: 5.0},
+		{name: "bitwise AND of negative", expression: "-1 & 7", expected: 7.0},
+		{name: "non-integer bitwise operand", expression: "1.5 | 1", wantErr: true},
+
+		// Shifts
+		{name: "size in bytes", expression: "(1 << 20) + 4096", expected: 1052672.0},
+		{name: "shift count is a sum", expression: "1 << 2 + 1", expected: 8.0},
+		{name: "right shift", expression: "1024 >> 3", expected: 128.0},
+		{name: "shift count sum beyond 63", expression: "1 << 20 + 4096", wantErr: true},
+		{name: "shift before comparison", expression: "1 << 4 == 16", expected: 1.0},
+
+		// Conditionals
+		{name: "absolute value", expression: "-4 > 0 ? -4 : 0 - -4", expected: 4.0},
+		{name: "conditional in arithmetic", expression: "(1 < 2 ? 10 : 20) + 1", expected: 11.0},
+		{name: "chained conditional", expression: "0 ? 1 : 0 ? 2 : 3", expected: 3.0},
+		{name: "negated branch", expression: "1 ? -2 : 2", expected: -2.0},
 
 		// Spacing variations
 		{name: "no spaces", expression: "1+2", expected: 3.0},
@@ -356,8 +1418,11 @@ func TestFullPipeline(t *testing.T) {
 
 		// Error cases
 		{name: "division by zero", expression: "10 / 0", wantErr: true},
+		{name: "modulo by zero", expression: "10 % 0", wantErr: true},
 		{name: "invalid character", expression: "2 + a", wantErr: true},
 		{name: "mismatched parens", expression: "(2 + 3", wantErr: true},
+		{name: "trailing operator", expression: "2 +", wantErr: true},
+		{name: "leading operator", expression: "* 3", wantErr: true},
 	}
 
 	for _, tt := range tests {
@@ -401,10 +1466,36 @@ func TestFullPipeline(t *testing.T) {
 	}
 }
 
+// TestPipelineAllocations guards against per-token heap allocations creeping back in
+func TestPipelineAllocations(t *testing.T) {
+	expression := "100 / 2 - 3 * 4 + 5"
+	tokens, _ := Scan(expression)
+	postfix, _ := Parse(tokens)
+
+	tests := []struct {
+		name string
+		max  float64
+		run  func()
+	}{
+		{name: "Scan", max: 1, run: func() { _, _ = Scan(expression) }},
+		{name: "Parse", max: 2, run: func() { _, _ = Parse(tokens) }},
+		{name: "Evaluate", max: 1, run: func() { _, _ = Evaluate(postfix) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.run); allocs > tt.max {
+				t.Errorf("%s allocated %v times per run, expected at most %v", tt.name, allocs, tt.max)
+			}
+		})
+	}
+}
+
 // BenchmarkFullPipeline benchmarks the complete evaluation pipeline
 func BenchmarkFullPipeline(b *testing.B) {
 	expression := "100 / 2 - 3 * 4 + 5"
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		tokens, _ := Scan(expression)
@@ -412,3 +1503,37 @@ func BenchmarkFullPipeline(b *testing.B) {
 		_, _ = Evaluate(postfix)
 	}
 }
+
+// BenchmarkScan benchmarks tokenization and reports allocations per expression
+func BenchmarkScan(b *testing.B) {
+	expression := "100 / 2 - 3 * 4 + 5"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Scan(expression)
+	}
+}
+
+// BenchmarkParse benchmarks infix to postfix conversion and reports allocations
+func BenchmarkParse(b *testing.B) {
+	tokens, _ := Scan("100 / 2 - 3 * 4 + 5")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Parse(tokens)
+	}
+}
+
+// BenchmarkEvaluate benchmarks postfix evaluation and reports allocations
+func BenchmarkEvaluate(b *testing.B) {
+	tokens, _ := Scan("100 / 2 - 3 * 4 + 5")
+	postfix, _ := Parse(tokens)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Evaluate(postfix)
+	}
+}
@@ -0,0 +1,42 @@
+package shuntingyard
+
+import "fmt"
+
+// Span is the byte range [Start, End) of a token in the source expression.
+type Span struct {
+	Start int
+	End   int
+}
+
+// ScanSpans tokenizes an expression like Scan and also returns the source span
+// of every token, so later stages can point back into the original text.
+//
+// Returns the tokens and their spans (spans[i] belongs to tokens[i]), or the Scan error.
+func ScanSpans(expression string, opts ...Option) ([]string, []Span, error) {
+	return scan(expression, newConfig(opts), true)
+}
+
+// ParseSpans converts infix tokens to postfix like Parse and carries the source
+// span of each token through the conversion, producing a source map from every
+// output RPN token back to its position in the original expression. spans must
+// hold one entry per token, as returned by ScanSpans.
+//
+// When Evaluate fails with a *TokenError, the returned spans locate the
+// offending token in the source:
+//
+//	tokens, spans, _ := ScanSpans(expression)
+//	postfix, postfixSpans, _ := ParseSpans(tokens, spans)
+//	_, err := Evaluate(postfix)
+//	var tokenErr *TokenError
+//	if errors.As(err, &tokenErr) {
+//		span := postfixSpans[tokenErr.Index]
+//		fmt.Println(expression[span.Start:span.End])
+//	}
+//
+// Returns postfix tokens and their spans, or the Parse error.
+func ParseSpans(tokens []string, spans []Span, opts ...Option) ([]string, []Span, error) {
+	if len(spans) != len(tokens) {
+		return nil, nil, fmt.Errorf("got %d spans for %d tokens", len(spans), len(tokens))
+	}
+	return parse(tokens, spans, newConfig(opts))
+}
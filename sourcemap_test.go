@@ -0,0 +1,108 @@
+package shuntingyard
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestScanSpans tests that every token is mapped to its byte range
+func TestScanSpans(t *testing.T) {
+	expression := "(10.5 +2)* 3"
+
+	tokens, spans, err := ScanSpans(expression)
+	if err != nil {
+		t.Fatalf("ScanSpans() unexpected error: %v", err)
+	}
+
+	expected := []Span{{0, 1}, {1, 5}, {6, 7}, {7, 8}, {8, 9}, {9, 10}, {11, 12}}
+	if len(spans) != len(expected) || len(tokens) != len(expected) {
+		t.Fatalf("ScanSpans() got %d tokens and %d spans, expected %d", len(tokens), len(spans), len(expected))
+	}
+
+	for i, span := range spans {
+		if span != expected[i] {
+			t.Errorf("ScanSpans() span[%d] = %v, expected %v", i, span, expected[i])
+		}
+		if source := expression[span.Start:span.End]; source != tokens[i] {
+			t.Errorf("ScanSpans() span[%d] covers %q, token is %q", i, source, tokens[i])
+		}
+	}
+}
+
+// TestParseSpans tests that postfix tokens keep the spans of their source tokens
+func TestParseSpans(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   []string
+	}{
+		{name: "precedence", expression: "2 + 3 * 4", expected: []string{"2", "3", "4", "*", "+"}},
+		{name: "parentheses", expression: "(2 + 3) * 4", expected: []string{"2", "3", "+", "4", "*"}},
+		{name: "left associativity", expression: "8 - 4 - 2", expected: []string{"8", "4", "-", "2", "-"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, spans, err := ScanSpans(tt.expression)
+			if err != nil {
+				t.Fatalf("ScanSpans() unexpected error: %v", err)
+			}
+
+			postfix, postfixSpans, err := ParseSpans(tokens, spans)
+			if err != nil {
+				t.Fatalf("ParseSpans() unexpected error: %v", err)
+			}
+
+			if len(postfix) != len(tt.expected) || len(postfixSpans) != len(tt.expected) {
+				t.Fatalf("ParseSpans() got %v with %d spans, expected %v", postfix, len(postfixSpans), tt.expected)
+			}
+
+			for i, token := range postfix {
+				if token != tt.expected[i] {
+					t.Errorf("ParseSpans() token[%d] = %s, expected %s", i, token, tt.expected[i])
+				}
+				span := postfixSpans[i]
+				if source := tt.expression[span.Start:span.End]; source != token {
+					t.Errorf("ParseSpans() span[%d] covers %q, token is %q", i, source, token)
+				}
+			}
+		})
+	}
+}
+
+// TestParseSpansLengthMismatch tests that spans must match tokens one to one
+func TestParseSpansLengthMismatch(t *testing.T) {
+	_, _, err := ParseSpans([]string{"2", "+", "3"}, []Span{{0, 1}})
+	if err == nil {
+		t.Errorf("ParseSpans() expected error, got nil")
+	}
+}
+
+// TestEvaluateTokenError tests locating an evaluation failure in the source expression
+func TestEvaluateTokenError(t *testing.T) {
+	expression := "1 / 2 + 3 / (4 - 4)"
+
+	tokens, spans, err := ScanSpans(expression)
+	if err != nil {
+		t.Fatalf("ScanSpans() unexpected error: %v", err)
+	}
+	postfix, postfixSpans, err := ParseSpans(tokens, spans)
+	if err != nil {
+		t.Fatalf("ParseSpans() unexpected error: %v", err)
+	}
+
+	_, err = Evaluate(postfix)
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) {
+		t.Fatalf("Evaluate() error = %v, expected *TokenError", err)
+	}
+	if err.Error() != "division by zero" {
+		t.Errorf("Evaluate() error = %q, expected %q", err, "division by zero")
+	}
+
+	// The second '/' is the one that fails
+	span := postfixSpans[tokenErr.Index]
+	if span != (Span{Start: 10, End: 11}) {
+		t.Errorf("failing token span = %v, expected {10 11}", span)
+	}
+}
@@ -0,0 +1,77 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+// WithStatsFunctions makes a pack of statistics functions over argument lists
+// available to expressions, for formulas such as "mean(a, b, c) / median(a, b, c)":
+//
+//   - mean(x...): the arithmetic mean
+//   - median(x...): the middle value, or the mean of the two middle values
+//   - variance(x...): the sample variance, dividing by n - 1
+//   - stddev(x...): the sample standard deviation
+//   - percentile(p, x...): the p-th percentile, 0 <= p <= 100, interpolating
+//     linearly between the closest ranks like a spreadsheet's PERCENTILE.INC
+//
+// mean and median take at least one value; variance and stddev take at least
+// two; percentile takes p and at least one value.
+func WithStatsFunctions() Option {
+	return withFunctions(statsFunctions)
+}
+
+// statsFunctions is the pack registered by WithStatsFunctions.
+var statsFunctions = map[string]function{
+	"mean":       {arity: 1, variadic: true, call: mean},
+	"median":     {arity: 1, variadic: true, call: median},
+	"variance":   {arity: 2, variadic: true, call: variance},
+	"stddev":     {arity: 2, variadic: true, call: stddev},
+	"percentile": {arity: 2, variadic: true, call: percentile},
+}
+
+func mean(values ...float64) (float64, error) {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values)), nil
+}
+
+func median(values ...float64) (float64, error) {
+	return percentile(append([]float64{50}, values...)...)
+}
+
+func variance(values ...float64) (float64, error) {
+	m, _ := mean(values...)
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - m) * (v - m)
+	}
+	return squares / float64(len(values)-1), nil
+}
+
+func stddev(values ...float64) (float64, error) {
+	v, _ := variance(values...)
+	return math.Sqrt(v), nil
+}
+
+func percentile(args ...float64) (float64, error) {
+	p := args[0]
+	if !(p >= 0 && p <= 100) {
+		return 0, errors.New("percentile must be between 0 and 100")
+	}
+
+	// Sort a copy; the arguments belong to the caller
+	sorted := slices.Clone(args[1:])
+	slices.Sort(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower == len(sorted)-1 {
+		return sorted[lower], nil
+	}
+	fraction := rank - float64(lower)
+	return sorted[lower] + fraction*(sorted[lower+1]-sorted[lower]), nil
+}
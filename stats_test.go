@@ -0,0 +1,64 @@
+package shuntingyard
+
+import (
+	"math"
+	"testing"
+)
+
+// TestStatsFunctions tests the statistics function pack through the full pipeline
+func TestStatsFunctions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "mean", expression: "mean(1, 2, 3, 4)", expected: 2.5},
+		{name: "mean of one", expression: "mean(7)", expected: 7},
+		{name: "median of odd count", expression: "median(9, 1, 5)", expected: 5},
+		{name: "median of even count", expression: "median(4, 1, 3, 2)", expected: 2.5},
+		{name: "sample variance", expression: "variance(2, 4, 4, 4, 5, 5, 7, 9)", expected: 32.0 / 7},
+		{name: "sample standard deviation", expression: "stddev(2, 4, 4, 4, 5, 5, 7, 9)", expected: math.Sqrt(32.0 / 7)},
+		{name: "percentile interpolates", expression: "percentile(25, 1, 2, 3, 4, 5)", expected: 2},
+		{name: "percentile between ranks", expression: "percentile(90, 10, 20, 30, 40)", expected: 37},
+		{name: "minimum percentile", expression: "percentile(0, 3, 1, 2)", expected: 1},
+		{name: "maximum percentile", expression: "percentile(100, 3, 1, 2)", expected: 3},
+		{name: "percentile of one value", expression: "percentile(40, 8)", expected: 8},
+		{name: "in a formula", expression: "(mean(90, 100, 110) - median(80, 90, 130)) / 10", expected: 1},
+
+		// Error cases
+		{name: "mean of nothing", expression: "mean()", wantErr: true},
+		{name: "variance of one value", expression: "variance(3)", wantErr: true},
+		{name: "percentile out of range", expression: "percentile(101, 1, 2)", wantErr: true},
+		{name: "percentile without values", expression: "percentile(50)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithStatsFunctions())
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("eval() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestStatsFunctionsOptional tests that the pack is only available when requested
+func TestStatsFunctionsOptional(t *testing.T) {
+	if _, err := eval("mean(1, 2)"); err == nil {
+		t.Error("eval() without WithStatsFunctions expected error, got nil")
+	}
+}
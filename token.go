@@ -0,0 +1,137 @@
+package shuntingyard
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"strings"
+)
+
+// TokenKind classifies the tokens ScanTokens returns.
+type TokenKind int
+
+const (
+	// TokenNumber is a numeric literal, such as "2.5", "1e3" or "inf".
+	TokenNumber TokenKind = iota
+	// TokenName is the name of a variable or constant, such as "x" or
+	// "order.total".
+	TokenName
+	// TokenFunction is the name of a built-in or registered function.
+	TokenFunction
+	// TokenOperator is an operator, including the '?' and ':' of a
+	// conditional.
+	TokenOperator
+	// TokenLeftParen is an opening parenthesis.
+	TokenLeftParen
+	// TokenRightParen is a closing parenthesis.
+	TokenRightParen
+	// TokenComma separates function arguments.
+	TokenComma
+	// TokenAssign is the '=' of an assignment.
+	TokenAssign
+)
+
+// String returns the name of the kind, e.g. "number".
+func (k TokenKind) String() string {
+	switch k {
+	case TokenNumber:
+		return "number"
+	case TokenName:
+		return "name"
+	case TokenFunction:
+		return "function"
+	case TokenOperator:
+		return "operator"
+	case TokenLeftParen:
+		return "left parenthesis"
+	case TokenRightParen:
+		return "right parenthesis"
+	case TokenComma:
+		return "comma"
+	case TokenAssign:
+		return "assignment"
+	}
+	return "unknown"
+}
+
+// Token is a token of an infix expression with its kind and the byte offset
+// it starts at in the source. Value is the token as Scan returns it, so a
+// literal that Scan translates, such as "0xFF", has the value "255".
+type Token struct {
+	Kind  TokenKind
+	Value string
+	Pos   int
+}
+
+// ScanTokens tokenizes an expression like Scan, classifying every token and
+// locating it in the source, for error reporting and tooling such as syntax
+// highlighters. Names of functions, including those registered through opts,
+// are TokenFunction, and other names TokenName.
+//
+// Returns the tokens or the Scan error.
+func ScanTokens(expression string, opts ...Option) ([]Token, error) {
+	cfg := newConfig(opts)
+	values, spans, err := scan(expression, cfg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]Token, len(values))
+	for i, value := range values {
+		tokens[i] = Token{Kind: cfg.tokenKind(value), Value: value, Pos: spans[i].Start}
+	}
+	return tokens, nil
+}
+
+// Tokens returns an iterator over the tokens of an expression, classified and
+// located as by ScanTokens, for analysis tools that may stop early:
+//
+//	for token, err := range Tokens(expression) {
+//		if err != nil {
+//			return err
+//		}
+//		if token.Kind == TokenFunction {
+//			...
+//		}
+//	}
+//
+// The expression is scanned as the loop advances, a chunk at a time as by a
+// Scanner, so tokens past the point where the loop stops are never produced.
+// A Scan error ends the sequence, paired with a zero Token.
+func Tokens(expression string, opts ...Option) iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		s := NewScanner(strings.NewReader(expression), opts...)
+		for {
+			token, err := s.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(token, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// tokenKind classifies an infix token.
+func (c config) tokenKind(token string) TokenKind {
+	switch token {
+	case "(":
+		return TokenLeftParen
+	case ")":
+		return TokenRightParen
+	case ",":
+		return TokenComma
+	case "=":
+		return TokenAssign
+	}
+	switch {
+	case c.isGrammarToken(token):
+		return TokenOperator
+	case isNumberLiteral(token) || isFloatKeyword(token):
+		return TokenNumber
+	case c.isFunction(token):
+		return TokenFunction
+	}
+	return TokenName
+}
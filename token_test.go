@@ -0,0 +1,151 @@
+package shuntingyard
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScanTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   []Token
+	}{
+		{
+			name:       "arithmetic",
+			expression: "2 * (x + 1.5)",
+			expected: []Token{
+				{Kind: TokenNumber, Value: "2", Pos: 0},
+				{Kind: TokenOperator, Value: "*", Pos: 2},
+				{Kind: TokenLeftParen, Value: "(", Pos: 4},
+				{Kind: TokenName, Value: "x", Pos: 5},
+				{Kind: TokenOperator, Value: "+", Pos: 7},
+				{Kind: TokenNumber, Value: "1.5", Pos: 9},
+				{Kind: TokenRightParen, Value: ")", Pos: 12},
+			},
+		},
+		{
+			name:       "function call",
+			expression: "max(a, inf)",
+			expected: []Token{
+				{Kind: TokenFunction, Value: "max", Pos: 0},
+				{Kind: TokenLeftParen, Value: "(", Pos: 3},
+				{Kind: TokenName, Value: "a", Pos: 4},
+				{Kind: TokenComma, Value: ",", Pos: 5},
+				{Kind: TokenNumber, Value: "inf", Pos: 7},
+				{Kind: TokenRightParen, Value: ")", Pos: 10},
+			},
+		},
+		{
+			name:       "registered function",
+			expression: "double(1)",
+			opts:       []Option{WithFunction("double", 1, func(args ...float64) (float64, error) { return 2 * args[0], nil })},
+			expected: []Token{
+				{Kind: TokenFunction, Value: "double", Pos: 0},
+				{Kind: TokenLeftParen, Value: "(", Pos: 6},
+				{Kind: TokenNumber, Value: "1", Pos: 7},
+				{Kind: TokenRightParen, Value: ")", Pos: 8},
+			},
+		},
+		{
+			name:       "assignment and conditional",
+			expression: "y = x >= 0xFF ? −1 : pi",
+			expected: []Token{
+				{Kind: TokenName, Value: "y", Pos: 0},
+				{Kind: TokenAssign, Value: "=", Pos: 2},
+				{Kind: TokenName, Value: "x", Pos: 4},
+				{Kind: TokenOperator, Value: ">=", Pos: 6},
+				{Kind: TokenNumber, Value: "255", Pos: 9},
+				{Kind: TokenOperator, Value: "?", Pos: 14},
+				{Kind: TokenOperator, Value: "-", Pos: 16},
+				{Kind: TokenNumber, Value: "1", Pos: 19},
+				{Kind: TokenOperator, Value: ":", Pos: 21},
+				{Kind: TokenName, Value: "pi", Pos: 23},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := ScanTokens(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("ScanTokens() unexpected error: %v", err)
+			}
+			if !slices.Equal(tokens, tt.expected) {
+				t.Errorf("ScanTokens() = %v, expected %v", tokens, tt.expected)
+			}
+		})
+	}
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := ScanTokens("2 $ 3")
+		var charErr *InvalidCharError
+		if !errors.As(err, &charErr) {
+			t.Errorf("ScanTokens() error = %v, expected *InvalidCharError", err)
+		}
+	})
+}
+
+func TestTokenKindString(t *testing.T) {
+	if got := TokenLeftParen.String(); got != "left parenthesis" {
+		t.Errorf("TokenLeftParen.String() = %q, expected %q", got, "left parenthesis")
+	}
+	if got := TokenKind(99).String(); got != "unknown" {
+		t.Errorf("TokenKind(99).String() = %q, expected %q", got, "unknown")
+	}
+}
+
+func TestTokens(t *testing.T) {
+	t.Run("matches ScanTokens", func(t *testing.T) {
+		expression := "max(a, 2) * −b # total"
+		expected, err := ScanTokens(expression)
+		if err != nil {
+			t.Fatalf("ScanTokens() unexpected error: %v", err)
+		}
+		var tokens []Token
+		for token, err := range Tokens(expression) {
+			if err != nil {
+				t.Fatalf("Tokens() unexpected error: %v", err)
+			}
+			tokens = append(tokens, token)
+		}
+		if !slices.Equal(tokens, expected) {
+			t.Errorf("Tokens() = %v, expected %v", tokens, expected)
+		}
+	})
+
+	t.Run("stops early", func(t *testing.T) {
+		// The invalid character lies beyond the first chunk, so stopping at
+		// the first function never reaches it
+		expression := "sqrt(2) + " + strings.Repeat("1 + ", 5000) + "$"
+		for token, err := range Tokens(expression) {
+			if err != nil {
+				t.Fatalf("Tokens() unexpected error: %v", err)
+			}
+			if token.Kind == TokenFunction {
+				break
+			}
+		}
+	})
+
+	t.Run("error ends the sequence", func(t *testing.T) {
+		var errs []error
+		count := 0
+		for _, err := range Tokens("1 + $ 2") {
+			count++
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		var charErr *InvalidCharError
+		if len(errs) != 1 || !errors.As(errs[0], &charErr) || charErr.Pos != 4 {
+			t.Errorf("Tokens() errors = %v, expected one *InvalidCharError at 4", errs)
+		}
+		if count != 1 {
+			t.Errorf("Tokens() yielded %d pairs, expected only the error", count)
+		}
+	})
+}
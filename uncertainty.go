@@ -0,0 +1,182 @@
+package shuntingyard
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Uncertain is a value with its standard uncertainty, such as a measured
+// 9.81 ± 0.02.
+type Uncertain struct {
+	Value       float64
+	Uncertainty float64
+}
+
+// String renders u as "9.81 ± 0.02".
+func (u Uncertain) String() string {
+	return strconv.FormatFloat(u.Value, 'g', -1, 64) + " ± " + strconv.FormatFloat(u.Uncertainty, 'g', -1, 64)
+}
+
+// EvaluateUncertain computes the result of a postfix (RPN) expression whose
+// literals may carry an uncertainty, written "9.81±0.02" or "9.81 ± 0.02",
+// propagating it to the result with the first-order rules for independent
+// errors: for "+" and "-" the uncertainties add in quadrature, for "*" and
+// "/" the relative ones do, and in general each operand's uncertainty is
+// scaled by the partial derivative of the operation. Functions are
+// differentiated numerically, so registered functions propagate uncertainty
+// too. Literals without an uncertainty, variables and constants are exact.
+//
+// Every literal is an independent measurement, so a quantity used twice
+// should be given once as a variable or computed by a single operation.
+// Comparisons, logical and bitwise operators act on the values and yield
+// exact results, and a conditional chooses its branch by value.
+//
+// Returns the result or an error as for Evaluate.
+func EvaluateUncertain(postfixTokens []string, opts ...Option) (Uncertain, error) {
+	return evaluateWith(postfixTokens, Arithmetic[Uncertain](uncertainArithmetic{}), newConfig(opts))
+}
+
+// parseUncertain reads a number literal with an optional uncertainty.
+func parseUncertain(token string) (value, uncertainty float64, err error) {
+	number, deviation, uncertain := strings.Cut(token, "±")
+	value, err = strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || !uncertain {
+		return value, 0, err
+	}
+	uncertainty, err = strconv.ParseFloat(strings.TrimSpace(deviation), 64)
+	return value, uncertainty, err
+}
+
+// isNumberLiteral reports whether an infix token is a number as Scan reads
+// one from digits and dots.
+func isNumberLiteral(token string) bool {
+	return token != "" && ('0' <= token[0] && token[0] <= '9' || token[0] == '.')
+}
+
+// uncertainArithmetic implements EvaluateUncertain's error propagation.
+type uncertainArithmetic struct{}
+
+func (uncertainArithmetic) ParseLiteral(token string) (Uncertain, error) {
+	value, uncertainty, err := parseUncertain(token)
+	if err != nil {
+		return Uncertain{}, ErrInvalidNumber
+	}
+	return Uncertain{Value: value, Uncertainty: uncertainty}, nil
+}
+
+func (u uncertainArithmetic) Add(a, b Uncertain) (Uncertain, error) { return u.Binary("+", a, b) }
+func (u uncertainArithmetic) Sub(a, b Uncertain) (Uncertain, error) { return u.Binary("-", a, b) }
+func (u uncertainArithmetic) Mul(a, b Uncertain) (Uncertain, error) { return u.Binary("*", a, b) }
+func (u uncertainArithmetic) Div(a, b Uncertain) (Uncertain, error) { return u.Binary("/", a, b) }
+
+func (uncertainArithmetic) Binary(operator string, a, b Uncertain) (Uncertain, error) {
+	value, err := applyBinary(operator, a.Value, b.Value)
+	if err != nil {
+		return Uncertain{}, err
+	}
+
+	// The partial derivatives of the operation by a and by b
+	var da, db float64
+	switch operator {
+	case "+":
+		da, db = 1, 1
+	case "-":
+		da, db = 1, -1
+	case "*":
+		da, db = b.Value, a.Value
+	case "/":
+		da, db = 1/b.Value, -a.Value/(b.Value*b.Value)
+	case "%":
+		da, db = 1, -math.Trunc(a.Value/b.Value)
+	case "^":
+		da = b.Value * math.Pow(a.Value, b.Value-1)
+		if b.Uncertainty != 0 {
+			db = value * math.Log(a.Value)
+		}
+	default:
+		// Comparisons, logical and bitwise results are exact
+		return Uncertain{Value: value}, nil
+	}
+	return Uncertain{Value: value, Uncertainty: quadrature(scaled(da, a.Uncertainty), scaled(db, b.Uncertainty))}, nil
+}
+
+func (uncertainArithmetic) Unary(operator string, a Uncertain) (Uncertain, error) {
+	value, err := applyUnary(operator, a.Value)
+	if err != nil {
+		return Uncertain{}, err
+	}
+	if operator == negate {
+		return Uncertain{Value: value, Uncertainty: a.Uncertainty}, nil
+	}
+	return Uncertain{Value: value}, nil
+}
+
+func (uncertainArithmetic) Truth(a Uncertain) bool { return a.Value != 0 }
+
+func (uncertainArithmetic) Variable(_ string, value float64) (Uncertain, error) {
+	return Uncertain{Value: value}, nil
+}
+
+// Round rounds the value, leaving its uncertainty as computed.
+func (uncertainArithmetic) Round(a Uncertain, scale int, mode RoundingMode) (Uncertain, error) {
+	return Uncertain{Value: roundFloat(a.Value, scale, mode, 64), Uncertainty: a.Uncertainty}, nil
+}
+
+// invoke calls the function on the values and scales each argument's
+// uncertainty by the function's central-difference derivative in it.
+func (uncertainArithmetic) invoke(fn function, token string, args []Uncertain) (Uncertain, error) {
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	value, err := fn.invoke(token, values)
+	if err != nil {
+		return Uncertain{}, err
+	}
+
+	var terms []float64
+	for i, arg := range args {
+		if arg.Uncertainty == 0 {
+			continue
+		}
+		h := derivativeStep * math.Max(math.Abs(arg.Value), 1)
+		values[i] = arg.Value + h
+		above, err := fn.invoke(token, values)
+		if err != nil {
+			return Uncertain{}, err
+		}
+		values[i] = arg.Value - h
+		below, err := fn.invoke(token, values)
+		if err != nil {
+			return Uncertain{}, err
+		}
+		values[i] = arg.Value
+		terms = append(terms, (above-below)/(2*h)*arg.Uncertainty)
+	}
+	return Uncertain{Value: value, Uncertainty: quadrature(terms...)}, nil
+}
+
+// derivativeStep is the relative step of numerical differentiation, near the
+// cube root of the float64 epsilon, which balances truncation and rounding
+// error for central differences.
+const derivativeStep = 6e-6
+
+// scaled returns the contribution of an uncertainty through a partial
+// derivative; an exact operand contributes nothing, even where the derivative
+// is infinite.
+func scaled(derivative, uncertainty float64) float64 {
+	if uncertainty == 0 {
+		return 0
+	}
+	return derivative * uncertainty
+}
+
+// quadrature returns the square root of the sum of the squares of terms.
+func quadrature(terms ...float64) float64 {
+	sum := 0.0
+	for _, term := range terms {
+		sum = math.Hypot(sum, term)
+	}
+	return sum
+}
@@ -0,0 +1,115 @@
+package shuntingyard
+
+import "testing"
+
+// TestEvaluateUncertain tests uncertainty propagation through the full pipeline
+func TestEvaluateUncertain(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		value       float64
+		uncertainty float64
+		wantErr     bool
+	}{
+		{name: "exact literals", expression: "2 + 3", value: 5},
+		{name: "literal", expression: "9.81±0.02", value: 9.81, uncertainty: 0.02},
+		{name: "spaced literal", expression: "9.81 ± 0.02", value: 9.81, uncertainty: 0.02},
+		{name: "sum adds in quadrature", expression: "10±3 + 20±4", value: 30, uncertainty: 5},
+		{name: "difference adds in quadrature", expression: "20±4 - 10±3", value: 10, uncertainty: 5},
+		{name: "exact scale factor", expression: "2 * 9.81±0.02", value: 19.62, uncertainty: 0.04},
+		{name: "product adds relative uncertainties", expression: "10±0.3 * 20±0.8", value: 200, uncertainty: 10},
+		{name: "quotient adds relative uncertainties", expression: "20±0.8 / 10±0.3", value: 2, uncertainty: 0.1},
+		{name: "power", expression: "(3±0.1) ^ 2", value: 9, uncertainty: 0.6},
+		{name: "negation", expression: "-(5±0.5)", value: -5, uncertainty: 0.5},
+		{name: "function", expression: "sqrt(16±1)", value: 4, uncertainty: 0.125},
+		{name: "two-argument function", expression: "hypot(3±0.3, 4±0.4)", value: 5, uncertainty: 0.3672},
+		{name: "comparison is exact", expression: "9.81±0.02 > 9", value: 1},
+		{name: "conditional chooses by value", expression: "1 ? 2±0.5 : 3", value: 2, uncertainty: 0.5},
+		{name: "pendulum period", expression: "2 * pi * sqrt(1.5±0.01 / 9.81±0.02)", value: 2.4569, uncertainty: 0.0086},
+
+		// Error cases
+		{name: "division by zero", expression: "1±0.1 / 0", wantErr: true},
+		{name: "invalid uncertain literal", expression: "1±2±3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatalf("Parse() unexpected error: %v", err)
+				}
+				return
+			}
+
+			result, err := EvaluateUncertain(postfix)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateUncertain() expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateUncertain() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result.Value, tt.value, 0.0001) || !almostEqual(result.Uncertainty, tt.uncertainty, 0.0001) {
+				t.Errorf("EvaluateUncertain(%q) = %s, expected %v ± %v", tt.expression, result, tt.value, tt.uncertainty)
+			}
+		})
+	}
+}
+
+// TestScanUncertain tests how Scan joins uncertainties to their values
+func TestScanUncertain(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   []string
+		wantErr    bool
+	}{
+		{expression: "9.81±0.02", expected: []string{"9.81±0.02"}},
+		{expression: "9.81 ± 0.02 * t", expected: []string{"9.81 ± 0.02", "*", "t"}},
+		{expression: "(1±2)", expected: []string{"(", "1±2", ")"}},
+		{expression: "x ± 1", wantErr: true},
+		{expression: "1 ±", wantErr: true},
+		{expression: "± 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			tokens, err := Scan(tt.expression)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Scan() expected error, got %q", tokens)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			if len(tokens) != len(tt.expected) {
+				t.Fatalf("Scan() = %q, expected %q", tokens, tt.expected)
+			}
+			for i := range tokens {
+				if tokens[i] != tt.expected[i] {
+					t.Errorf("Scan() = %q, expected %q", tokens, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+// TestUncertainString tests rendering an uncertain value
+func TestUncertainString(t *testing.T) {
+	u := Uncertain{Value: 9.81, Uncertainty: 0.02}
+	if u.String() != "9.81 ± 0.02" {
+		t.Errorf("String() = %q, expected %q", u.String(), "9.81 ± 0.02")
+	}
+}
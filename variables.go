@@ -0,0 +1,168 @@
+package shuntingyard
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// EvaluateWithVars evaluates a postfix expression like Evaluate, resolving
+// names in it against vars, so that a formula such as "x * 2 + y" can be
+// parsed once and evaluated against different values:
+//
+//	tokens, _ := Scan("x * 2 + y")
+//	postfix, _ := Parse(tokens)
+//	result, err := EvaluateWithVars(postfix, map[string]float64{"x": 3, "y": 1})
+//
+// A name missing from vars fails the evaluation with a *TokenError. vars is
+// only read, so one map can serve concurrent evaluations.
+//
+// The names "inf", "infinity" and "nan", in any case, are read as float
+// literals rather than variables, so a vars key spelled as one of them fails
+// with an error wrapping ErrReservedName instead of being silently ignored.
+func EvaluateWithVars(postfixTokens []string, vars map[string]float64, opts ...Option) (float64, error) {
+	if err := checkVarNames(vars); err != nil {
+		return 0, err
+	}
+	cfg := newConfig(opts)
+	cfg.vars = vars
+	return evaluate(postfixTokens, cfg)
+}
+
+// ErrUndefinedVariable is the error for a variable that has no value. The
+// *TokenError that Evaluate returns wraps it, naming the variable.
+var ErrUndefinedVariable = errors.New("undefined variable")
+
+// ErrReservedName is wrapped by the error for a variable bound to a name that
+// expressions read as a number, such as "nan" or "Inf".
+var ErrReservedName = errors.New("reserved name")
+
+// checkVarNames reports an error wrapping ErrReservedName for a key of vars
+// that no expression can refer to because it is a float keyword.
+func checkVarNames(vars map[string]float64) error {
+	for name := range vars {
+		if isFloatKeyword(name) {
+			return fmt.Errorf("variable '%s': %w", name, ErrReservedName)
+		}
+	}
+	return nil
+}
+
+// VariableResolver supplies the values of variables during evaluation, for
+// values fetched lazily from a database, a config store, or a request context
+// rather than collected into a map up front. Resolve is called each time a
+// variable is evaluated, so an expensive resolver should cache its results.
+// It should return an error wrapping ErrUndefinedVariable for a name it
+// doesn't know, so that constants of that name still resolve; any other error
+// fails the evaluation.
+type VariableResolver interface {
+	Resolve(name string) (float64, error)
+}
+
+// VariableResolverFunc adapts a function to the VariableResolver interface.
+type VariableResolverFunc func(name string) (float64, error)
+
+// Resolve calls f(name).
+func (f VariableResolverFunc) Resolve(name string) (float64, error) {
+	return f(name)
+}
+
+// WithVariableResolver makes Evaluate and the other evaluators resolve
+// variables through r. Values given to EvaluateWithVars take precedence over
+// r, and r takes precedence over constants.
+func WithVariableResolver(r VariableResolver) Option {
+	return func(c *config) {
+		c.resolver = r
+	}
+}
+
+// variable resolves a postfix operand that isn't a number as a variable or,
+// failing that, a constant.
+func (c config) variable(token string, index int) (float64, error) {
+	if !isVariableName(token) {
+		return 0, c.invalidNumber(token, index)
+	}
+	if value, ok := c.vars[token]; ok {
+		return value, nil
+	}
+	if c.resolver != nil {
+		value, err := c.resolver.Resolve(token)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrUndefinedVariable) {
+			return 0, fmt.Errorf("variable '%s': %w", token, err)
+		}
+	}
+	if value, ok := c.constant(token); ok {
+		return value, nil
+	}
+	if c.missing != nil {
+		value, err := c.missing(token)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrUndefinedVariable) {
+			return 0, fmt.Errorf("variable '%s': %w", token, err)
+		}
+	}
+	return 0, fmt.Errorf("%w '%s'", ErrUndefinedVariable, token)
+}
+
+// WithMissingVariableDefault makes a variable without a value evaluate to
+// value, such as 0, instead of failing the evaluation. By default a missing
+// variable is an error wrapping ErrUndefinedVariable. A variable is missing
+// once EvaluateWithVars, any WithVariableResolver, and the constants have no
+// value for it, so PartialEvaluate too folds it to value.
+func WithMissingVariableDefault(value float64) Option {
+	return WithMissingVariableFallback(func(string) (float64, error) {
+		return value, nil
+	})
+}
+
+// WithMissingVariableFallback makes a variable without a value evaluate to
+// what fn returns for its name, for callers that log, count, or look up
+// missing variables elsewhere. An error from fn fails the evaluation; one
+// wrapping ErrUndefinedVariable leaves the variable missing, as by default.
+func WithMissingVariableFallback(fn func(name string) (float64, error)) Option {
+	return func(c *config) {
+		c.missing = fn
+	}
+}
+
+// Variables returns the names of the variables an infix expression refers to,
+// sorted and without duplicates, so that callers know which values to fetch
+// before evaluating it. Names of functions and constants, including those
+// registered through opts, are not variables.
+//
+// Returns the Scan or Parse error for an invalid expression.
+func Variables(expression string, opts ...Option) ([]string, error) {
+	cfg := newConfig(opts)
+	cfg.source = expression
+
+	tokens, spans, err := scan(expression, cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := parse(tokens, spans, cfg); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, token := range tokens {
+		if cfg.isGrammarToken(token) || !isVariableName(token) || cfg.isFunction(token) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(token, 64); err == nil {
+			continue
+		}
+		if _, ok := cfg.constant(token); ok {
+			continue
+		}
+		names = append(names, token)
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
@@ -0,0 +1,341 @@
+package shuntingyard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// TestEvaluateWithVars tests evaluation of postfix expressions against variable values
+func TestEvaluateWithVars(t *testing.T) {
+	vars := map[string]float64{"x": 3, "y": 1, "rate_2": 0.5}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "templated formula", expression: "x * 2 + y", expected: 7},
+		{name: "single variable", expression: "x", expected: 3},
+		{name: "name with digits and underscore", expression: "100 * rate_2", expected: 50},
+		{name: "negated variable", expression: "-x ^ 2", expected: -9},
+		{name: "variable as argument", expression: "max(x, y * 10)", expected: 10},
+		{name: "variable in conditional", expression: "x > y ? x : y", expected: 3},
+
+		// Error cases
+		{name: "undefined variable", expression: "x + z", wantErr: true},
+		{name: "name is case sensitive", expression: "X", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Scan(tt.expression)
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			postfix, err := Parse(tokens)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			result, err := EvaluateWithVars(postfix, vars)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EvaluateWithVars() expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("EvaluateWithVars() unexpected error: %v", err)
+				return
+			}
+
+			if !almostEqual(result, tt.expected, 0.0000001) {
+				t.Errorf("EvaluateWithVars(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluateUndefinedVariable tests the error for a variable without a value
+func TestEvaluateUndefinedVariable(t *testing.T) {
+	_, err := Evaluate([]string{"2", "x", "*"})
+
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) {
+		t.Fatalf("Evaluate() error = %v, expected *TokenError", err)
+	}
+	if tokenErr.Index != 1 {
+		t.Errorf("TokenError.Index = %d, expected 1", tokenErr.Index)
+	}
+
+	if !errors.Is(err, ErrUndefinedVariable) {
+		t.Errorf("Evaluate() error = %v, expected ErrUndefinedVariable", err)
+	}
+
+	expected := "undefined variable 'x'"
+	if tokenErr.Err.Error() != expected {
+		t.Errorf("Evaluate() error = %q, expected %q", tokenErr.Err.Error(), expected)
+	}
+}
+
+// TestReservedVariableNames tests that binding a float keyword is an error
+// rather than a value no expression can read
+func TestReservedVariableNames(t *testing.T) {
+	expr, err := Compile("x + 1")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	fn, err := CompileFunc("x + 1")
+	if err != nil {
+		t.Fatalf("CompileFunc() unexpected error: %v", err)
+	}
+
+	evaluators := map[string]func(vars map[string]float64) (float64, error){
+		"EvaluateWithVars": func(vars map[string]float64) (float64, error) {
+			return EvaluateWithVars([]string{"x", "1", "+"}, vars)
+		},
+		"Eval": expr.Eval,
+		"EvalContext": func(vars map[string]float64) (float64, error) {
+			return expr.EvalContext(context.Background(), vars)
+		},
+		"CompileFunc": fn,
+	}
+	for evaluator, eval := range evaluators {
+		for _, name := range []string{"nan", "NaN", "inf", "Infinity"} {
+			t.Run(evaluator+"/"+name, func(t *testing.T) {
+				_, err := eval(map[string]float64{"x": 1, name: 2})
+				if !errors.Is(err, ErrReservedName) {
+					t.Fatalf("%s() error = %v, expected ErrReservedName", evaluator, err)
+				}
+				expected := fmt.Sprintf("variable '%s': reserved name", name)
+				if err.Error() != expected {
+					t.Errorf("%s() error = %q, expected %q", evaluator, err.Error(), expected)
+				}
+			})
+		}
+
+		// Names that merely contain a keyword are ordinary variables
+		result, err := eval(map[string]float64{"x": 1, "nano": 2, "info": 3})
+		if err != nil || result != 2 {
+			t.Errorf("%s() = %v, %v, expected 2", evaluator, result, err)
+		}
+	}
+
+	session := NewSession()
+	if _, err := session.Eval("nan = 3"); err == nil {
+		t.Error("Session.Eval(\"nan = 3\") expected error")
+	}
+}
+
+// TestEvaluateWithVarsReuse tests that parsed output can be evaluated against different values
+func TestEvaluateWithVarsReuse(t *testing.T) {
+	postfix := []string{"price", "qty", "*"}
+
+	for _, tt := range []struct {
+		price, qty, expected float64
+	}{
+		{price: 2.5, qty: 4, expected: 10},
+		{price: 10, qty: 0, expected: 0},
+	} {
+		result, err := EvaluateWithVars(postfix, map[string]float64{"price": tt.price, "qty": tt.qty})
+		if err != nil {
+			t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("EvaluateWithVars() = %v, expected %v", result, tt.expected)
+		}
+	}
+}
+
+// TestVariableResolver tests resolving variables lazily during evaluation
+func TestVariableResolver(t *testing.T) {
+	errUnavailable := errors.New("store unavailable")
+
+	var calls []string
+	resolver := VariableResolverFunc(func(name string) (float64, error) {
+		calls = append(calls, name)
+		switch name {
+		case "price":
+			return 20, nil
+		case "e":
+			return 1, nil
+		case "broken":
+			return 0, errUnavailable
+		}
+		return 0, fmt.Errorf("no column %q: %w", name, ErrUndefinedVariable)
+	})
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   float64
+		wantErr    error
+	}{
+		{name: "resolved variable", expression: "price * 1.25", expected: 25},
+		{name: "resolver shadows constant", expression: "e + 1", expected: 2},
+		{name: "unknown name falls back to constant", expression: "round(pi, 2)", expected: 3.14},
+		{name: "unknown name", expression: "price + qty", wantErr: ErrUndefinedVariable},
+		{name: "resolver failure", expression: "1 + broken", wantErr: errUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, WithVariableResolver(resolver))
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("eval() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+
+	// Variables are fetched as they are evaluated, not before
+	calls = nil
+	if _, err := eval("price + broken + price", WithVariableResolver(resolver)); err == nil {
+		t.Fatal("eval() expected error, got nil")
+	}
+	if len(calls) != 2 {
+		t.Errorf("resolver called for %v, expected [price broken]", calls)
+	}
+}
+
+// TestVariableResolverWithVars tests that values given to EvaluateWithVars take precedence
+func TestVariableResolverWithVars(t *testing.T) {
+	resolver := VariableResolverFunc(func(name string) (float64, error) {
+		return 100, nil
+	})
+
+	result, err := EvaluateWithVars([]string{"x", "y", "+"}, map[string]float64{"x": 1}, WithVariableResolver(resolver))
+	if err != nil {
+		t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+	}
+	if result != 101 {
+		t.Errorf("EvaluateWithVars() = %v, expected 101", result)
+	}
+}
+
+// TestVariables tests extracting the variable names an expression refers to
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   []string
+		wantErr    bool
+	}{
+		{name: "sorted and unique", expression: "y * x + x", expected: []string{"x", "y"}},
+		{name: "no variables", expression: "2 + 3", expected: nil},
+		{name: "dotted paths", expression: "order.total > user.limit", expected: []string{"order.total", "user.limit"}},
+		{name: "function names excluded", expression: "max(a, sqrt(b))", expected: []string{"a", "b"}},
+		{name: "constants excluded", expression: "2 * pi * r", expected: []string{"r"}},
+		{name: "registered constants excluded", expression: "0.5 * g * t ^ 2", opts: []Option{WithConstant("g", 9.81)}, expected: []string{"t"}},
+		{name: "special literals excluded", expression: "x < inf", expected: []string{"x"}},
+		{name: "conditional", expression: "ok ? a : b", expected: []string{"a", "b", "ok"}},
+
+		// Error cases
+		{name: "invalid expression", expression: "x +", wantErr: true},
+		{name: "invalid character", expression: "x $ y", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := Variables(tt.expression, tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Variables() expected error, got %v", names)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Variables() unexpected error: %v", err)
+				return
+			}
+
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("Variables(%q) = %q, expected %q", tt.expression, names, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMissingVariablePolicy tests the strict, default and fallback policies for missing variables
+func TestMissingVariablePolicy(t *testing.T) {
+	errNotAllowed := errors.New("not allowed")
+	var missing []string
+	fallback := WithMissingVariableFallback(func(name string) (float64, error) {
+		missing = append(missing, name)
+		switch name {
+		case "secret":
+			return 0, errNotAllowed
+		case "strict":
+			return 0, ErrUndefinedVariable
+		}
+		return 1, nil
+	})
+
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		expected   float64
+		wantErr    error
+	}{
+		{name: "strict by default", expression: "x + 1", wantErr: ErrUndefinedVariable},
+		{name: "default value", expression: "x + 1", opts: []Option{WithMissingVariableDefault(0)}, expected: 1},
+		{name: "default value for each", expression: "x * y + 2", opts: []Option{WithMissingVariableDefault(3)}, expected: 11},
+		{name: "default value after constants", expression: "pi > x", opts: []Option{WithMissingVariableDefault(0)}, expected: 1},
+		{name: "fallback", expression: "x + y", opts: []Option{fallback}, expected: 2},
+		{name: "fallback error", expression: "secret", opts: []Option{fallback}, wantErr: errNotAllowed},
+		{name: "fallback keeps variable missing", expression: "strict", opts: []Option{fallback}, wantErr: ErrUndefinedVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval(tt.expression, tt.opts...)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("eval() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("eval() unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("eval(%q) = %v, expected %v", tt.expression, result, tt.expected)
+			}
+		})
+	}
+
+	// Variables that have values never reach the fallback
+	missing = nil
+	if _, err := EvaluateWithVars([]string{"x", "y", "+"}, map[string]float64{"x": 1}, fallback); err != nil {
+		t.Fatalf("EvaluateWithVars() unexpected error: %v", err)
+	}
+	if !slices.Equal(missing, []string{"y"}) {
+		t.Errorf("fallback called for %q, expected [y]", missing)
+	}
+}
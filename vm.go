@@ -0,0 +1,392 @@
+package shuntingyard
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// opcode is an instruction of a compiled program.
+type opcode uint8
+
+const (
+	// opConst pushes consts[arg]
+	opConst opcode = iota
+	// opVar pushes the value of the variable names[arg]
+	opVar
+	opAdd
+	opSub
+	opMul
+	opDiv
+	// opBinary applies the built-in binary operator names[arg]
+	opBinary
+	opNeg
+	// opUnary applies the prefix operator names[arg], "!" or "~"
+	opUnary
+	// opCall calls calls[arg], a function or registered operator, with the
+	// operands on top of the stack
+	opCall
+	// opCond drops the condition beneath the branch of a conditional taken
+	opCond
+	// opBranch jumps to arg, the else branch of a conditional, when the
+	// condition on top of the stack is false
+	opBranch
+	// opJump jumps to arg, past the else branch of a conditional
+	opJump
+	// opAnd and opOr jump to arg, past a "&&" or "||", with its result in
+	// place of the left operand on top of the stack when that decides it
+	opAnd
+	opOr
+	// opMemo starts a repeated subexpression: once its value is cached in
+	// slot, it pushes that and jumps to arg, past the subexpression
+	opMemo
+	// opStore caches the value on top of the stack in slot
+	opStore
+	// opLoad pushes the value cached in slot, for a repeated subexpression
+	// whose first occurrence has always been computed before
+	opLoad
+)
+
+// instruction is an opcode with its operand, and index the postfix token it
+// was compiled from, which errors report. slot is the cache slot of opMemo
+// and opStore.
+type instruction struct {
+	op    opcode
+	arg   int
+	index int
+	slot  int
+}
+
+// compiledCall is a call resolved at compile time.
+type compiledCall struct {
+	fn    function
+	token string
+	argc  int
+}
+
+// program is a postfix expression compiled for float64 evaluation: its
+// constants parsed and its functions resolved once, so that running it
+// switches on opcodes rather than on token strings.
+type program struct {
+	code   []instruction
+	consts []float64
+	names  []string
+	calls  []compiledCall
+	// depth is the most operands the program holds on the stack at once
+	depth int
+	// shared holds, for the cache slot of each repeated subexpression, the
+	// index of the last token of its first occurrence
+	shared []int
+}
+
+// compileProgram compiles postfix tokens for the float64 arithmetic of
+// Evaluate under cfg, origin giving the index each token had before folding,
+// if it was folded (see foldConstants). A token Evaluate would reject
+// whatever the variables, such as an invalid number or a call with the wrong
+// number of arguments, fails compilation with the *TokenError Evaluate
+// returns for it.
+//
+// A subexpression that occurs more than once is computed once per run, its
+// value cached for the other occurrences. Where its first occurrence is
+// always evaluated, the others are replaced by a load of the value; where it
+// may be skipped, as in a branch of a conditional, each occurrence computes
+// it unless an earlier one has.
+func compileProgram(postfixTokens []string, origin []int, cfg config) (*program, error) {
+	if len(postfixTokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	// source maps a postfix index to that of the token it stands for in the
+	// expression as parsed, which errors report
+	source := func(i int) int {
+		if origin == nil {
+			return i
+		}
+		return origin[i]
+	}
+
+	p := &program{code: make([]instruction, 0, len(postfixTokens))}
+	depth := 0
+	emit := func(op opcode, arg, index, pops int) error {
+		if depth < pops {
+			return &TokenError{Index: source(index), Err: fmt.Errorf("invalid expression: insufficient operands for operator '%s'", postfixTokens[index])}
+		}
+		depth += 1 - pops
+		p.depth = max(p.depth, depth)
+		p.code = append(p.code, instruction{op: op, arg: arg, index: source(index)})
+		return nil
+	}
+	name := func(s string) int {
+		p.names = append(p.names, s)
+		return len(p.names) - 1
+	}
+	call := func(fn function, token string, argc int) int {
+		p.calls = append(p.calls, compiledCall{fn: fn, token: token, argc: argc})
+		return len(p.calls) - 1
+	}
+
+	// compileToken emits the instruction of the postfix token i
+	compileToken := func(i int, token string) error {
+		switch {
+		case cfg.isBinaryOperator(token):
+			if custom, ok := cfg.operators[token]; ok {
+				return emit(opCall, call(custom.fn, callToken(token, 2), 2), i, 2)
+			}
+			switch token {
+			case "+":
+				return emit(opAdd, 0, i, 2)
+			case "-":
+				return emit(opSub, 0, i, 2)
+			case "*":
+				return emit(opMul, 0, i, 2)
+			case "/":
+				return emit(opDiv, 0, i, 2)
+			}
+			return emit(opBinary, name(token), i, 2)
+
+		case token == negate:
+			return emit(opNeg, 0, i, 1)
+
+		case isUnaryOperator(token):
+			return emit(opUnary, name(token), i, 1)
+
+		case cfg.isCall(token):
+			fn, argc, err := cfg.checkCall(token, depth)
+			if err != nil {
+				return &TokenError{Index: source(i), Err: err}
+			}
+			return emit(opCall, call(fn, token, argc), i, argc)
+
+		case token == ternary:
+			return emit(opCond, 0, i, 3)
+
+		case isVariableName(token) && !isFloatKeyword(token):
+			return emit(opVar, name(token), i, 0)
+		}
+
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return &TokenError{Index: source(i), Err: cfg.invalidNumber(token, source(i))}
+		}
+		p.consts = append(p.consts, value)
+		return emit(opConst, len(p.consts)-1, i, 0)
+	}
+
+	// Jumps are emitted before the instructions they lead to, and patched as
+	// those are reached: before[i] lists those to the first instruction of
+	// the postfix token i, or to the end of the program for
+	// len(postfixTokens), and after[i] those to the instruction after the
+	// token's own, ahead of the caching of the subexpression it ends
+	branches := cfg.branches(postfixTokens)
+	var before, after [][]int
+	if branches != nil {
+		before = make([][]int, len(postfixTokens)+1)
+		after = make([][]int, len(postfixTokens))
+	}
+	jump := func(op opcode, targets [][]int, target, index int) {
+		targets[target] = append(targets[target], len(p.code))
+		p.code = append(p.code, instruction{op: op, index: source(index)})
+	}
+	land := func(jumps []int) {
+		for _, pc := range jumps {
+			p.code[pc].arg = len(p.code)
+		}
+	}
+
+	shared := cfg.share(postfixTokens)
+	var memoPC []int
+	if shared != nil {
+		// memoPC holds the opMemo of the occurrence being compiled of each
+		// cached subexpression, to be patched with the end of it
+		memoPC = make([]int, len(shared.first))
+		p.shared = make([]int, len(shared.first))
+	}
+
+	for i := 0; i < len(postfixTokens); i++ {
+		if branches != nil {
+			land(before[i])
+		}
+
+		// A repeated subexpression whose value is known by now is loaded,
+		// its tokens skipped
+		loaded := false
+		if shared != nil {
+			for _, end := range shared.starts[i] {
+				slot := shared.roots[end]
+				p.shared[slot] = source(shared.first[slot])
+				switch {
+				case !shared.hoisted[slot]:
+					memoPC[slot] = len(p.code)
+					p.code = append(p.code, instruction{op: opMemo, index: source(i), slot: slot})
+					continue
+				case end == shared.first[slot]:
+					continue
+				}
+				if err := emit(opLoad, 0, end, 0); err != nil {
+					return nil, err
+				}
+				p.code[len(p.code)-1].slot = slot
+				i, loaded = end, true
+				break
+			}
+		}
+
+		if !loaded {
+			if err := compileToken(i, postfixTokens[i]); err != nil {
+				return nil, err
+			}
+			if branches != nil {
+				land(after[i])
+			}
+			if shared != nil && shared.roots[i] >= 0 {
+				slot := shared.roots[i]
+				p.code = append(p.code, instruction{op: opStore, index: source(i), slot: slot})
+				if !shared.hoisted[slot] {
+					p.code[memoPC[slot]].arg = len(p.code)
+				}
+			}
+		}
+
+		if branches == nil {
+			continue
+		}
+		switch b := branches[i]; b.kind {
+		case branchCondition:
+			jump(opBranch, before, b.target, i)
+		case branchThen:
+			jump(opJump, before, b.target, i)
+		case branchAnd:
+			jump(opAnd, after, b.target, b.target)
+		case branchOr:
+			jump(opOr, after, b.target, b.target)
+		}
+	}
+	if branches != nil {
+		land(before[len(postfixTokens)])
+	}
+
+	if depth != 1 {
+		return nil, fmt.Errorf("invalid expression: too many operands")
+	}
+	return p, nil
+}
+
+// run evaluates the program with the variables, constants and rounding of
+// cfg, returning what Evaluate returns for the postfix it was compiled from.
+func (p *program) run(cfg config) (float64, error) {
+	stack := make([]float64, 0, p.depth)
+	var cache []memo
+	if len(p.shared) > 0 {
+		cache = make([]memo, len(p.shared))
+	}
+
+	steps := 0
+	for pc := 0; pc < len(p.code); pc++ {
+		in := p.code[pc]
+		if err := cfg.step(steps, in.index); err != nil {
+			return 0, err
+		}
+		steps++
+
+		var result float64
+		var err error
+		switch in.op {
+		case opConst:
+			stack = append(stack, p.consts[in.arg])
+			continue
+
+		case opVar:
+			value, err := cfg.variable(p.names[in.arg], in.index)
+			if err != nil {
+				return 0, &TokenError{Index: in.index, Err: err}
+			}
+			stack = append(stack, value)
+			continue
+
+		case opAdd, opSub, opMul, opDiv, opBinary:
+			a, b := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			switch in.op {
+			case opAdd:
+				result = a + b
+			case opSub:
+				result = a - b
+			case opMul:
+				result = a * b
+			case opDiv:
+				result, err = applyBinary("/", a, b)
+			default:
+				result, err = applyBinary(p.names[in.arg], a, b)
+			}
+
+		case opNeg:
+			result = -stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+		case opUnary:
+			result, err = applyUnary(p.names[in.arg], stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+
+		case opCall:
+			c := p.calls[in.arg]
+			args := stack[len(stack)-c.argc:]
+			result, err = c.fn.invoke(c.token, args)
+			stack = stack[:len(stack)-c.argc]
+
+		case opCond:
+			// Choosing a branch computes nothing to round
+			stack[len(stack)-2] = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			continue
+
+		case opBranch:
+			if stack[len(stack)-1] == 0 {
+				pc = in.arg - 1
+			}
+			continue
+
+		case opJump:
+			pc = in.arg - 1
+			continue
+
+		case opMemo:
+			if cached := cache[in.slot]; cached.set {
+				stack = append(stack, cached.value)
+				pc = in.arg - 1
+			}
+			continue
+
+		case opStore:
+			cache[in.slot] = memo{value: stack[len(stack)-1], set: true}
+			continue
+
+		case opLoad:
+			stack = append(stack, cache[in.slot].value)
+			continue
+
+		case opAnd, opOr:
+			decided := stack[len(stack)-1] != 0
+			if decided != (in.op == opOr) {
+				continue
+			}
+			result = fromBool(decided)
+			stack = stack[:len(stack)-1]
+			pc = in.arg - 1
+		}
+
+		if err == nil && cfg.nonFinite != nonFinitePropagate {
+			result, err = checkFinite[float64](floatArithmetic{}, result, cfg)
+		}
+		if err == nil && cfg.roundIntermediates {
+			result = roundFloat(result, cfg.roundScale, cfg.roundMode, 64)
+		}
+		if err != nil {
+			return 0, &TokenError{Index: in.index, Err: err}
+		}
+		stack = append(stack, result)
+	}
+
+	if cfg.rounding {
+		return roundFloat(stack[0], cfg.roundScale, cfg.roundMode, 64), nil
+	}
+	return stack[0], nil
+}
@@ -0,0 +1,86 @@
+package shuntingyard
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestProgramMatchesEvaluate(t *testing.T) {
+	cube := WithOperator("^^", 7, RightAssociative, func(a, b float64) (float64, error) { return math.Pow(a, b*b), nil })
+	tests := []struct {
+		name       string
+		expression string
+		opts       []Option
+		vars       map[string]float64
+	}{
+		{name: "arithmetic", expression: "1 + 2 * 3 - 4 / 8"},
+		{name: "powers and modulo", expression: "2 ^ 3 ^ 2 % 7"},
+		{name: "comparison and logic", expression: "x > 1 && !(y == 2) || ~x < 0", vars: map[string]float64{"x": 3, "y": 2}},
+		{name: "negation", expression: "-x * -(2 + x)", vars: map[string]float64{"x": 1.5}},
+		{name: "conditional", expression: "x < 0 ? -x : x * 2", vars: map[string]float64{"x": -4}},
+		{name: "functions", expression: "max(abs(x), sqrt(16), 1) + min(1, 2)", vars: map[string]float64{"x": -7}},
+		{name: "constants", expression: "2 * pi * r", vars: map[string]float64{"r": 1}},
+		{name: "degrees", expression: "sin(x) + cos(0)", opts: []Option{WithDegrees()}, vars: map[string]float64{"x": 30}},
+		{name: "custom operator", expression: "2 ^^ 2 + 1", opts: []Option{cube}},
+		{name: "rounding", expression: "x / 3 + x / 3", opts: []Option{WithRounding(2, RoundHalfEven)}, vars: map[string]float64{"x": 1}},
+		{name: "intermediate rounding", expression: "x / 3 + x / 3", opts: []Option{WithRounding(2, RoundHalfEven), WithIntermediateRounding()}, vars: map[string]float64{"x": 1}},
+
+		// Error cases
+		{name: "division by zero", expression: "1 + 2 / (x - 1)", vars: map[string]float64{"x": 1}},
+		{name: "undefined variable", expression: "x + y", vars: map[string]float64{"x": 1}},
+		{name: "bitwise on fraction", expression: "~x", vars: map[string]float64{"x": 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expression, tt.opts...)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			cfg := expr.cfg
+			cfg.vars = tt.vars
+			expected, expectedErr := evaluate(expr.postfix, cfg)
+
+			result, err := expr.Eval(tt.vars)
+			if expectedErr != nil {
+				if err == nil || err.Error() != expectedErr.Error() {
+					t.Fatalf("Eval() error = %v, expected %v", err, expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+			if result != expected {
+				t.Errorf("Eval() = %v, evaluate() = %v", result, expected)
+			}
+		})
+	}
+}
+
+func TestCompileProgramErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		postfix  []string
+		expected string
+		index    int
+	}{
+		{name: "invalid number", postfix: []string{"1", "2.5.1", "+"}, expected: "invalid number: 2.5.1", index: 1},
+		{name: "insufficient operands", postfix: []string{"1", "+"}, expected: "invalid expression: insufficient operands for operator '+'", index: 1},
+		{name: "wrong argument count", postfix: []string{"1", "pow/1"}, expected: "function 'pow' takes 2 arguments, got 1", index: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileProgram(tt.postfix, nil, newConfig(nil))
+			var tokenErr *TokenError
+			if !errors.As(err, &tokenErr) {
+				t.Fatalf("compileProgram() error = %v, expected a *TokenError", err)
+			}
+			if tokenErr.Index != tt.index || tokenErr.Err.Error() != tt.expected {
+				t.Errorf("compileProgram() error = %d: %v, expected %d: %s", tokenErr.Index, tokenErr.Err, tt.index, tt.expected)
+			}
+		})
+	}
+}